@@ -14,9 +14,19 @@ import (
 
 	"google.golang.org/grpc"
 	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/api"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/cache"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/catalog"
 	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
 	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
 	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/persona"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/ratelimit"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/semantic"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/session"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/usage"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/window"
 )
 
 func main() {
@@ -26,6 +36,7 @@ func main() {
 		httpOnly   = flag.Bool("http-only", false, "Run only HTTP REST server")
 		grpcOnly   = flag.Bool("grpc-only", false, "Run only gRPC server")
 		version    = flag.Bool("version", false, "Show version information")
+		hashKey    = flag.String("hash-key", "", "Print the hash of an API key for use in the auth config")
 	)
 	flag.Parse()
 
@@ -34,18 +45,113 @@ func main() {
 		return
 	}
 
+	if *hashKey != "" {
+		fmt.Println(auth.HashKey(*hashKey))
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Create OpenWebUI client
-	openWebUIClient := client.NewOpenWebUIClient(
-		cfg.OpenWebUI.BaseURL,
-		cfg.OpenWebUI.APIKey,
-		time.Duration(cfg.OpenWebUI.Timeout)*time.Second,
-	)
+	// Create upstream clients and the model router in front of them
+	upstreamClient, err := router.NewFromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create upstream client: %v", err)
+	}
+
+	// Create the persona registry shared by both servers
+	personaStore, err := persona.OpenStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to open persona storage: %v", err)
+	}
+	personas := persona.NewRegistryWithStore(personaStore)
+	defer personas.Close()
+
+	// List the models of every backend with their declared capabilities
+	modelCatalog := catalog.New(upstreamClient, cfg)
+	restOptions := []api.Option{api.WithPersonas(personas), api.WithModels(modelCatalog), api.WithAliases(upstreamClient)}
+	grpcOptions := []api.Option{api.WithPersonas(personas), api.WithModels(modelCatalog), api.WithAliases(upstreamClient)}
+
+	// Create server-side conversation sessions, if enabled
+	if cfg.Sessions.Enabled {
+		sessionStore, err := session.OpenStore(cfg.Sessions)
+		if err != nil {
+			log.Fatalf("Failed to open sessions storage: %v", err)
+		}
+		sessions := session.NewManager(sessionStore)
+		defer sessions.Close()
+
+		restOptions = append(restOptions, api.WithSessions(sessions))
+		grpcOptions = append(grpcOptions, api.WithSessions(sessions))
+	}
+
+	// Create the response cache, if enabled
+	if cfg.Cache.Enabled {
+		cacheStore, err := cache.OpenStore(cfg.Cache)
+		if err != nil {
+			log.Fatalf("Failed to open response cache: %v", err)
+		}
+		responses := cache.New(cacheStore, cfg.Cache)
+		defer responses.Close()
+
+		restOptions = append(restOptions, api.WithResponseCache(responses))
+		grpcOptions = append(grpcOptions, api.WithResponseCache(responses))
+	}
+
+	// Create caller authentication, if enabled
+	unaryInterceptors := []grpc.UnaryServerInterceptor{api.LoggingInterceptor}
+	streamInterceptors := []grpc.StreamServerInterceptor{api.StreamLoggingInterceptor}
+	if cfg.Auth.Enabled {
+		authenticator, err := auth.NewFromConfig(cfg.Auth)
+		if err != nil {
+			log.Fatalf("Failed to configure authentication: %v", err)
+		}
+
+		restOptions = append(restOptions, api.WithAuthenticator(authenticator), api.WithAdminKeys(cfg.Auth.AdminKeys))
+		grpcOptions = append(grpcOptions, api.WithAdminKeys(cfg.Auth.AdminKeys))
+		unaryInterceptors = append(unaryInterceptors, api.AuthUnaryInterceptor(authenticator))
+		streamInterceptors = append(streamInterceptors, api.AuthStreamInterceptor(authenticator))
+	}
+
+	// Create per-caller rate limiting, if enabled
+	var bridgeClient client.Provider = upstreamClient
+	if cfg.RateLimit.Enabled {
+		limiter := ratelimit.NewFromConfig(cfg.RateLimit)
+		bridgeClient = ratelimit.NewClient(bridgeClient, limiter)
+
+		restOptions = append(restOptions, api.WithRateLimiter(limiter, cfg.RateLimit.By))
+		unaryInterceptors = append(unaryInterceptors, api.RateLimitUnaryInterceptor(limiter, cfg.RateLimit.By))
+		streamInterceptors = append(streamInterceptors, api.RateLimitStreamInterceptor(limiter, cfg.RateLimit.By))
+	}
+
+	// Create usage accounting and quotas, if enabled
+	if cfg.Usage.Enabled {
+		ledger, err := usage.Open(cfg.Usage.LedgerFile)
+		if err != nil {
+			log.Fatalf("Failed to open usage ledger: %v", err)
+		}
+		defer ledger.Close()
+
+		bridgeClient = usage.NewClient(bridgeClient, ledger, usage.NewQuotasFromConfig(ledger, cfg.Usage))
+		restOptions = append(restOptions, api.WithUsageLedger(ledger, cfg.Usage.AdminKeys))
+	}
+
+	// Fit requests into the model's context window, if enabled
+	if cfg.Context.Enabled {
+		bridgeClient = window.NewClient(bridgeClient, window.NewFromConfig(cfg.Context))
+	}
+
+	// Create the semantic cache, if enabled. Prompts are embedded through the
+	// bridge client so they are rate limited and accounted to the caller.
+	if cfg.Semantic.Enabled {
+		similar := semantic.New(bridgeClient, cfg.Semantic)
+
+		restOptions = append(restOptions, api.WithSemanticCache(similar))
+		grpcOptions = append(grpcOptions, api.WithSemanticCache(similar))
+	}
 
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -59,7 +165,7 @@ func main() {
 		go func() {
 			log.Printf("Starting HTTP REST server on %s:%d", cfg.Server.Host, cfg.Server.HTTPPort)
 			
-			restServer := api.NewRESTServer(openWebUIClient)
+			restServer := api.NewRESTServer(bridgeClient, restOptions...)
 			
 			httpServer := &http.Server{
 				Addr:    fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.HTTPPort),
@@ -100,9 +206,10 @@ func main() {
 			}
 
 			grpcServer := grpc.NewServer(
-				grpc.UnaryInterceptor(api.LoggingInterceptor),
+				grpc.ChainUnaryInterceptor(unaryInterceptors...),
+				grpc.ChainStreamInterceptor(streamInterceptors...),
 			)
-			bridgeServer := api.NewGRPCServer(openWebUIClient)
+			bridgeServer := api.NewGRPCServer(bridgeClient, grpcOptions...)
 			pb.RegisterFr0GAiBridgeServer(grpcServer, bridgeServer)
 
 			// Start server in goroutine
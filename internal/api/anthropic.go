@@ -0,0 +1,489 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/window"
+)
+
+// anthropicPath is the route of the Anthropic Messages API, which reports
+// errors in Anthropic's envelope
+const anthropicPath = "/v1/messages"
+
+// setupAnthropicRoutes registers the Anthropic-compatible API
+func (s *RESTServer) setupAnthropicRoutes() {
+	s.router.HandleFunc(anthropicPath, s.handleMessages).Methods("POST")
+}
+
+// isAnthropicPath reports whether path belongs to the Anthropic-compatible
+// API
+func isAnthropicPath(path string) bool {
+	return path == anthropicPath || strings.HasPrefix(path, anthropicPath+"/")
+}
+
+// handleMessages handles Anthropic Messages API requests by translating them
+// to chat completions and the replies back
+func (s *RESTServer) handleMessages(w http.ResponseWriter, r *http.Request) {
+	var msgReq models.AnthropicMessagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&msgReq); err != nil {
+		s.writeErrorFor(w, r, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req, err := anthropicToChat(&msgReq)
+	if err != nil {
+		s.writeErrorFor(w, r, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if err := s.validateChatCompletionRequest(req); err != nil {
+		s.writeErrorFor(w, r, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	// Check the caller may use the requested model
+	if err := s.opts.checkModel(r.Context(), req.Model); err != nil {
+		s.writeErrorFor(w, r, http.StatusForbidden, "Forbidden", err)
+		return
+	}
+
+	if msgReq.Stream {
+		s.handleMessagesStream(w, r, req)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	noCache := strings.Contains(strings.ToLower(r.Header.Get("Cache-Control")), "no-cache")
+	resp, cached, err := cachedCompletion(ctx, s.opts, s.client, req, noCache)
+	setCacheHeaders(w, cached)
+	if err != nil {
+		s.writeErrorFor(w, r, httpStatusFromError(err), "Failed to process message", err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, chatToAnthropic(resp))
+}
+
+// handleMessagesStream relays upstream chunks as Anthropic server-sent
+// events: message_start, a content block per run of text or tool call and
+// message_delta with the stop reason, followed by message_stop
+func (s *RESTServer) handleMessagesStream(w http.ResponseWriter, r *http.Request, req *models.ChatCompletionRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeErrorFor(w, r, http.StatusInternalServerError, "Streaming not supported", nil)
+		return
+	}
+
+	stream := &anthropicStream{w: w, flusher: flusher, model: req.Model}
+	err := s.client.ChatCompletionStream(r.Context(), req, stream.add)
+	if err != nil {
+		if !stream.started {
+			s.writeErrorFor(w, r, httpStatusFromError(err), "Failed to process message", err)
+			return
+		}
+
+		// Headers are already sent, so report the failure in-band
+		log.Printf("API Error: stream interrupted - %v", err)
+		if r.Context().Err() == nil {
+			resp := anthropicError(http.StatusInternalServerError, "Stream interrupted", err)
+			stream.write(models.AnthropicStreamEvent{Type: resp.Type, Error: &resp.Error})
+		}
+		return
+	}
+
+	stream.finish()
+}
+
+// anthropicStream translates chat completion chunks to Anthropic stream
+// events. Text and every tool call get their own content block; a block is
+// closed when the next one starts.
+type anthropicStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	model   string
+
+	started    bool
+	blocks     int  // Content blocks started so far
+	open       bool // Whether the last block is still open
+	tool       int  // Tool call index of the open block, -1 for text
+	stopReason string
+	usage      models.AnthropicUsage
+}
+
+// start sends the headers and message_start
+func (st *anthropicStream) start(id string) error {
+	st.w.Header().Set("Content-Type", "text/event-stream")
+	st.w.Header().Set("Cache-Control", "no-cache")
+	st.w.Header().Set("Connection", "keep-alive")
+	st.w.Header().Set("X-Accel-Buffering", "no")
+	st.w.WriteHeader(http.StatusOK)
+	st.started = true
+
+	message := &models.AnthropicMessagesResponse{
+		ID:      id,
+		Type:    "message",
+		Role:    "assistant",
+		Content: models.AnthropicContent{},
+		Model:   st.model,
+	}
+	return st.write(models.AnthropicStreamEvent{Type: "message_start", Message: message})
+}
+
+// add translates a single chunk
+func (st *anthropicStream) add(chunk *models.ChatCompletionChunk) error {
+	if chunk.Model != "" {
+		st.model = chunk.Model
+	}
+	if !st.started {
+		if err := st.start(chunk.ID); err != nil {
+			return err
+		}
+	}
+	if chunk.Usage != nil {
+		st.usage = models.AnthropicUsage{InputTokens: chunk.Usage.PromptTokens, OutputTokens: chunk.Usage.CompletionTokens}
+	}
+	if len(chunk.Choices) == 0 {
+		return nil
+	}
+
+	choice := chunk.Choices[0]
+	if choice.FinishReason != "" {
+		st.stopReason = anthropicStopReason(choice.FinishReason)
+	}
+	if choice.Delta.Content != "" {
+		if !st.open || st.tool >= 0 {
+			if err := st.startBlock(-1, models.AnthropicContentBlock{Type: "text"}); err != nil {
+				return err
+			}
+		}
+		if err := st.delta(models.AnthropicDelta{Type: "text_delta", Text: choice.Delta.Content}); err != nil {
+			return err
+		}
+	}
+	for _, fragment := range choice.Delta.ToolCalls {
+		if err := st.addToolCall(fragment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addToolCall translates a tool call fragment, starting a tool_use block
+// for every new call
+func (st *anthropicStream) addToolCall(fragment models.ToolCall) error {
+	index, newCall := st.blocks, fragment.ID != "" || !st.open || st.tool < 0
+	if fragment.Index != nil {
+		index, newCall = *fragment.Index, !st.open || st.tool != *fragment.Index
+	}
+	if newCall {
+		block := models.AnthropicContentBlock{
+			Type:  "tool_use",
+			ID:    fragment.ID,
+			Name:  fragment.Function.Name,
+			Input: json.RawMessage("{}"),
+		}
+		if err := st.startBlock(index, block); err != nil {
+			return err
+		}
+	}
+	if fragment.Function.Arguments == "" {
+		return nil
+	}
+	return st.delta(models.AnthropicDelta{Type: "input_json_delta", PartialJSON: fragment.Function.Arguments})
+}
+
+// startBlock closes the open content block and starts the next one. tool
+// is the index of the tool call the block holds, -1 for text.
+func (st *anthropicStream) startBlock(tool int, block models.AnthropicContentBlock) error {
+	if err := st.stopBlock(); err != nil {
+		return err
+	}
+
+	index := st.blocks
+	st.blocks++
+	st.open = true
+	st.tool = tool
+	return st.write(models.AnthropicStreamEvent{Type: "content_block_start", Index: &index, ContentBlock: &block})
+}
+
+// stopBlock closes the open content block, if any
+func (st *anthropicStream) stopBlock() error {
+	if !st.open {
+		return nil
+	}
+	st.open = false
+	index := st.blocks - 1
+	return st.write(models.AnthropicStreamEvent{Type: "content_block_stop", Index: &index})
+}
+
+// delta sends a fragment of the open content block
+func (st *anthropicStream) delta(delta models.AnthropicDelta) error {
+	index := st.blocks - 1
+	return st.write(models.AnthropicStreamEvent{Type: "content_block_delta", Index: &index, Delta: &delta})
+}
+
+// finish closes the last content block and the message. A reply without
+// content still gets an empty text block.
+func (st *anthropicStream) finish() error {
+	if !st.started {
+		if err := st.start(""); err != nil {
+			return err
+		}
+	}
+	if st.blocks == 0 {
+		if err := st.startBlock(-1, models.AnthropicContentBlock{Type: "text"}); err != nil {
+			return err
+		}
+	}
+	if st.stopReason == "" {
+		st.stopReason = "end_turn"
+	}
+
+	if err := st.stopBlock(); err != nil {
+		return err
+	}
+	if err := st.write(models.AnthropicStreamEvent{
+		Type:  "message_delta",
+		Delta: &models.AnthropicDelta{StopReason: st.stopReason},
+		Usage: &st.usage,
+	}); err != nil {
+		return err
+	}
+	return st.write(models.AnthropicStreamEvent{Type: "message_stop"})
+}
+
+// write sends a single event named after its type
+func (st *anthropicStream) write(event models.AnthropicStreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(st.w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	st.flusher.Flush()
+	return nil
+}
+
+// anthropicToChat translates a messages request to a chat completion
+// request. The system prompt becomes a leading system message and text
+// blocks are joined by blank lines. tool_use blocks become tool calls of
+// the assistant message and tool_result blocks become tool messages ahead
+// of the rest of their user message.
+func anthropicToChat(req *models.AnthropicMessagesRequest) (*models.ChatCompletionRequest, error) {
+	if req.MaxTokens <= 0 {
+		return nil, fmt.Errorf("max_tokens is required")
+	}
+
+	maxTokens := req.MaxTokens
+	chatReq := &models.ChatCompletionRequest{
+		Model:       req.Model,
+		Temperature: req.Temperature,
+		MaxTokens:   &maxTokens,
+	}
+	if req.Stream {
+		stream := true
+		chatReq.Stream = &stream
+	}
+
+	for _, tool := range req.Tools {
+		chatReq.Tools = append(chatReq.Tools, models.Tool{
+			Type: "function",
+			Function: models.ToolFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.InputSchema,
+			},
+		})
+	}
+	if req.ToolChoice != nil {
+		choice, err := anthropicToolChoice(req.ToolChoice)
+		if err != nil {
+			return nil, err
+		}
+		chatReq.ToolChoice = choice
+	}
+
+	if len(req.System) > 0 {
+		text, err := anthropicText(req.System)
+		if err != nil {
+			return nil, fmt.Errorf("system: %w", err)
+		}
+		chatReq.Messages = append(chatReq.Messages, models.ChatMessage{Role: "system", Content: text})
+	}
+	for i, msg := range req.Messages {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			return nil, fmt.Errorf("messages.%d: role must be user or assistant, got %q", i, msg.Role)
+		}
+		messages, err := anthropicMessage(msg)
+		if err != nil {
+			return nil, fmt.Errorf("messages.%d: %w", i, err)
+		}
+		chatReq.Messages = append(chatReq.Messages, messages...)
+	}
+	return chatReq, nil
+}
+
+// anthropicMessage translates a single message to chat messages
+func anthropicMessage(msg models.AnthropicMessage) ([]models.ChatMessage, error) {
+	var results []models.ChatMessage
+	var text []string
+	converted := models.ChatMessage{Role: msg.Role}
+	for i, block := range msg.Content {
+		switch {
+		case block.Type == "text":
+			text = append(text, block.Text)
+		case block.Type == "tool_use" && msg.Role == "assistant":
+			args := "{}"
+			if len(block.Input) > 0 {
+				args = string(block.Input)
+			}
+			converted.ToolCalls = append(converted.ToolCalls, models.ToolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: models.FunctionCall{Name: block.Name, Arguments: args},
+			})
+		case block.Type == "tool_result" && msg.Role == "user":
+			result, err := anthropicText(block.Content)
+			if err != nil {
+				return nil, fmt.Errorf("content.%d: %w", i, err)
+			}
+			if block.IsError {
+				result = "Error: " + result
+			}
+			results = append(results, models.ChatMessage{Role: "tool", ToolCallID: block.ToolUseID, Content: result})
+		default:
+			return nil, fmt.Errorf("content.%d: unsupported content block type %q in a %s message", i, block.Type, msg.Role)
+		}
+	}
+
+	converted.Content = strings.Join(text, "\n\n")
+	if converted.Content != "" || len(converted.ToolCalls) > 0 || len(results) == 0 {
+		results = append(results, converted)
+	}
+	return results, nil
+}
+
+// anthropicToolChoice maps an Anthropic tool choice to the OpenAI one
+func anthropicToolChoice(choice *models.AnthropicToolChoice) (*models.ToolChoice, error) {
+	switch choice.Type {
+	case "auto":
+		return &models.ToolChoice{Mode: models.ToolChoiceAuto}, nil
+	case "any":
+		return &models.ToolChoice{Mode: models.ToolChoiceRequired}, nil
+	case "none":
+		return &models.ToolChoice{Mode: models.ToolChoiceNone}, nil
+	case "tool":
+		return &models.ToolChoice{Function: choice.Name}, nil
+	default:
+		return nil, fmt.Errorf("tool_choice: unsupported type %q", choice.Type)
+	}
+}
+
+// anthropicText joins the text blocks of content
+func anthropicText(content models.AnthropicContent) (string, error) {
+	parts := make([]string, 0, len(content))
+	for i, block := range content {
+		if block.Type != "text" {
+			return "", fmt.Errorf("content.%d: unsupported content block type %q", i, block.Type)
+		}
+		parts = append(parts, block.Text)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// chatToAnthropic translates a chat completion to a messages reply. Tool
+// calls become tool_use blocks after the text.
+func chatToAnthropic(resp *models.ChatCompletionResponse) models.AnthropicMessagesResponse {
+	stopReason := "end_turn"
+	msg := models.AnthropicMessagesResponse{
+		ID:      resp.ID,
+		Type:    "message",
+		Role:    "assistant",
+		Content: models.AnthropicContent{},
+		Model:   resp.Model,
+		Usage: models.AnthropicUsage{
+			InputTokens:  resp.Usage.PromptTokens,
+			OutputTokens: resp.Usage.CompletionTokens,
+		},
+	}
+	if len(resp.Choices) > 0 {
+		choice := resp.Choices[0]
+		if choice.Message.Content != "" {
+			msg.Content = append(msg.Content, models.AnthropicContentBlock{Type: "text", Text: choice.Message.Content})
+		}
+		for _, call := range choice.Message.ToolCalls {
+			input := json.RawMessage("{}")
+			if args := strings.TrimSpace(call.Function.Arguments); strings.HasPrefix(args, "{") && json.Valid([]byte(args)) {
+				input = json.RawMessage(args)
+			}
+			msg.Content = append(msg.Content, models.AnthropicContentBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: input})
+		}
+		stopReason = anthropicStopReason(choice.FinishReason)
+	}
+	msg.StopReason = &stopReason
+	return msg
+}
+
+// anthropicStopReason maps an OpenAI finish reason to an Anthropic stop
+// reason
+func anthropicStopReason(finishReason string) string {
+	switch finishReason {
+	case "length":
+		return "max_tokens"
+	case "tool_calls":
+		return "tool_use"
+	case "content_filter":
+		return "refusal"
+	default:
+		return "end_turn"
+	}
+}
+
+// writeAnthropicError writes an error in Anthropic's envelope. Context
+// overflows are reported as 400 invalid_request_error, as Anthropic does.
+func (s *RESTServer) writeAnthropicError(w http.ResponseWriter, statusCode int, message string, err error) {
+	log.Printf("API Error: %s - %v", message, err)
+
+	if errors.Is(err, window.ErrContextOverflow) {
+		statusCode = http.StatusBadRequest
+	}
+
+	s.writeJSON(w, statusCode, anthropicError(statusCode, message, err))
+}
+
+// anthropicError builds the Anthropic error envelope for a failed request
+func anthropicError(statusCode int, message string, err error) models.AnthropicErrorResponse {
+	if err != nil {
+		message = message + ": " + err.Error()
+	}
+
+	errType := "invalid_request_error"
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		errType = "authentication_error"
+	case statusCode == http.StatusForbidden:
+		errType = "permission_error"
+	case statusCode == http.StatusNotFound:
+		errType = "not_found_error"
+	case statusCode == http.StatusRequestEntityTooLarge:
+		errType = "request_too_large"
+	case statusCode == http.StatusTooManyRequests:
+		errType = "rate_limit_error"
+	case statusCode == http.StatusServiceUnavailable:
+		errType = "overloaded_error"
+	case statusCode >= 500:
+		errType = "api_error"
+	}
+
+	return models.AnthropicErrorResponse{Type: "error", Error: models.AnthropicError{Type: errType, Message: message}}
+}
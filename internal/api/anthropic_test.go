@@ -0,0 +1,372 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/window"
+)
+
+func TestRESTServer_Messages(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{chatResponse: &models.ChatCompletionResponse{
+		ID:      "chatcmpl-1",
+		Model:   "llama3.1",
+		Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: "Hi there"}, FinishReason: "length"}},
+		Usage:   models.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15},
+	}}
+	server := NewRESTServer(mockClient)
+
+	body := `{
+		"model": "llama3.1",
+		"max_tokens": 64,
+		"system": [{"type": "text", "text": "Be brief."}, {"type": "text", "text": "Be kind."}],
+		"messages": [
+			{"role": "user", "content": "Hello"},
+			{"role": "assistant", "content": [{"type": "text", "text": "Hi"}]},
+			{"role": "user", "content": [{"type": "text", "text": "How are"}, {"type": "text", "text": "you?"}]}
+		]
+	}`
+	req := httptest.NewRequest("POST", "/v1/messages", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	sent := mockClient.chatRequest
+	expected := []models.ChatMessage{
+		{Role: "system", Content: "Be brief.\n\nBe kind."},
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi"},
+		{Role: "user", Content: "How are\n\nyou?"},
+	}
+	if len(sent.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %+v", len(expected), sent.Messages)
+	}
+	for i, msg := range expected {
+		if !reflect.DeepEqual(sent.Messages[i], msg) {
+			t.Errorf("message %d: expected %+v, got %+v", i, msg, sent.Messages[i])
+		}
+	}
+	if sent.MaxTokens == nil || *sent.MaxTokens != 64 {
+		t.Errorf("expected max_tokens 64, got %v", sent.MaxTokens)
+	}
+
+	var resp models.AnthropicMessagesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Type != "message" || resp.Role != "assistant" || resp.ID != "chatcmpl-1" {
+		t.Errorf("unexpected message %+v", resp)
+	}
+	if len(resp.Content) != 1 || resp.Content[0].Type != "text" || resp.Content[0].Text != "Hi there" {
+		t.Errorf("unexpected content %+v", resp.Content)
+	}
+	if resp.StopReason == nil || *resp.StopReason != "max_tokens" {
+		t.Errorf("expected stop_reason max_tokens, got %v", resp.StopReason)
+	}
+	if resp.Usage.InputTokens != 12 || resp.Usage.OutputTokens != 3 {
+		t.Errorf("unexpected usage %+v", resp.Usage)
+	}
+}
+
+func TestRESTServer_MessagesStream(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{chatChunks: []*models.ChatCompletionChunk{
+		{ID: "chatcmpl-1", Model: "llama3.1", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Role: "assistant", Content: "Hel"}}}},
+		{ID: "chatcmpl-1", Model: "llama3.1", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Content: "lo"}, FinishReason: "stop"}}},
+		{ID: "chatcmpl-1", Model: "llama3.1", Usage: &models.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}},
+	}}
+	server := NewRESTServer(mockClient)
+
+	req := httptest.NewRequest("POST", "/v1/messages", strings.NewReader(`{"model":"llama3.1","max_tokens":16,"stream":true,"messages":[{"role":"user","content":"Hi"}]}`))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q: %s", ct, w.Body.String())
+	}
+	if mockClient.chatRequest.Stream == nil || !*mockClient.chatRequest.Stream {
+		t.Error("expected the upstream request to stream")
+	}
+
+	var names []string
+	var events []models.AnthropicStreamEvent
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			names = append(names, name)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var event models.AnthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("failed to decode event %q: %v", data, err)
+			}
+			events = append(events, event)
+		}
+	}
+
+	expected := []string{"message_start", "content_block_start", "content_block_delta", "content_block_delta", "content_block_stop", "message_delta", "message_stop"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected events %v, got %v", expected, names)
+	}
+	for i, event := range events {
+		if event.Type != names[i] {
+			t.Errorf("event %d: expected type %s, got %s", i, names[i], event.Type)
+		}
+	}
+
+	if events[0].Message == nil || events[0].Message.ID != "chatcmpl-1" || events[0].Message.StopReason != nil {
+		t.Errorf("unexpected message_start %+v", events[0].Message)
+	}
+	if text := events[2].Delta.Text + events[3].Delta.Text; text != "Hello" || events[2].Delta.Type != "text_delta" {
+		t.Errorf("expected text_delta events spelling Hello, got %q", text)
+	}
+	if events[5].Delta.StopReason != "end_turn" || events[5].Usage.OutputTokens != 2 {
+		t.Errorf("unexpected message_delta %+v %+v", events[5].Delta, events[5].Usage)
+	}
+}
+
+func TestRESTServer_MessagesErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		upstreamError  error
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:           "missing max_tokens",
+			body:           `{"model":"llama3.1","messages":[{"role":"user","content":"Hi"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "invalid_request_error",
+		},
+		{
+			name:           "system role in messages",
+			body:           `{"model":"llama3.1","max_tokens":16,"messages":[{"role":"system","content":"Hi"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "invalid_request_error",
+		},
+		{
+			name:           "unsupported block",
+			body:           `{"model":"llama3.1","max_tokens":16,"messages":[{"role":"user","content":[{"type":"image"}]}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "invalid_request_error",
+		},
+		{
+			name:           "malformed content",
+			body:           `{"model":"llama3.1","max_tokens":16,"messages":[{"role":"user","content":42}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "invalid_request_error",
+		},
+		{
+			name:           "unknown model",
+			body:           `{"model":"claude-x","max_tokens":16,"messages":[{"role":"user","content":"Hi"}]}`,
+			upstreamError:  fmt.Errorf("%w: claude-x", router.ErrModelNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedType:   "not_found_error",
+		},
+		{
+			name:           "unknown model streaming",
+			body:           `{"model":"claude-x","max_tokens":16,"stream":true,"messages":[{"role":"user","content":"Hi"}]}`,
+			upstreamError:  fmt.Errorf("%w: claude-x", router.ErrModelNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedType:   "not_found_error",
+		},
+		{
+			name:           "context overflow",
+			body:           `{"model":"llama3.1","max_tokens":16,"messages":[{"role":"user","content":"Hi"}]}`,
+			upstreamError:  fmt.Errorf("%w: 10 over", window.ErrContextOverflow),
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "invalid_request_error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewRESTServer(&mockOpenWebUIClient{chatError: tt.upstreamError})
+
+			req := httptest.NewRequest("POST", "/v1/messages", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			server.GetRouter().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			var resp models.AnthropicErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Type != "error" || resp.Error.Type != tt.expectedType || resp.Error.Message == "" {
+				t.Errorf("expected a %s error, got %s", tt.expectedType, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRESTServer_MessagesAuthentication(t *testing.T) {
+	server := NewRESTServer(&mockOpenWebUIClient{}, WithAuthenticator(newTestKeyStore(t)))
+
+	req := httptest.NewRequest("POST", "/v1/messages", strings.NewReader(`{"model":"llama3.1","max_tokens":16,"messages":[{"role":"user","content":"Hi"}]}`))
+	req.Header.Set("x-api-key", "sk-wrong")
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	var resp models.AnthropicErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusUnauthorized || resp.Error.Type != "authentication_error" {
+		t.Errorf("expected 401 authentication_error, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Unknown routes under /v1/messages keep the Anthropic envelope
+	req = httptest.NewRequest("GET", "/v1/messages/batches", nil)
+	w = httptest.NewRecorder()
+	NewRESTServer(&mockOpenWebUIClient{}).GetRouter().ServeHTTP(w, req)
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusNotFound || resp.Error.Type != "not_found_error" {
+		t.Errorf("expected 404 not_found_error, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRESTServer_MessagesTools(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{chatResponse: &models.ChatCompletionResponse{
+		ID:    "chatcmpl-1",
+		Model: "llama3.1",
+		Choices: []models.Choice{{
+			Message: models.ChatMessage{Role: "assistant", Content: "Checking.", ToolCalls: []models.ToolCall{
+				{ID: "call_2", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Lyon"}`}},
+			}},
+			FinishReason: "tool_calls",
+		}},
+	}}
+	server := NewRESTServer(mockClient)
+
+	body := `{
+		"model": "llama3.1",
+		"max_tokens": 64,
+		"tools": [{"name": "get_weather", "description": "Current weather", "input_schema": {"type": "object"}}],
+		"tool_choice": {"type": "any"},
+		"messages": [
+			{"role": "user", "content": "Weather in Paris?"},
+			{"role": "assistant", "content": [{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"city": "Paris"}}]},
+			{"role": "user", "content": [
+				{"type": "tool_result", "tool_use_id": "toolu_1", "content": "18C"},
+				{"type": "text", "text": "And Lyon?"}
+			]}
+		]
+	}`
+	req := httptest.NewRequest("POST", "/v1/messages", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	sent := mockClient.chatRequest
+	if len(sent.Tools) != 1 || sent.Tools[0].Type != "function" || string(sent.Tools[0].Function.Parameters) != `{"type": "object"}` {
+		t.Errorf("unexpected tools %+v", sent.Tools)
+	}
+	if sent.ToolChoice == nil || sent.ToolChoice.Mode != models.ToolChoiceRequired {
+		t.Errorf("expected tool_choice any to become required, got %+v", sent.ToolChoice)
+	}
+	expected := []models.ChatMessage{
+		{Role: "user", Content: "Weather in Paris?"},
+		{Role: "assistant", ToolCalls: []models.ToolCall{
+			{ID: "toolu_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city": "Paris"}`}},
+		}},
+		{Role: "tool", ToolCallID: "toolu_1", Content: "18C"},
+		{Role: "user", Content: "And Lyon?"},
+	}
+	if !reflect.DeepEqual(sent.Messages, expected) {
+		t.Errorf("expected messages %+v, got %+v", expected, sent.Messages)
+	}
+
+	var resp models.AnthropicMessagesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Content) != 2 || resp.Content[1].Type != "tool_use" || resp.Content[1].ID != "call_2" || string(resp.Content[1].Input) != `{"city":"Lyon"}` {
+		t.Errorf("expected a text and a tool_use block, got %+v", resp.Content)
+	}
+	if resp.StopReason == nil || *resp.StopReason != "tool_use" {
+		t.Errorf("expected stop_reason tool_use, got %v", resp.StopReason)
+	}
+}
+
+func TestRESTServer_MessagesToolStream(t *testing.T) {
+	index := func(i int) *int { return &i }
+	mockClient := &mockOpenWebUIClient{chatChunks: []*models.ChatCompletionChunk{
+		{ID: "chatcmpl-1", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Role: "assistant", Content: "Checking."}}}},
+		{ID: "chatcmpl-1", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{ToolCalls: []models.ToolCall{
+			{Index: index(0), ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "a"}},
+		}}}}},
+		{ID: "chatcmpl-1", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{ToolCalls: []models.ToolCall{
+			{Index: index(0), Function: models.FunctionCall{Arguments: `{"x":`}},
+		}}}}},
+		{ID: "chatcmpl-1", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{ToolCalls: []models.ToolCall{
+			{Index: index(0), Function: models.FunctionCall{Arguments: `1}`}},
+			{Index: index(1), ID: "call_2", Type: "function", Function: models.FunctionCall{Name: "b", Arguments: `{}`}},
+		}}}}},
+		{ID: "chatcmpl-1", Choices: []models.ChunkChoice{{FinishReason: "tool_calls"}}},
+	}}
+	server := NewRESTServer(mockClient)
+
+	req := httptest.NewRequest("POST", "/v1/messages", strings.NewReader(`{"model":"llama3.1","max_tokens":16,"stream":true,"messages":[{"role":"user","content":"Go"}]}`))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	var events []models.AnthropicStreamEvent
+	var raw []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			var event models.AnthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("failed to decode event %q: %v", data, err)
+			}
+			events = append(events, event)
+			raw = append(raw, data)
+		}
+	}
+
+	var got []string
+	for _, event := range events {
+		switch {
+		case event.ContentBlock != nil:
+			got = append(got, fmt.Sprintf("start %d %s %s", *event.Index, event.ContentBlock.Type, event.ContentBlock.Name))
+		case event.Type == "content_block_delta":
+			got = append(got, fmt.Sprintf("delta %d %s%s", *event.Index, event.Delta.Text, event.Delta.PartialJSON))
+		case event.Type == "content_block_stop":
+			got = append(got, fmt.Sprintf("stop %d", *event.Index))
+		case event.Type == "message_delta":
+			got = append(got, "message_delta "+event.Delta.StopReason)
+		default:
+			got = append(got, event.Type)
+		}
+	}
+	expected := []string{
+		"message_start",
+		"start 0 text ", "delta 0 Checking.", "stop 0",
+		"start 1 tool_use a", `delta 1 {"x":`, "delta 1 1}", "stop 1",
+		"start 2 tool_use b", "delta 2 {}", "stop 2",
+		"message_delta tool_use",
+		"message_stop",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected events\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if !strings.Contains(raw[4], `"input":{}`) || !strings.Contains(raw[1], `"text":""`) {
+		t.Errorf("expected blocks to start with empty text and input, got %s and %s", raw[1], raw[4])
+	}
+}
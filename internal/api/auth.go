@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+)
+
+// healthCheckMethod is exempt from authentication so probes keep working
+const healthCheckMethod = "/fr0g_ai_bridge.Fr0gAiBridge/HealthCheck"
+
+// authMiddleware authenticates REST callers and stores the principal in the
+// request context. The health endpoint is exempt.
+func (s *RESTServer) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" || r.Method == "OPTIONS" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		credential := auth.BearerToken(r.Header.Get("Authorization"))
+		if credential == "" {
+			credential = r.Header.Get("X-API-Key")
+		}
+
+		principal, err := s.opts.authenticator.Authenticate(r.Context(), credential)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			s.writeErrorFor(w, r, http.StatusUnauthorized, "Unauthorized", err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	})
+}
+
+// AuthUnaryInterceptor authenticates unary gRPC callers using the
+// "authorization" (Bearer) or "x-api-key" metadata
+func AuthUnaryInterceptor(a auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == healthCheckMethod {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticateGRPC(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor authenticates streaming gRPC callers
+func AuthStreamInterceptor(a auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateGRPC(ss.Context(), a)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticateGRPC validates the credential in the incoming metadata and
+// returns a context carrying the principal
+func authenticateGRPC(ctx context.Context, a auth.Authenticator) (context.Context, error) {
+	var credential string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			credential = auth.BearerToken(values[0])
+		}
+		if values := md.Get("x-api-key"); credential == "" && len(values) > 0 {
+			credential = values[0]
+		}
+	}
+
+	principal, err := a.Authenticate(ctx, credential)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "unauthorized: %v", err)
+	}
+
+	return auth.NewContext(ctx, principal), nil
+}
+
+// contextServerStream overrides the context of a server stream
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overridden context
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+)
+
+func newTestKeyStore(t *testing.T) *auth.KeyStore {
+	t.Helper()
+
+	store, err := auth.NewKeyStore([]config.APIKeyConfig{
+		{ID: "team-a", Hash: auth.HashKey("secret-a"), AllowedModels: []string{"test-*"}},
+	})
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+	return store
+}
+
+func TestRESTServer_Auth(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{ID: "test-id"},
+	}
+	server := NewRESTServer(mockClient, WithAuthenticator(newTestKeyStore(t)))
+
+	tests := []struct {
+		name           string
+		path           string
+		header         string
+		value          string
+		model          string
+		expectedStatus int
+	}{
+		{name: "health is public", path: "/health", expectedStatus: http.StatusOK},
+		{name: "missing key", path: "/api/chat/completions", model: "test-model", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong key", path: "/api/chat/completions", header: "Authorization", value: "Bearer nope", model: "test-model", expectedStatus: http.StatusUnauthorized},
+		{name: "bearer key", path: "/api/chat/completions", header: "Authorization", value: "Bearer secret-a", model: "test-model", expectedStatus: http.StatusOK},
+		{name: "x-api-key", path: "/api/chat/completions", header: "X-API-Key", value: "secret-a", model: "test-model", expectedStatus: http.StatusOK},
+		{name: "model not allowed", path: "/api/chat/completions", header: "X-API-Key", value: "secret-a", model: "gpt-4o", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if tt.path == "/health" {
+				req = httptest.NewRequest("GET", tt.path, nil)
+			} else {
+				reqBody, _ := json.Marshal(models.ChatCompletionRequest{
+					Model:    tt.model,
+					Messages: []models.ChatMessage{{Role: "user", Content: "Hello"}},
+				})
+				req = httptest.NewRequest("POST", tt.path, bytes.NewBuffer(reqBody))
+			}
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+
+			server.GetRouter().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	interceptor := AuthUnaryInterceptor(newTestKeyStore(t))
+	server := NewGRPCServer(&mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{ID: "test-id"},
+	})
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.ChatCompletion(ctx, req.(*pb.ChatCompletionRequest))
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/fr0g_ai_bridge.Fr0gAiBridge/ChatCompletion"}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		model    string
+		wantCode codes.Code
+	}{
+		{name: "missing key", md: metadata.MD{}, model: "test-model", wantCode: codes.Unauthenticated},
+		{name: "bearer key", md: metadata.Pairs("authorization", "Bearer secret-a"), model: "test-model", wantCode: codes.OK},
+		{name: "x-api-key", md: metadata.Pairs("x-api-key", "secret-a"), model: "test-model", wantCode: codes.OK},
+		{name: "model not allowed", md: metadata.Pairs("x-api-key", "secret-a"), model: "gpt-4o", wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			req := &pb.ChatCompletionRequest{
+				Model:    tt.model,
+				Messages: []*pb.ChatMessage{{Role: "user", Content: "Hello"}},
+			}
+
+			_, err := interceptor(ctx, req, info, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("expected code %v, got %v (%v)", tt.wantCode, code, err)
+			}
+		})
+	}
+
+	// Health checks are exempt
+	healthInfo := &grpc.UnaryServerInfo{FullMethod: healthCheckMethod}
+	_, err := interceptor(context.Background(), &pb.HealthCheckRequest{}, healthInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.HealthCheck(ctx, req.(*pb.HealthCheckRequest))
+	})
+	if err != nil {
+		t.Errorf("expected health check to bypass auth, got %v", err)
+	}
+}
+
+func TestAuthStreamInterceptor(t *testing.T) {
+	interceptor := AuthStreamInterceptor(newTestKeyStore(t))
+	info := &grpc.StreamServerInfo{FullMethod: "/fr0g_ai_bridge.Fr0gAiBridge/StreamChatCompletion"}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret-a"))
+	stream := &mockChatStream{ctx: ctx}
+
+	var principal *auth.Principal
+	err := interceptor(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		principal, _ = auth.FromContext(ss.Context())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor failed: %v", err)
+	}
+
+	if principal == nil || principal.ID != "team-a" {
+		t.Errorf("expected principal in stream context, got %+v", principal)
+	}
+
+	stream = &mockChatStream{ctx: context.Background()}
+	err = interceptor(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestRESTServer_AuthResolvesAliases(t *testing.T) {
+	aliases, err := router.New([]*router.Backend{
+		{Name: "default", Provider: struct {
+			*mockOpenWebUIClient
+			*staticLister
+		}{&mockOpenWebUIClient{}, &staticLister{}}},
+	}, config.RoutingConfig{
+		Aliases:        map[string]string{"test-best": "gpt-4o", "smart": "test-model"},
+		DefaultBackend: "default",
+	})
+	if err != nil {
+		t.Fatalf("router.New failed: %v", err)
+	}
+	mockClient := &mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{ID: "test-id"},
+	}
+	server := NewRESTServer(mockClient, WithAuthenticator(newTestKeyStore(t)), WithAliases(aliases))
+	grpcServer := NewGRPCServer(mockClient, WithAliases(aliases))
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "team-a", AllowedModels: []string{"test-*"}})
+
+	tests := []struct {
+		name           string
+		model          string
+		expectedStatus int
+		expectedCode   codes.Code
+	}{
+		{name: "alias of a disallowed model", model: "test-best", expectedStatus: http.StatusForbidden, expectedCode: codes.PermissionDenied},
+		{name: "alias of an allowed model", model: "smart", expectedStatus: http.StatusOK, expectedCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, _ := json.Marshal(models.ChatCompletionRequest{
+				Model:    tt.model,
+				Messages: []models.ChatMessage{{Role: "user", Content: "Hello"}},
+			})
+			req := httptest.NewRequest("POST", "/api/chat/completions", bytes.NewBuffer(reqBody))
+			req.Header.Set("X-API-Key", "secret-a")
+			w := httptest.NewRecorder()
+
+			server.GetRouter().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			_, err := grpcServer.ChatCompletion(ctx, &pb.ChatCompletionRequest{
+				Model:    tt.model,
+				Messages: []*pb.ChatMessage{{Role: "user", Content: "Hello"}},
+			})
+			if status.Code(err) != tt.expectedCode {
+				t.Errorf("expected gRPC code %v, got %v", tt.expectedCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/cache"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/semantic"
+)
+
+// cacheResult describes how a chat completion was answered
+type cacheResult struct {
+	status     string  // cache.Hit, cache.Miss, or "" without any cache
+	similarity float64 // similarity of a semantic hit, 0 otherwise
+}
+
+// cachedCompletion answers req from the exact response cache, then from the
+// semantic cache, and otherwise forwards it to provider and caches the
+// reply. Either cache may be nil when disabled. noCache skips both for this
+// request.
+func cachedCompletion(ctx context.Context, opts serverOptions, provider OpenWebUIClientInterface, req *models.ChatCompletionRequest, noCache bool) (*models.ChatCompletionResponse, cacheResult, error) {
+	if opts.responses == nil && opts.semantic == nil {
+		resp, err := provider.ChatCompletion(ctx, req)
+		return resp, cacheResult{}, err
+	}
+	miss := cacheResult{status: cache.Miss}
+	if noCache {
+		resp, err := provider.ChatCompletion(ctx, req)
+		return resp, miss, err
+	}
+
+	var key string
+	if opts.responses != nil && opts.responses.Cacheable(req) {
+		key = cache.Key(req)
+		if resp, ok := opts.responses.Get(key); ok {
+			return resp, cacheResult{status: cache.Hit}, nil
+		}
+	}
+
+	var query semantic.Query
+	if opts.semantic != nil && opts.semantic.Cacheable(req) {
+		resp, similarity, q, err := opts.semantic.Lookup(ctx, req)
+		switch {
+		case err != nil:
+			// The answer can still be fetched; only the cache is lost
+			log.Printf("Semantic cache: %v", err)
+		case resp != nil:
+			return resp, cacheResult{status: cache.Hit, similarity: similarity}, nil
+		default:
+			query = q
+		}
+	}
+
+	resp, err := provider.ChatCompletion(ctx, req)
+	if err != nil {
+		return nil, miss, err
+	}
+	if key != "" {
+		opts.responses.Put(key, resp)
+	}
+	if opts.semantic != nil {
+		opts.semantic.Store(query, resp)
+	}
+	return resp, miss, nil
+}
+
+// setCacheHeaders reports how a REST response was answered in X-Cache and,
+// for semantic hits, X-Cache-Similarity
+func setCacheHeaders(w http.ResponseWriter, result cacheResult) {
+	if result.status == "" {
+		return
+	}
+	w.Header().Set("X-Cache", result.status)
+	if result.similarity > 0 {
+		w.Header().Set("X-Cache-Similarity", strconv.FormatFloat(result.similarity, 'f', 4, 64))
+	}
+}
+
+// noCacheMetadata reports whether a gRPC caller sent "cache-control:
+// no-cache" metadata
+func noCacheMetadata(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("cache-control") {
+		if strings.Contains(strings.ToLower(v), "no-cache") {
+			return true
+		}
+	}
+	return false
+}
+
+// setCacheHeader reports how a gRPC response was answered as "x-cache" and
+// "x-cache-similarity" header metadata
+func setCacheHeader(ctx context.Context, result cacheResult) {
+	if result.status == "" {
+		return
+	}
+	md := metadata.Pairs("x-cache", result.status)
+	if result.similarity > 0 {
+		md.Set("x-cache-similarity", strconv.FormatFloat(result.similarity, 'f', 4, 64))
+	}
+	// Fails only outside a real RPC, such as in direct handler calls
+	grpc.SetHeader(ctx, md)
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/cache"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/semantic"
+)
+
+// countingClient counts the chat completions that reach upstream
+type countingClient struct {
+	mockOpenWebUIClient
+	calls int
+}
+
+func (m *countingClient) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	m.calls++
+	return m.mockOpenWebUIClient.ChatCompletion(ctx, req)
+}
+
+func newTestCache() *cache.Cache {
+	return cache.New(cache.NewMemoryStore(10), config.CacheConfig{TTLSeconds: 60, DeterministicOnly: true})
+}
+
+func TestRESTServer_ResponseCache(t *testing.T) {
+	mockClient := &countingClient{mockOpenWebUIClient: mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{
+			ID:      "test-id",
+			Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: "Hi"}}},
+		},
+	}}
+	server := NewRESTServer(mockClient, WithResponseCache(newTestCache()))
+
+	zero, sampled, off := 0.0, 0.7, false
+	tests := []struct {
+		name          string
+		temperature   *float64
+		cache         *bool
+		cacheControl  string
+		expectedCache string
+		expectedCalls int
+	}{
+		{name: "first request", temperature: &zero, expectedCache: "MISS", expectedCalls: 1},
+		{name: "repeat", temperature: &zero, expectedCache: "HIT", expectedCalls: 1},
+		{name: "cache false", temperature: &zero, cache: &off, expectedCache: "MISS", expectedCalls: 2},
+		{name: "no-cache header", temperature: &zero, cacheControl: "no-cache", expectedCache: "MISS", expectedCalls: 3},
+		{name: "sampled", temperature: &sampled, expectedCache: "MISS", expectedCalls: 4},
+		{name: "sampled repeat", temperature: &sampled, expectedCache: "MISS", expectedCalls: 5},
+		{name: "still cached", temperature: &zero, expectedCache: "HIT", expectedCalls: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(models.ChatCompletionRequest{
+				Model:       "test-model",
+				Messages:    []models.ChatMessage{{Role: "user", Content: "Hello"}},
+				Temperature: tt.temperature,
+				Cache:       tt.cache,
+			})
+			req := httptest.NewRequest("POST", "/api/chat/completions", bytes.NewReader(body))
+			if tt.cacheControl != "" {
+				req.Header.Set("Cache-Control", tt.cacheControl)
+			}
+			w := httptest.NewRecorder()
+			server.GetRouter().ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("X-Cache"); got != tt.expectedCache {
+				t.Errorf("expected X-Cache %s, got %q", tt.expectedCache, got)
+			}
+			if mockClient.calls != tt.expectedCalls {
+				t.Errorf("expected %d upstream calls, got %d", tt.expectedCalls, mockClient.calls)
+			}
+
+			var resp models.ChatCompletionResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.ID != "test-id" || len(resp.Choices) != 1 {
+				t.Errorf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
+
+func TestRESTServer_ResponseCacheDisabled(t *testing.T) {
+	server := NewRESTServer(&mockOpenWebUIClient{chatResponse: &models.ChatCompletionResponse{ID: "test-id"}})
+
+	body, _ := json.Marshal(models.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []models.ChatMessage{{Role: "user", Content: "Hello"}},
+	})
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, httptest.NewRequest("POST", "/api/chat/completions", bytes.NewReader(body)))
+
+	if got := w.Header().Get("X-Cache"); got != "" {
+		t.Errorf("expected no X-Cache header without a cache, got %q", got)
+	}
+}
+
+func TestGRPCServer_ResponseCache(t *testing.T) {
+	mockClient := &countingClient{mockOpenWebUIClient: mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{
+			ID:      "test-id",
+			Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: "Hi"}}},
+		},
+	}}
+	server := NewGRPCServer(mockClient, WithResponseCache(newTestCache()))
+
+	zero, off := 0.0, false
+	request := func(cache *bool) *pb.ChatCompletionRequest {
+		return &pb.ChatCompletionRequest{
+			Model:       "test-model",
+			Messages:    []*pb.ChatMessage{{Role: "user", Content: "Hello"}},
+			Temperature: &zero,
+			Cache:       cache,
+		}
+	}
+	noCache := metadata.NewIncomingContext(context.Background(), metadata.Pairs("cache-control", "no-cache"))
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		req           *pb.ChatCompletionRequest
+		expectedCalls int
+	}{
+		{name: "first request", ctx: context.Background(), req: request(nil), expectedCalls: 1},
+		{name: "repeat", ctx: context.Background(), req: request(nil), expectedCalls: 1},
+		{name: "cache false", ctx: context.Background(), req: request(&off), expectedCalls: 2},
+		{name: "no-cache metadata", ctx: noCache, req: request(nil), expectedCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.ChatCompletion(tt.ctx, tt.req)
+			if err != nil {
+				t.Fatalf("ChatCompletion failed: %v", err)
+			}
+			if resp.Id != "test-id" {
+				t.Errorf("expected response test-id, got %s", resp.Id)
+			}
+			if mockClient.calls != tt.expectedCalls {
+				t.Errorf("expected %d upstream calls, got %d", tt.expectedCalls, mockClient.calls)
+			}
+		})
+	}
+}
+
+// vectorEmbedder embeds known texts as fixed vectors
+type vectorEmbedder map[string][]float64
+
+func (v vectorEmbedder) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	resp := &models.EmbeddingResponse{Object: "list", Model: req.Model}
+	for i, text := range req.Input {
+		resp.Data = append(resp.Data, models.Embedding{Object: "embedding", Index: i, Embedding: v[text]})
+	}
+	return resp, nil
+}
+
+func TestRESTServer_SemanticCache(t *testing.T) {
+	mockClient := &countingClient{mockOpenWebUIClient: mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{
+			ID:      "test-id",
+			Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: "Use the reset link."}}},
+		},
+	}}
+	embedder := vectorEmbedder{
+		"How do I reset my password?":  {1, 0, 0},
+		"How can I reset my password?": {0.98, 0.2, 0},
+		"What are your opening hours?": {0, 1, 0},
+	}
+	similar := semantic.New(embedder, config.SemanticConfig{Model: "embed", Threshold: 0.95, MaxEntries: 10, DeterministicOnly: true})
+	server := NewRESTServer(mockClient, WithResponseCache(newTestCache()), WithSemanticCache(similar))
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedCache string
+		similar       bool
+		expectedCalls int
+	}{
+		{name: "first question", content: "How do I reset my password?", expectedCache: "MISS", expectedCalls: 1},
+		{name: "exact repeat", content: "How do I reset my password?", expectedCache: "HIT", expectedCalls: 1},
+		{name: "reworded", content: "How can I reset my password?", expectedCache: "HIT", similar: true, expectedCalls: 1},
+		{name: "different question", content: "What are your opening hours?", expectedCache: "MISS", expectedCalls: 2},
+	}
+
+	zero := 0.0
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(models.ChatCompletionRequest{
+				Model:       "test-model",
+				Messages:    []models.ChatMessage{{Role: "user", Content: tt.content}},
+				Temperature: &zero,
+			})
+			w := httptest.NewRecorder()
+			server.GetRouter().ServeHTTP(w, httptest.NewRequest("POST", "/api/chat/completions", bytes.NewReader(body)))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("X-Cache"); got != tt.expectedCache {
+				t.Errorf("expected X-Cache %s, got %q", tt.expectedCache, got)
+			}
+			if got := w.Header().Get("X-Cache-Similarity"); (got != "") != tt.similar {
+				t.Errorf("expected X-Cache-Similarity only on semantic hits, got %q", got)
+			}
+			if mockClient.calls != tt.expectedCalls {
+				t.Errorf("expected %d upstream calls, got %d", tt.expectedCalls, mockClient.calls)
+			}
+		})
+	}
+}
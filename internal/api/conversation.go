@@ -0,0 +1,251 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/session"
+)
+
+// prepareConversation expands a request continuing a conversation with its
+// stored history. sessions may be nil when sessions are disabled. It returns
+// the new turn, nil without a conversation, to record once the reply arrives;
+// callers release it when they are done either way.
+func prepareConversation(ctx context.Context, sessions *session.Manager, req *models.ChatCompletionRequest) (*session.Turn, error) {
+	if req.ConversationID == "" {
+		return nil, nil
+	}
+	if sessions == nil {
+		return nil, fmt.Errorf("%w: %s", session.ErrNotFound, req.ConversationID)
+	}
+	return sessions.Prepare(ctx, req)
+}
+
+// recordConversation stores a completed turn. The reply has already been
+// produced, so failures are logged rather than returned to the caller.
+func recordConversation(turn *session.Turn, reply models.ChatMessage) {
+	if turn == nil {
+		return
+	}
+	if err := turn.Record(reply); err != nil {
+		log.Printf("Failed to record conversation %s: %v", turn.ID(), err)
+	}
+}
+
+// replyMessage returns the first choice of a response as the assistant
+// message to record
+func replyMessage(resp *models.ChatCompletionResponse) models.ChatMessage {
+	if len(resp.Choices) == 0 {
+		return models.ChatMessage{Role: "assistant"}
+	}
+	return resp.Choices[0].Message
+}
+
+// streamReply accumulates the first choice of a stream into the assistant
+// message to record
+type streamReply struct {
+	message models.ChatMessage
+	calls   map[int]int // Tool call index -> position in message.ToolCalls
+}
+
+// add folds a chunk into the reply
+func (r *streamReply) add(chunk *models.ChatCompletionChunk) {
+	for _, choice := range chunk.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+		if choice.Delta.Role != "" {
+			r.message.Role = choice.Delta.Role
+		}
+		r.message.Content += choice.Delta.Content
+		for _, fragment := range choice.Delta.ToolCalls {
+			r.addToolCall(fragment)
+		}
+	}
+}
+
+// addToolCall folds a tool call fragment into the call sharing its index.
+// Fragments without an index continue the last call unless they carry a new
+// ID.
+func (r *streamReply) addToolCall(fragment models.ToolCall) {
+	pos, ok := 0, false
+	if fragment.Index != nil {
+		pos, ok = r.calls[*fragment.Index]
+	} else if fragment.ID == "" && len(r.message.ToolCalls) > 0 {
+		pos, ok = len(r.message.ToolCalls)-1, true
+	}
+	if !ok {
+		pos = len(r.message.ToolCalls)
+		r.message.ToolCalls = append(r.message.ToolCalls, models.ToolCall{})
+		if fragment.Index != nil {
+			if r.calls == nil {
+				r.calls = make(map[int]int)
+			}
+			r.calls[*fragment.Index] = pos
+		}
+	}
+
+	call := &r.message.ToolCalls[pos]
+	if fragment.ID != "" {
+		call.ID = fragment.ID
+	}
+	if fragment.Type != "" {
+		call.Type = fragment.Type
+	}
+	if fragment.Function.Name != "" {
+		call.Function.Name = fragment.Function.Name
+	}
+	call.Function.Arguments += fragment.Function.Arguments
+}
+
+// reply returns the accumulated assistant message
+func (r *streamReply) reply() models.ChatMessage {
+	if r.message.Role == "" {
+		r.message.Role = "assistant"
+	}
+	return r.message
+}
+
+// handleListConversations lists the caller's conversations
+func (s *RESTServer) handleListConversations(w http.ResponseWriter, r *http.Request) {
+	conversations, err := s.opts.sessions.List(r.Context())
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to list conversations", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, models.ConversationList{Conversations: conversations})
+}
+
+// handleGetConversation returns a conversation with its history
+func (s *RESTServer) handleGetConversation(w http.ResponseWriter, r *http.Request) {
+	c, err := s.opts.sessions.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to get conversation", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, c)
+}
+
+// handleCreateConversation starts a conversation. The body may set a model,
+// a persona_id and seed messages; all are optional.
+func (s *RESTServer) handleCreateConversation(w http.ResponseWriter, r *http.Request) {
+	var c models.Conversation
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	created, err := s.opts.sessions.Create(r.Context(), c)
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to create conversation", err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, created)
+}
+
+// handleDeleteConversation removes a conversation
+func (s *RESTServer) handleDeleteConversation(w http.ResponseWriter, r *http.Request) {
+	if err := s.opts.sessions.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to delete conversation", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// errSessionsDisabled is returned by the conversation RPCs without sessions
+var errSessionsDisabled = status.Error(codes.Unimplemented, "sessions are not enabled")
+
+// CreateConversation implements the conversation create endpoint
+func (s *GRPCServer) CreateConversation(ctx context.Context, req *pb.Conversation) (*pb.Conversation, error) {
+	if s.opts.sessions == nil {
+		return nil, errSessionsDisabled
+	}
+
+	c, err := s.opts.sessions.Create(ctx, protoToConversation(req))
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	return conversationToProto(c), nil
+}
+
+// ListConversations implements the conversation list endpoint
+func (s *GRPCServer) ListConversations(ctx context.Context, req *pb.ListConversationsRequest) (*pb.ListConversationsResponse, error) {
+	if s.opts.sessions == nil {
+		return nil, errSessionsDisabled
+	}
+
+	conversations, err := s.opts.sessions.List(ctx)
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+
+	resp := &pb.ListConversationsResponse{}
+	for _, c := range conversations {
+		resp.Conversations = append(resp.Conversations, conversationToProto(c))
+	}
+	return resp, nil
+}
+
+// GetConversation implements the conversation get endpoint
+func (s *GRPCServer) GetConversation(ctx context.Context, req *pb.GetConversationRequest) (*pb.Conversation, error) {
+	if s.opts.sessions == nil {
+		return nil, errSessionsDisabled
+	}
+
+	c, err := s.opts.sessions.Get(ctx, req.Id)
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	return conversationToProto(c), nil
+}
+
+// DeleteConversation implements the conversation delete endpoint
+func (s *GRPCServer) DeleteConversation(ctx context.Context, req *pb.DeleteConversationRequest) (*pb.DeleteConversationResponse, error) {
+	if s.opts.sessions == nil {
+		return nil, errSessionsDisabled
+	}
+
+	if err := s.opts.sessions.Delete(ctx, req.Id); err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	return &pb.DeleteConversationResponse{}, nil
+}
+
+// conversationToProto converts a conversation to protobuf
+func conversationToProto(c models.Conversation) *pb.Conversation {
+	result := &pb.Conversation{
+		Id:        c.ID,
+		Model:     c.Model,
+		PersonaId: c.PersonaID,
+		CreatedAt: c.CreatedAt.Unix(),
+		UpdatedAt: c.UpdatedAt.Unix(),
+	}
+	for _, msg := range c.Messages {
+		result.Messages = append(result.Messages, messageToProto(msg))
+	}
+	return result
+}
+
+// protoToConversation converts a protobuf conversation to the internal
+// model. The ID and timestamps are managed by the server and ignored.
+func protoToConversation(c *pb.Conversation) models.Conversation {
+	result := models.Conversation{
+		Model:     c.Model,
+		PersonaID: c.PersonaId,
+	}
+	for _, msg := range c.Messages {
+		result.Messages = append(result.Messages, protoToMessage(msg))
+	}
+	return result
+}
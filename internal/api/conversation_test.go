@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/session"
+)
+
+func TestRESTServer_Conversations(t *testing.T) {
+	mockClient := &recordingClient{mockOpenWebUIClient: mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{
+			ID:      "test-id",
+			Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: "Hi there"}}},
+		},
+		chatChunks: []*models.ChatCompletionChunk{
+			{ID: "test-id", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Role: "assistant", Content: "Good"}}}},
+			{ID: "test-id", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Content: "bye"}, FinishReason: "stop"}}},
+		},
+	}}
+	server := NewRESTServer(mockClient, WithSessions(session.NewManager(session.NewMemoryStore())))
+
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		w := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(w, httptest.NewRequest(method, path, &buf))
+		return w
+	}
+
+	w := do("POST", "/api/conversations", models.Conversation{Model: "test-model"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created models.Conversation
+	json.NewDecoder(w.Body).Decode(&created)
+
+	// First turn
+	w = do("POST", "/api/chat/completions", models.ChatCompletionRequest{
+		ConversationID: created.ID,
+		Messages:       []models.ChatMessage{{Role: "user", Content: "Hello"}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp models.ChatCompletionResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.ConversationID != created.ID {
+		t.Errorf("expected conversation_id %s in the response, got %q", created.ID, resp.ConversationID)
+	}
+
+	// Second turn, streamed, carries the history upstream
+	streaming := true
+	w = do("POST", "/api/chat/completions", models.ChatCompletionRequest{
+		ConversationID: created.ID,
+		Messages:       []models.ChatMessage{{Role: "user", Content: "Bye"}},
+		Stream:         &streaming,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Third turn checks what is sent upstream
+	do("POST", "/api/chat/completions", models.ChatCompletionRequest{
+		ConversationID: created.ID,
+		Messages:       []models.ChatMessage{{Role: "user", Content: "Again"}},
+	})
+	if sent := mockClient.lastRequest.Messages; len(sent) != 5 || sent[0].Content != "Hello" || sent[4].Content != "Again" {
+		t.Errorf("expected the full history upstream, got %+v", sent)
+	}
+	if mockClient.lastRequest.Model != "test-model" {
+		t.Errorf("expected the conversation model, got %q", mockClient.lastRequest.Model)
+	}
+
+	w = do("GET", "/api/conversations/"+created.ID, nil)
+	var got models.Conversation
+	json.NewDecoder(w.Body).Decode(&got)
+	expected := []string{"Hello", "Hi there", "Bye", "Goodbye", "Again", "Hi there"}
+	if len(got.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %+v", len(expected), got.Messages)
+	}
+	for i, content := range expected {
+		if got.Messages[i].Content != content {
+			t.Errorf("message %d: expected %q, got %q", i, content, got.Messages[i].Content)
+		}
+	}
+
+	w = do("GET", "/api/conversations", nil)
+	var list models.ConversationList
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Conversations) != 1 {
+		t.Errorf("expected 1 conversation, got %+v", list)
+	}
+
+	if w := do("DELETE", "/api/conversations/"+created.ID, nil); w.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", w.Code)
+	}
+	w = do("POST", "/api/chat/completions", models.ChatCompletionRequest{
+		ConversationID: created.ID,
+		Model:          "test-model",
+		Messages:       []models.ChatMessage{{Role: "user", Content: "Hello"}},
+	})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a deleted conversation, got %d", w.Code)
+	}
+}
+
+func TestRESTServer_ConversationsDisabled(t *testing.T) {
+	server := NewRESTServer(&mockOpenWebUIClient{})
+
+	reqBody, _ := json.Marshal(models.ChatCompletionRequest{
+		ConversationID: "conv-1",
+		Model:          "test-model",
+		Messages:       []models.ChatMessage{{Role: "user", Content: "Hello"}},
+	})
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, httptest.NewRequest("POST", "/api/chat/completions", bytes.NewBuffer(reqBody)))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestGRPCServer_Conversations(t *testing.T) {
+	server := NewGRPCServer(&mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{
+			ID:      "test-id",
+			Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: "Hi there"}}},
+		},
+	}, WithSessions(session.NewManager(session.NewMemoryStore())))
+	ctx := context.Background()
+
+	created, err := server.CreateConversation(ctx, &pb.Conversation{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("CreateConversation failed: %v", err)
+	}
+
+	resp, err := server.ChatCompletion(ctx, &pb.ChatCompletionRequest{
+		ConversationId: created.Id,
+		Messages:       []*pb.ChatMessage{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil || resp.ConversationId != created.Id {
+		t.Fatalf("expected chat completion in the conversation, got %v (%v)", resp, err)
+	}
+
+	got, err := server.GetConversation(ctx, &pb.GetConversationRequest{Id: created.Id})
+	if err != nil || len(got.Messages) != 2 || got.Messages[1].Content != "Hi there" {
+		t.Errorf("expected the turn and reply to be recorded, got %v (%v)", got, err)
+	}
+
+	list, err := server.ListConversations(ctx, &pb.ListConversationsRequest{})
+	if err != nil || len(list.Conversations) != 1 {
+		t.Errorf("expected 1 conversation, got %v (%v)", list, err)
+	}
+
+	if _, err := server.DeleteConversation(ctx, &pb.DeleteConversationRequest{Id: created.Id}); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	if _, err := server.GetConversation(ctx, &pb.GetConversationRequest{Id: created.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+
+	disabled := NewGRPCServer(&mockOpenWebUIClient{})
+	if _, err := disabled.ListConversations(ctx, &pb.ListConversationsRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected Unimplemented without sessions, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+)
+
+// maxEmbeddingInputs caps the inputs of one embeddings request, matching the
+// OpenAI limit
+const maxEmbeddingInputs = 2048
+
+// validateEmbeddingRequest validates an embeddings request
+func validateEmbeddingRequest(req *models.EmbeddingRequest) error {
+	if req.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if len(req.Input) == 0 {
+		return fmt.Errorf("input is required")
+	}
+	if len(req.Input) > maxEmbeddingInputs {
+		return fmt.Errorf("at most %d inputs are allowed, got %d", maxEmbeddingInputs, len(req.Input))
+	}
+	for i, text := range req.Input {
+		if text == "" {
+			return fmt.Errorf("input %d: must not be empty", i)
+		}
+	}
+	if req.EncodingFormat != "" && req.EncodingFormat != "float" && req.EncodingFormat != "base64" {
+		return fmt.Errorf("unsupported encoding_format %q", req.EncodingFormat)
+	}
+	return nil
+}
+
+// base64Embedding is an embedding whose vector is encoded as base64
+// little-endian float32s, as OpenAI does for encoding_format "base64"
+type base64Embedding struct {
+	Object    string `json:"object"`
+	Index     int    `json:"index"`
+	Embedding string `json:"embedding"`
+}
+
+// handleEmbeddings handles embeddings requests. The body follows the OpenAI
+// /v1/embeddings shape; input may be a single string or an array of strings.
+func (s *RESTServer) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var req models.EmbeddingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorFor(w, r, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := validateEmbeddingRequest(&req); err != nil {
+		s.writeErrorFor(w, r, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	// Check the caller may use the requested model
+	if err := s.opts.checkModel(r.Context(), req.Model); err != nil {
+		s.writeErrorFor(w, r, http.StatusForbidden, "Forbidden", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	resp, err := s.client.Embed(ctx, &req)
+	if err != nil {
+		s.writeErrorFor(w, r, httpStatusFromError(err), "Failed to create embeddings", err)
+		return
+	}
+	if resp.Object == "" {
+		resp.Object = "list"
+	}
+	for i := range resp.Data {
+		if resp.Data[i].Object == "" {
+			resp.Data[i].Object = "embedding"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if req.EncodingFormat != "base64" {
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	encoded := make([]base64Embedding, len(resp.Data))
+	for i, e := range resp.Data {
+		encoded[i] = base64Embedding{Object: e.Object, Index: e.Index, Embedding: encodeVector(e.Embedding)}
+	}
+	json.NewEncoder(w).Encode(struct {
+		Object string            `json:"object"`
+		Data   []base64Embedding `json:"data"`
+		Model  string            `json:"model"`
+		Usage  models.Usage      `json:"usage"`
+	}{resp.Object, encoded, resp.Model, resp.Usage})
+}
+
+// encodeVector encodes vector as base64 little-endian float32s
+func encodeVector(vector []float64) string {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// Embed implements the embeddings endpoint
+func (s *GRPCServer) Embed(ctx context.Context, req *pb.EmbedRequest) (*pb.EmbedResponse, error) {
+	modelReq := &models.EmbeddingRequest{Model: req.Model, Input: req.Input}
+	if err := validateEmbeddingRequest(modelReq); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Check the caller may use the requested model
+	if err := s.opts.checkModel(ctx, modelReq.Model); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	resp, err := s.client.Embed(ctx, modelReq)
+	if err != nil {
+		return nil, grpcError("failed to create embeddings", err)
+	}
+	return embeddingsToProto(resp), nil
+}
+
+// embeddingsToProto converts an embeddings response to protobuf
+func embeddingsToProto(resp *models.EmbeddingResponse) *pb.EmbedResponse {
+	result := &pb.EmbedResponse{
+		Model: resp.Model,
+		Usage: &pb.Usage{
+			PromptTokens: int32(resp.Usage.PromptTokens),
+			TotalTokens:  int32(resp.Usage.TotalTokens),
+		},
+	}
+	for _, e := range resp.Data {
+		vector := make([]float32, len(e.Embedding))
+		for i, v := range e.Embedding {
+			vector[i] = float32(v)
+		}
+		result.Data = append(result.Data, &pb.Embedding{Index: int32(e.Index), Embedding: vector})
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+)
+
+func TestRESTServer_Embeddings(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		upstreamError  error
+		expectedStatus int
+		expectedInputs []string
+	}{
+		{
+			name:           "single string",
+			body:           `{"model":"nomic-embed-text","input":"hello"}`,
+			expectedStatus: http.StatusOK,
+			expectedInputs: []string{"hello"},
+		},
+		{
+			name:           "batch",
+			body:           `{"model":"nomic-embed-text","input":["hello","world"],"encoding_format":"float"}`,
+			expectedStatus: http.StatusOK,
+			expectedInputs: []string{"hello", "world"},
+		},
+		{name: "missing model", body: `{"input":"hello"}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing input", body: `{"model":"nomic-embed-text"}`, expectedStatus: http.StatusBadRequest},
+		{name: "empty input", body: `{"model":"nomic-embed-text","input":["hello",""]}`, expectedStatus: http.StatusBadRequest},
+		{name: "token input", body: `{"model":"nomic-embed-text","input":[1,2,3]}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown encoding", body: `{"model":"nomic-embed-text","input":"hello","encoding_format":"int8"}`, expectedStatus: http.StatusBadRequest},
+		{
+			name:           "upstream error",
+			body:           `{"model":"nomic-embed-text","input":"hello"}`,
+			upstreamError:  errors.New("backend down"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockOpenWebUIClient{chatError: tt.upstreamError}
+			server := NewRESTServer(mockClient)
+
+			req := httptest.NewRequest("POST", "/api/embeddings", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			server.GetRouter().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp models.EmbeddingResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Object != "list" {
+				t.Errorf("expected object list, got %q", resp.Object)
+			}
+			if len(resp.Data) != len(tt.expectedInputs) {
+				t.Fatalf("expected %d embeddings, got %d", len(tt.expectedInputs), len(resp.Data))
+			}
+			for i, text := range tt.expectedInputs {
+				if mockClient.embedRequest.Input[i] != text {
+					t.Errorf("expected input %d to be %q, got %q", i, text, mockClient.embedRequest.Input[i])
+				}
+				if resp.Data[i].Index != i {
+					t.Errorf("expected index %d, got %d", i, resp.Data[i].Index)
+				}
+			}
+		})
+	}
+}
+
+func TestGRPCServer_Embed(t *testing.T) {
+	server := NewGRPCServer(&mockOpenWebUIClient{})
+	ctx := context.Background()
+
+	resp, err := server.Embed(ctx, &pb.EmbedRequest{Model: "nomic-embed-text", Input: []string{"hello", "world"}})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if resp.Model != "nomic-embed-text" || len(resp.Data) != 2 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if resp.Data[1].Index != 1 || resp.Data[1].Embedding[0] != 1 || resp.Data[1].Embedding[1] != 0.5 {
+		t.Errorf("unexpected embedding %+v", resp.Data[1])
+	}
+	if resp.Usage.TotalTokens != 2 {
+		t.Errorf("expected 2 total tokens, got %d", resp.Usage.TotalTokens)
+	}
+
+	if _, err := server.Embed(ctx, &pb.EmbedRequest{Model: "nomic-embed-text"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument without input, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/persona"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/resilience"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/session"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/usage"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/window"
+)
+
+// httpStatusFromError maps backend errors to REST status codes
+func httpStatusFromError(err error) int {
+	switch {
+	case errors.Is(err, router.ErrModelNotFound), errors.Is(err, persona.ErrNotFound),
+		errors.Is(err, session.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, persona.ErrExists), errors.Is(err, persona.ErrVersionMismatch):
+		return http.StatusConflict
+	case errors.Is(err, persona.ErrInvalid), errors.Is(err, persona.ErrConflict), errors.Is(err, persona.ErrMissingVars),
+		errors.Is(err, persona.ErrSystemRejected), errors.Is(err, session.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, resilience.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, usage.ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, window.ErrContextOverflow):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// grpcCodeFromError maps backend errors to gRPC status codes
+func grpcCodeFromError(err error) codes.Code {
+	switch {
+	case errors.Is(err, router.ErrModelNotFound), errors.Is(err, persona.ErrNotFound),
+		errors.Is(err, session.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, persona.ErrExists):
+		return codes.AlreadyExists
+	case errors.Is(err, persona.ErrVersionMismatch):
+		return codes.Aborted
+	case errors.Is(err, persona.ErrInvalid), errors.Is(err, persona.ErrConflict), errors.Is(err, persona.ErrMissingVars),
+		errors.Is(err, persona.ErrSystemRejected), errors.Is(err, session.ErrInvalid):
+		return codes.InvalidArgument
+	case errors.Is(err, resilience.ErrCircuitOpen):
+		return codes.Unavailable
+	case errors.Is(err, usage.ErrQuotaExceeded):
+		return codes.ResourceExhausted
+	case errors.Is(err, window.ErrContextOverflow):
+		return codes.OutOfRange
+	default:
+		return codes.Internal
+	}
+}
+
+// grpcError wraps err in a gRPC status carrying the mapped code
+func grpcError(message string, err error) error {
+	return status.Errorf(grpcCodeFromError(err), "%s: %v", message, err)
+}
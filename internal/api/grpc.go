@@ -4,29 +4,65 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
 	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
 )
 
-// OpenWebUIClientInterface defines the interface for OpenWebUI client
+// OpenWebUIClientInterface defines the interface for the upstream client.
+// Every client.Provider satisfies it.
 type OpenWebUIClientInterface interface {
 	HealthCheck(ctx context.Context) error
 	ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error)
+	ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error
+	Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error)
+}
+
+// BackendHealthReporter is implemented by clients that front several
+// backends and can report on each of them
+type BackendHealthReporter interface {
+	BackendHealth(ctx context.Context) []models.BackendHealth
+}
+
+// checkHealth returns the overall upstream health and, when the client
+// supports it, a per-backend report
+func checkHealth(ctx context.Context, client OpenWebUIClientInterface) ([]models.BackendHealth, error) {
+	reporter, ok := client.(BackendHealthReporter)
+	if !ok {
+		return nil, client.HealthCheck(ctx)
+	}
+
+	backends := reporter.BackendHealth(ctx)
+	var unhealthy []string
+	for _, b := range backends {
+		if b.Status != "healthy" {
+			unhealthy = append(unhealthy, b.Name)
+		}
+	}
+
+	if len(unhealthy) > 0 {
+		return backends, fmt.Errorf("unhealthy backends: %s", strings.Join(unhealthy, ", "))
+	}
+	return backends, nil
 }
 
 // GRPCServer implements the Fr0gAiBridge gRPC service
 type GRPCServer struct {
 	pb.UnimplementedFr0GAiBridgeServer
 	client OpenWebUIClientInterface
+	opts   serverOptions
 }
 
 // NewGRPCServer creates a new gRPC server
-func NewGRPCServer(openWebUIClient OpenWebUIClientInterface) *GRPCServer {
+func NewGRPCServer(openWebUIClient OpenWebUIClientInterface, opts ...Option) *GRPCServer {
 	return &GRPCServer{
 		client: openWebUIClient,
+		opts:   newServerOptions(opts),
 	}
 }
 
@@ -46,15 +82,40 @@ func LoggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnarySe
 	return resp, err
 }
 
+// StreamLoggingInterceptor logs streaming gRPC requests
+func StreamLoggingInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+
+	duration := time.Since(start)
+	status := "OK"
+	if err != nil {
+		status = "ERROR"
+	}
+
+	log.Printf("gRPC %s %s %v", info.FullMethod, status, duration)
+	return err
+}
+
 // HealthCheck implements the health check endpoint
 func (s *GRPCServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
 	// Check OpenWebUI health
-	err := s.client.HealthCheck(ctx)
+	backends, err := checkHealth(ctx, s.client)
 	
 	response := &pb.HealthCheckResponse{
 		Version: "1.0.0",
 	}
 
+	for _, b := range backends {
+		response.Backends = append(response.Backends, &pb.BackendHealth{
+			Name:    b.Name,
+			Status:  b.Status,
+			Circuit: b.Circuit,
+			Error:   b.Error,
+		})
+	}
+
 	if err != nil {
 		response.Status = "unhealthy"
 		log.Printf("gRPC Health check failed: %v", err)
@@ -67,28 +128,95 @@ func (s *GRPCServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest
 
 // ChatCompletion implements the chat completion endpoint
 func (s *GRPCServer) ChatCompletion(ctx context.Context, req *pb.ChatCompletionRequest) (*pb.ChatCompletionResponse, error) {
+	// Convert protobuf request to internal model, expand its conversation
+	// and resolve its persona
+	modelReq := s.protoToModel(req)
+	turn, err := prepareConversation(ctx, s.opts.sessions, modelReq)
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	defer turn.Release()
+	applied, err := applyPersona(s.opts.personas, modelReq)
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+
 	// Validate request
-	if err := s.validateChatCompletionRequest(req); err != nil {
+	if err := s.validateChatCompletionRequest(modelReq); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	// Convert protobuf request to internal model
-	modelReq := s.protoToModel(req)
+	// Check the caller may use the requested model
+	if err := s.opts.checkModel(ctx, modelReq.Model); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
 
-	// Forward to OpenWebUI
-	resp, err := s.client.ChatCompletion(ctx, modelReq)
+	// Answer from the response cache or forward to OpenWebUI
+	resp, cached, err := cachedCompletion(ctx, s.opts, s.client, modelReq, noCacheMetadata(ctx))
+	setCacheHeader(ctx, cached)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process chat completion: %w", err)
+		return nil, grpcError("failed to process chat completion", err)
 	}
 
 	// Convert response back to protobuf
+	resp.PersonaID = applied.ID
+	resp.PersonaVersion = applied.Version
+	resp.ConversationID = modelReq.ConversationID
+	recordConversation(turn, replyMessage(resp))
 	protoResp := s.modelToProto(resp)
 
 	return protoResp, nil
 }
 
+// StreamChatCompletion implements the server-streaming chat completion
+// endpoint, forwarding each upstream delta as soon as it arrives
+func (s *GRPCServer) StreamChatCompletion(req *pb.ChatCompletionRequest, stream pb.Fr0GAiBridge_StreamChatCompletionServer) error {
+	// Convert protobuf request to internal model, expand its conversation
+	// and resolve its persona
+	modelReq := s.protoToModel(req)
+	turn, err := prepareConversation(stream.Context(), s.opts.sessions, modelReq)
+	if err != nil {
+		return status.Error(grpcCodeFromError(err), err.Error())
+	}
+	defer turn.Release()
+	applied, err := applyPersona(s.opts.personas, modelReq)
+	if err != nil {
+		return status.Error(grpcCodeFromError(err), err.Error())
+	}
+
+	// Validate request
+	if err := s.validateChatCompletionRequest(modelReq); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	// Check the caller may use the requested model
+	if err := s.opts.checkModel(stream.Context(), modelReq.Model); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	streaming := true
+	modelReq.Stream = &streaming
+
+	// Forward to OpenWebUI, cancelled when the caller goes away
+	var reply streamReply
+	err = s.client.ChatCompletionStream(stream.Context(), modelReq, func(chunk *models.ChatCompletionChunk) error {
+		chunk.PersonaID = applied.ID
+		chunk.PersonaVersion = applied.Version
+		chunk.ConversationID = modelReq.ConversationID
+		reply.add(chunk)
+		return stream.Send(s.chunkToProto(chunk))
+	})
+	if err != nil {
+		return grpcError("failed to process chat completion", err)
+	}
+	recordConversation(turn, reply.reply())
+
+	return nil
+}
+
 // validateChatCompletionRequest validates the gRPC chat completion request
-func (s *GRPCServer) validateChatCompletionRequest(req *pb.ChatCompletionRequest) error {
+// once its persona has been resolved
+func (s *GRPCServer) validateChatCompletionRequest(req *models.ChatCompletionRequest) error {
 	if req.Model == "" {
 		return fmt.Errorf("model is required")
 	}
@@ -96,14 +224,11 @@ func (s *GRPCServer) validateChatCompletionRequest(req *pb.ChatCompletionRequest
 		return fmt.Errorf("messages are required")
 	}
 	for i, msg := range req.Messages {
-		if msg.Role == "" {
-			return fmt.Errorf("message %d: role is required", i)
-		}
-		if msg.Content == "" {
-			return fmt.Errorf("message %d: content is required", i)
+		if err := validateMessage(i, msg); err != nil {
+			return err
 		}
 	}
-	return nil
+	return validateTools(req)
 }
 
 // protoToModel converts protobuf request to internal model
@@ -111,15 +236,18 @@ func (s *GRPCServer) protoToModel(req *pb.ChatCompletionRequest) *models.ChatCom
 	modelReq := &models.ChatCompletionRequest{
 		Model:         req.Model,
 		PersonaPrompt: req.PersonaPrompt,
+		PersonaID:     req.PersonaId,
+		PersonaVars:   req.PersonaVars,
+		PersonaMerge:  req.PersonaMerge,
+
+		ConversationID: req.ConversationId,
 	}
 
-	// Convert messages
+	// Convert messages and tools
 	for _, msg := range req.Messages {
-		modelReq.Messages = append(modelReq.Messages, models.ChatMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+		modelReq.Messages = append(modelReq.Messages, protoToMessage(msg))
 	}
+	protoToTools(req, modelReq)
 
 	// Convert optional fields
 	if req.Temperature != nil {
@@ -137,16 +265,24 @@ func (s *GRPCServer) protoToModel(req *pb.ChatCompletionRequest) *models.ChatCom
 		modelReq.Stream = &stream
 	}
 
+	if req.Cache != nil {
+		cache := *req.Cache
+		modelReq.Cache = &cache
+	}
+
 	return modelReq
 }
 
 // modelToProto converts internal model response to protobuf
 func (s *GRPCServer) modelToProto(resp *models.ChatCompletionResponse) *pb.ChatCompletionResponse {
 	protoResp := &pb.ChatCompletionResponse{
-		Id:      resp.ID,
-		Object:  resp.Object,
-		Created: resp.Created,
-		Model:   resp.Model,
+		Id:             resp.ID,
+		Object:         resp.Object,
+		Created:        resp.Created,
+		Model:          resp.Model,
+		PersonaId:      resp.PersonaID,
+		PersonaVersion: int32(resp.PersonaVersion),
+		ConversationId: resp.ConversationID,
 		Usage: &pb.Usage{
 			PromptTokens:     int32(resp.Usage.PromptTokens),
 			CompletionTokens: int32(resp.Usage.CompletionTokens),
@@ -157,14 +293,47 @@ func (s *GRPCServer) modelToProto(resp *models.ChatCompletionResponse) *pb.ChatC
 	// Convert choices
 	for _, choice := range resp.Choices {
 		protoResp.Choices = append(protoResp.Choices, &pb.Choice{
+			Index:        int32(choice.Index),
+			Message:      messageToProto(choice.Message),
+			FinishReason: choice.FinishReason,
+		})
+	}
+
+	return protoResp
+}
+
+// chunkToProto converts an internal stream chunk to protobuf
+func (s *GRPCServer) chunkToProto(chunk *models.ChatCompletionChunk) *pb.ChatCompletionChunk {
+	protoChunk := &pb.ChatCompletionChunk{
+		Id:             chunk.ID,
+		Object:         chunk.Object,
+		Created:        chunk.Created,
+		Model:          chunk.Model,
+		PersonaId:      chunk.PersonaID,
+		PersonaVersion: int32(chunk.PersonaVersion),
+		ConversationId: chunk.ConversationID,
+	}
+
+	if chunk.Usage != nil {
+		protoChunk.Usage = &pb.Usage{
+			PromptTokens:     int32(chunk.Usage.PromptTokens),
+			CompletionTokens: int32(chunk.Usage.CompletionTokens),
+			TotalTokens:      int32(chunk.Usage.TotalTokens),
+		}
+	}
+
+	// Convert choice deltas
+	for _, choice := range chunk.Choices {
+		protoChunk.Choices = append(protoChunk.Choices, &pb.ChunkChoice{
 			Index: int32(choice.Index),
-			Message: &pb.ChatMessage{
-				Role:    choice.Message.Role,
-				Content: choice.Message.Content,
+			Delta: &pb.ChatDelta{
+				Role:      choice.Delta.Role,
+				Content:   choice.Delta.Content,
+				ToolCalls: toolCallsToProto(choice.Delta.ToolCalls),
 			},
 			FinishReason: choice.FinishReason,
 		})
 	}
 
-	return protoResp
+	return protoChunk
 }
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
 	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
 )
@@ -140,6 +142,84 @@ func TestGRPCServer_ChatCompletion(t *testing.T) {
 	}
 }
 
+// mockChatStream captures chunks sent on a server stream
+type mockChatStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	chunks []*pb.ChatCompletionChunk
+	header metadata.MD
+}
+
+func (m *mockChatStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockChatStream) SetHeader(md metadata.MD) error {
+	m.header = metadata.Join(m.header, md)
+	return nil
+}
+
+func (m *mockChatStream) Send(chunk *pb.ChatCompletionChunk) error {
+	m.chunks = append(m.chunks, chunk)
+	return nil
+}
+
+func TestGRPCServer_StreamChatCompletion(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{
+		chatChunks: []*models.ChatCompletionChunk{
+			{
+				ID: "test-id",
+				Choices: []models.ChunkChoice{
+					{Delta: models.ChatDelta{Role: "assistant", Content: "Hel"}},
+				},
+			},
+			{
+				ID: "test-id",
+				Choices: []models.ChunkChoice{
+					{Delta: models.ChatDelta{Content: "lo"}, FinishReason: "stop"},
+				},
+				Usage: &models.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+			},
+		},
+	}
+
+	server := NewGRPCServer(mockClient)
+	stream := &mockChatStream{ctx: context.Background()}
+
+	req := &pb.ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []*pb.ChatMessage{
+			{Role: "user", Content: "Hello"},
+		},
+	}
+
+	if err := server.StreamChatCompletion(req, stream); err != nil {
+		t.Fatalf("StreamChatCompletion failed: %v", err)
+	}
+
+	if len(stream.chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(stream.chunks))
+	}
+
+	if stream.chunks[0].Choices[0].Delta.Role != "assistant" {
+		t.Errorf("expected first delta role assistant, got %s", stream.chunks[0].Choices[0].Delta.Role)
+	}
+
+	last := stream.chunks[1]
+	if last.Choices[0].FinishReason != "stop" {
+		t.Errorf("expected finish reason stop, got %s", last.Choices[0].FinishReason)
+	}
+
+	if last.Usage == nil || last.Usage.TotalTokens != 5 {
+		t.Errorf("expected final usage to be forwarded, got %v", last.Usage)
+	}
+
+	// Invalid requests are rejected before reaching the backend
+	if err := server.StreamChatCompletion(&pb.ChatCompletionRequest{}, stream); err == nil {
+		t.Error("expected error for invalid request")
+	}
+}
+
 func TestGRPCServer_ProtoToModel(t *testing.T) {
 	server := &GRPCServer{}
 	
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+)
+
+// checkModel returns auth.ErrModelNotAllowed when the caller in ctx may not
+// use model. Aliases are resolved first, so the model that would be served
+// is checked rather than the name the caller chose.
+func (o serverOptions) checkModel(ctx context.Context, model string) error {
+	if o.aliases != nil {
+		model = o.aliases.Resolve(model)
+	}
+	return auth.CheckModel(ctx, model)
+}
+
+// listModels returns the catalog's models the caller in ctx may use
+func (o serverOptions) listModels(ctx context.Context) ([]models.Model, error) {
+	all, err := o.catalog.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]models.Model, 0, len(all))
+	for _, m := range all {
+		if auth.CheckModel(ctx, m.ID) == nil {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed, nil
+}
+
+// handleListModels lists the models the bridge can serve with their
+// capabilities, in the OpenAI /v1/models shape
+func (s *RESTServer) handleListModels(w http.ResponseWriter, r *http.Request) {
+	list, err := s.opts.listModels(r.Context())
+	if err != nil {
+		s.writeErrorFor(w, r, http.StatusBadGateway, "Failed to list models", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ModelList{Object: "list", Data: list})
+}
+
+// handleGetModel describes a single model
+func (s *RESTServer) handleGetModel(w http.ResponseWriter, r *http.Request) {
+	list, err := s.opts.listModels(r.Context())
+	if err != nil {
+		s.writeErrorFor(w, r, http.StatusBadGateway, "Failed to list models", err)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	for _, m := range list {
+		if m.ID == id {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(m)
+			return
+		}
+	}
+	s.writeErrorFor(w, r, http.StatusNotFound, "Model not found", fmt.Errorf("%w: %s", router.ErrModelNotFound, id))
+}
+
+// ListModels implements the model listing endpoint
+func (s *GRPCServer) ListModels(ctx context.Context, req *pb.ListModelsRequest) (*pb.ListModelsResponse, error) {
+	if s.opts.catalog == nil {
+		return nil, status.Error(codes.Unimplemented, "model listing is not enabled")
+	}
+
+	list, err := s.opts.listModels(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	resp := &pb.ListModelsResponse{}
+	for _, m := range list {
+		resp.Models = append(resp.Models, modelToProto(m))
+	}
+	return resp, nil
+}
+
+// modelToProto converts a model description to protobuf
+func modelToProto(m models.Model) *pb.Model {
+	result := &pb.Model{
+		Id:                m.ID,
+		OwnedBy:           m.OwnedBy,
+		Backend:           m.Backend,
+		ContextLength:     int32(m.ContextLength),
+		SupportsTools:     m.Capabilities.Tools,
+		SupportsVision:    m.Capabilities.Vision,
+		SupportsStreaming: m.Capabilities.Streaming,
+		Aliases:           m.Aliases,
+		Created:           m.Created,
+	}
+	if m.Pricing != nil {
+		result.InputCost = m.Pricing.Input
+		result.OutputCost = m.Pricing.Output
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/catalog"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+)
+
+// staticLister lists a fixed set of models
+type staticLister struct {
+	err error
+}
+
+func (l *staticLister) ListModels(ctx context.Context) ([]models.Model, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	return []models.Model{
+		{ID: "test-model", Backend: "local"},
+		{ID: "gpt-4o", Backend: "cloud", OwnedBy: "openai"},
+	}, nil
+}
+
+func newTestCatalog(lister *staticLister) *catalog.Catalog {
+	cfg := &config.Config{}
+	cfg.Models.Metadata = []config.ModelConfig{{Name: "gpt-4o", ContextLength: 128000, Tools: true, InputCost: 0.0000025}}
+	cfg.Routing.Aliases = map[string]string{"smart": "gpt-4o"}
+	return catalog.New(lister, cfg)
+}
+
+func TestRESTServer_ListModels(t *testing.T) {
+	tests := []struct {
+		name           string
+		lister         *staticLister
+		key            string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{name: "all models", lister: &staticLister{}, expectedStatus: http.StatusOK, expectedIDs: []string{"gpt-4o", "test-model"}},
+		{name: "filtered by key", lister: &staticLister{}, key: "secret-a", expectedStatus: http.StatusOK, expectedIDs: []string{"test-model"}},
+		{name: "backends down", lister: &staticLister{err: errors.New("backend down")}, expectedStatus: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithModels(newTestCatalog(tt.lister))}
+			if tt.key != "" {
+				opts = append(opts, WithAuthenticator(newTestKeyStore(t)))
+			}
+			server := NewRESTServer(&mockOpenWebUIClient{}, opts...)
+
+			req := httptest.NewRequest("GET", "/api/models", nil)
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			w := httptest.NewRecorder()
+			server.GetRouter().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var list models.ModelList
+			if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if list.Object != "list" || len(list.Data) != len(tt.expectedIDs) {
+				t.Fatalf("expected %v, got %+v", tt.expectedIDs, list)
+			}
+			for i, id := range tt.expectedIDs {
+				if list.Data[i].ID != id {
+					t.Errorf("expected model %d to be %s, got %s", i, id, list.Data[i].ID)
+				}
+			}
+			if m := list.Data[0]; m.ID == "gpt-4o" && (m.ContextLength != 128000 || !m.Capabilities.Tools || m.Pricing == nil || len(m.Aliases) != 1) {
+				t.Errorf("expected declared metadata to be merged, got %+v", m)
+			}
+		})
+	}
+}
+
+func TestGRPCServer_ListModels(t *testing.T) {
+	server := NewGRPCServer(&mockOpenWebUIClient{}, WithModels(newTestCatalog(&staticLister{})))
+
+	resp, err := server.ListModels(context.Background(), &pb.ListModelsRequest{})
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(resp.Models) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(resp.Models))
+	}
+	if m := resp.Models[0]; m.Id != "gpt-4o" || m.Backend != "cloud" || m.ContextLength != 128000 || !m.SupportsTools || !m.SupportsStreaming || m.InputCost != 0.0000025 || m.Aliases[0] != "smart" {
+		t.Errorf("unexpected model %+v", m)
+	}
+
+	// Callers only see the models they may use
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "a", AllowedModels: []string{"test-*"}})
+	resp, err = server.ListModels(ctx, &pb.ListModelsRequest{})
+	if err != nil || len(resp.Models) != 1 || resp.Models[0].Id != "test-model" {
+		t.Errorf("expected only test-model, got %v %v", resp, err)
+	}
+
+	disabled := NewGRPCServer(&mockOpenWebUIClient{})
+	if _, err := disabled.ListModels(context.Background(), &pb.ListModelsRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected Unimplemented without a catalog, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/usage"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/window"
+)
+
+// openAIPrefix is the path prefix of the OpenAI-compatible routes, which
+// report errors in OpenAI's envelope
+const openAIPrefix = "/v1/"
+
+// setupOpenAIRoutes registers the OpenAI-compatible API. The handlers are
+// shared with the /api routes; only the error envelope differs.
+func (s *RESTServer) setupOpenAIRoutes() {
+	s.router.HandleFunc("/v1/chat/completions", s.handleChatCompletion).Methods("POST")
+	s.router.HandleFunc("/v1/embeddings", s.handleEmbeddings).Methods("POST")
+	if s.opts.catalog != nil {
+		s.router.HandleFunc("/v1/models", s.handleListModels).Methods("GET")
+		s.router.HandleFunc("/v1/models/{id:.+}", s.handleGetModel).Methods("GET")
+	}
+
+	// Unknown /v1 routes answer in the envelope of their API too
+	s.router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, openAIPrefix) {
+			http.NotFound(w, r)
+			return
+		}
+		s.writeErrorFor(w, r, http.StatusNotFound, "Not found", fmt.Errorf("unknown route %s %s", r.Method, r.URL.Path))
+	})
+	s.router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, openAIPrefix) {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		s.writeErrorFor(w, r, http.StatusMethodNotAllowed, "Method not allowed", fmt.Errorf("%s is not supported on %s", r.Method, r.URL.Path))
+	})
+}
+
+// writeErrorFor writes an error in the envelope of the API r was sent to
+func (s *RESTServer) writeErrorFor(w http.ResponseWriter, r *http.Request, statusCode int, message string, err error) {
+	switch {
+	case isAnthropicPath(r.URL.Path):
+		s.writeAnthropicError(w, statusCode, message, err)
+	case strings.HasPrefix(r.URL.Path, openAIPrefix):
+		s.writeOpenAIError(w, statusCode, message, err)
+	default:
+		s.writeError(w, statusCode, message, err)
+	}
+}
+
+// writeOpenAIError writes an error in OpenAI's envelope. Context overflows
+// are reported as 400 context_length_exceeded, as OpenAI does.
+func (s *RESTServer) writeOpenAIError(w http.ResponseWriter, statusCode int, message string, err error) {
+	log.Printf("API Error: %s - %v", message, err)
+
+	if errors.Is(err, window.ErrContextOverflow) {
+		statusCode = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(openAIError(statusCode, message, err))
+}
+
+// openAIError builds the OpenAI error envelope for a failed request
+func openAIError(statusCode int, message string, err error) models.OpenAIErrorResponse {
+	if err != nil {
+		message = message + ": " + err.Error()
+	}
+
+	errType, code := "invalid_request_error", ""
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		code = "invalid_api_key"
+	case statusCode == http.StatusForbidden:
+		errType = "permission_error"
+	case errors.Is(err, router.ErrModelNotFound):
+		code = "model_not_found"
+	case errors.Is(err, window.ErrContextOverflow):
+		code = "context_length_exceeded"
+	case errors.Is(err, usage.ErrQuotaExceeded):
+		errType, code = "insufficient_quota", "insufficient_quota"
+	case statusCode == http.StatusTooManyRequests:
+		errType, code = "requests", "rate_limit_exceeded"
+	case statusCode >= 500:
+		errType = "server_error"
+	}
+
+	resp := models.OpenAIErrorResponse{Error: models.OpenAIError{Message: message, Type: errType}}
+	if code != "" {
+		resp.Error.Code = &code
+	}
+	return resp
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/ratelimit"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/window"
+)
+
+// loadFixture reads an OpenAI API response from testdata/openai
+func loadFixture(t *testing.T, name string) interface{} {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "openai", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("failed to parse fixture %s: %v", name, err)
+	}
+	return v
+}
+
+// conforms checks that every field of the OpenAI fixture is present in got
+// with the same JSON type. Nullable fields may be null on either side, and
+// every element of an array must match the fixture's first element.
+func conforms(t *testing.T, at string, fixture, got interface{}) {
+	t.Helper()
+
+	if fixture == nil || got == nil {
+		return
+	}
+
+	switch f := fixture.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: expected an object, got %T", at, got)
+			return
+		}
+		for key, value := range f {
+			gv, ok := g[key]
+			if !ok {
+				t.Errorf("%s.%s: missing", at, key)
+				continue
+			}
+			conforms(t, at+"."+key, value, gv)
+		}
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			t.Errorf("%s: expected an array, got %T", at, got)
+			return
+		}
+		if len(f) > 0 && len(g) == 0 {
+			t.Errorf("%s: expected elements", at)
+		}
+		for i, gv := range g {
+			if len(f) > 0 {
+				conforms(t, fmt.Sprintf("%s[%d]", at, i), f[0], gv)
+			}
+		}
+	default:
+		if fmt.Sprintf("%T", fixture) != fmt.Sprintf("%T", got) {
+			t.Errorf("%s: expected %T, got %T", at, fixture, got)
+		}
+	}
+}
+
+// field returns the value at a dotted path in a decoded JSON object
+func field(v interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func TestOpenAI_Conformance(t *testing.T) {
+	chatBody := `{"model":"gpt-4o","messages":[{"role":"user","content":"Hello"}]}`
+
+	tests := []struct {
+		name    string
+		client  *mockOpenWebUIClient
+		opts    func(t *testing.T) []Option
+		method  string
+		path    string
+		body    string
+		headers map[string]string
+		repeat  int // requests sent before the checked one
+
+		expectedStatus int
+		fixture        string
+		exact          []string // fields whose values must equal the fixture's
+	}{
+		{
+			name: "chat completion",
+			client: &mockOpenWebUIClient{chatResponse: &models.ChatCompletionResponse{
+				ID: "chatcmpl-1", Object: "chat.completion", Created: 1741569952, Model: "gpt-4o-2024-08-06",
+				Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: "Hi"}, FinishReason: "stop"}},
+				Usage:   models.Usage{PromptTokens: 19, CompletionTokens: 10, TotalTokens: 29},
+			}},
+			method: "POST", path: "/v1/chat/completions", body: chatBody,
+			expectedStatus: http.StatusOK, fixture: "chat_completion.json", exact: []string{"object"},
+		},
+		{
+			name:   "embeddings",
+			client: &mockOpenWebUIClient{},
+			method: "POST", path: "/v1/embeddings", body: `{"model":"text-embedding-3-small","input":"hello"}`,
+			expectedStatus: http.StatusOK, fixture: "embeddings.json", exact: []string{"object"},
+		},
+		{
+			name:   "embeddings base64",
+			client: &mockOpenWebUIClient{},
+			method: "POST", path: "/v1/embeddings", body: `{"model":"text-embedding-3-small","input":"hello","encoding_format":"base64"}`,
+			expectedStatus: http.StatusOK, fixture: "embeddings_base64.json", exact: []string{"object"},
+		},
+		{
+			name:   "list models",
+			client: &mockOpenWebUIClient{},
+			opts:   func(t *testing.T) []Option { return []Option{WithModels(newTestCatalog(&staticLister{}))} },
+			method: "GET", path: "/v1/models",
+			expectedStatus: http.StatusOK, fixture: "models.json", exact: []string{"object"},
+		},
+		{
+			name:   "retrieve model",
+			client: &mockOpenWebUIClient{},
+			opts:   func(t *testing.T) []Option { return []Option{WithModels(newTestCatalog(&staticLister{}))} },
+			method: "GET", path: "/v1/models/gpt-4o",
+			expectedStatus: http.StatusOK, fixture: "model.json", exact: []string{"id", "object"},
+		},
+		{
+			name:   "missing model",
+			client: &mockOpenWebUIClient{},
+			method: "POST", path: "/v1/chat/completions", body: `{"messages":[{"role":"user","content":"Hello"}]}`,
+			expectedStatus: http.StatusBadRequest, fixture: "error_invalid_request.json", exact: []string{"error.type", "error.code"},
+		},
+		{
+			name:   "malformed body",
+			client: &mockOpenWebUIClient{},
+			method: "POST", path: "/v1/embeddings", body: `{"model":`,
+			expectedStatus: http.StatusBadRequest, fixture: "error_invalid_request.json", exact: []string{"error.type", "error.code"},
+		},
+		{
+			name:   "invalid api key",
+			client: &mockOpenWebUIClient{},
+			opts:   func(t *testing.T) []Option { return []Option{WithAuthenticator(newTestKeyStore(t))} },
+			method: "POST", path: "/v1/chat/completions", body: chatBody,
+			headers:        map[string]string{"Authorization": "Bearer sk-abc"},
+			expectedStatus: http.StatusUnauthorized, fixture: "error_invalid_api_key.json", exact: []string{"error.type", "error.code"},
+		},
+		{
+			name:   "unknown model",
+			client: &mockOpenWebUIClient{chatError: fmt.Errorf("%w: gpt-5-turbo", router.ErrModelNotFound)},
+			method: "POST", path: "/v1/chat/completions", body: chatBody,
+			expectedStatus: http.StatusNotFound, fixture: "error_model_not_found.json", exact: []string{"error.type", "error.code"},
+		},
+		{
+			name:   "unknown model retrieved",
+			client: &mockOpenWebUIClient{},
+			opts:   func(t *testing.T) []Option { return []Option{WithModels(newTestCatalog(&staticLister{}))} },
+			method: "GET", path: "/v1/models/gpt-5-turbo",
+			expectedStatus: http.StatusNotFound, fixture: "error_model_not_found.json", exact: []string{"error.type", "error.code"},
+		},
+		{
+			name:   "context overflow",
+			client: &mockOpenWebUIClient{chatError: fmt.Errorf("%w: 808 over", window.ErrContextOverflow)},
+			method: "POST", path: "/v1/chat/completions", body: chatBody,
+			expectedStatus: http.StatusBadRequest, fixture: "error_context_length_exceeded.json", exact: []string{"error.type", "error.code"},
+		},
+		{
+			name:   "rate limited",
+			client: &mockOpenWebUIClient{chatResponse: &models.ChatCompletionResponse{ID: "test-id"}},
+			opts: func(t *testing.T) []Option {
+				return []Option{WithRateLimiter(ratelimit.New(ratelimit.Limits{RequestsPerMinute: 1}, nil), "key")}
+			},
+			method: "POST", path: "/v1/chat/completions", body: chatBody, repeat: 1,
+			expectedStatus: http.StatusTooManyRequests, fixture: "error_rate_limit.json", exact: []string{"error.type", "error.code"},
+		},
+		{
+			name:   "unknown route",
+			client: &mockOpenWebUIClient{},
+			method: "POST", path: "/v1/completions", body: chatBody,
+			expectedStatus: http.StatusNotFound, fixture: "error_invalid_request.json", exact: []string{"error.type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.opts != nil {
+				opts = tt.opts(t)
+			}
+			server := NewRESTServer(tt.client, opts...)
+
+			var w *httptest.ResponseRecorder
+			for i := 0; i <= tt.repeat; i++ {
+				req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+				for name, value := range tt.headers {
+					req.Header.Set(name, value)
+				}
+				w = httptest.NewRecorder()
+				server.GetRouter().ServeHTTP(w, req)
+			}
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected Content-Type application/json, got %q", ct)
+			}
+
+			var got interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			fixture := loadFixture(t, tt.fixture)
+			conforms(t, "$", fixture, got)
+			for _, path := range tt.exact {
+				if want, have := field(fixture, path), field(got, path); want != have {
+					t.Errorf("%s: expected %v, got %v", path, want, have)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAI_StreamConformance(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{chatChunks: []*models.ChatCompletionChunk{
+		{ID: "chatcmpl-1", Object: "chat.completion.chunk", Created: 1694268190, Model: "gpt-4o-mini", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Role: "assistant", Content: "Hel"}}}},
+		{ID: "chatcmpl-1", Object: "chat.completion.chunk", Created: 1694268190, Model: "gpt-4o-mini", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Content: "lo"}, FinishReason: "stop"}}},
+	}}
+	server := NewRESTServer(mockClient)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"gpt-4o-mini","stream":true,"messages":[{"role":"user","content":"Hello"}]}`))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	fixture := loadFixture(t, "chat_completion_chunk.json")
+	var events []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			events = append(events, data)
+		}
+	}
+	if len(events) != 3 || events[2] != "[DONE]" {
+		t.Fatalf("expected 2 chunks and [DONE], got %v", events)
+	}
+	// Only the first chunk carries the delta role, as with OpenAI
+	var got interface{}
+	if err := json.Unmarshal([]byte(events[0]), &got); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	conforms(t, "chunk", fixture, got)
+	if object := field(got, "object"); object != "chat.completion.chunk" {
+		t.Errorf("expected object chat.completion.chunk, got %v", object)
+	}
+}
+
+func TestOpenAI_StreamError(t *testing.T) {
+	server := NewRESTServer(&mockOpenWebUIClient{chatError: fmt.Errorf("%w: gpt-5-turbo", router.ErrModelNotFound)})
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"gpt-5-turbo","stream":true,"messages":[{"role":"user","content":"Hello"}]}`))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	var got models.OpenAIErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusNotFound || got.Error.Code == nil || *got.Error.Code != "model_not_found" {
+		t.Errorf("expected a model_not_found error before the stream starts, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestOpenAI_APIRoutesKeepBridgeErrors(t *testing.T) {
+	server := NewRESTServer(&mockOpenWebUIClient{})
+
+	req := httptest.NewRequest("POST", "/api/embeddings", strings.NewReader(`{"input":"hello"}`))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	var got models.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Code != http.StatusBadRequest || got.Error != "Invalid request" {
+		t.Errorf("expected the bridge error body on /api routes, got %s", w.Body.String())
+	}
+}
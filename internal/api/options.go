@@ -0,0 +1,123 @@
+package api
+
+import (
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/cache"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/catalog"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/persona"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/ratelimit"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/semantic"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/session"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/usage"
+)
+
+// Option configures optional server dependencies
+type Option func(*serverOptions)
+
+// serverOptions holds the optional dependencies of the REST and gRPC servers
+type serverOptions struct {
+	authenticator auth.Authenticator
+	limiter       *ratelimit.Limiter
+	rateLimitBy   string
+	ledger        *usage.Ledger
+	adminKeys     []string
+	usageKeys     []string
+	personas      *persona.Registry
+	sessions      *session.Manager
+	responses     *cache.Cache
+	semantic      *semantic.Cache
+	catalog       *catalog.Catalog
+	aliases       *router.Router
+}
+
+// newServerOptions applies opts over the defaults
+func newServerOptions(opts []Option) serverOptions {
+	var o serverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithAuthenticator requires REST callers to present credentials accepted
+// by a. gRPC authentication is configured with the auth interceptors.
+func WithAuthenticator(a auth.Authenticator) Option {
+	return func(o *serverOptions) {
+		o.authenticator = a
+	}
+}
+
+// WithRateLimiter throttles REST callers with l, keyed by API key or by
+// tenant according to by. gRPC rate limiting is configured with the rate
+// limit interceptors.
+func WithRateLimiter(l *ratelimit.Limiter, by string) Option {
+	return func(o *serverOptions) {
+		o.limiter = l
+		o.rateLimitBy = by
+	}
+}
+
+// WithAdminKeys lets the key IDs in keys use admin endpoints: persona
+// changes and usage reports. Without authentication every caller may.
+func WithAdminKeys(keys []string) Option {
+	return func(o *serverOptions) {
+		o.adminKeys = keys
+	}
+}
+
+// WithUsageLedger serves usage reports from ledger on /admin/usage. When
+// authentication is enabled only admin keys and the key IDs in adminKeys may
+// read them.
+func WithUsageLedger(ledger *usage.Ledger, adminKeys []string) Option {
+	return func(o *serverOptions) {
+		o.ledger = ledger
+		o.usageKeys = adminKeys
+	}
+}
+
+// WithPersonas resolves persona_id on chat requests from registry and serves
+// the persona CRUD endpoints
+func WithPersonas(registry *persona.Registry) Option {
+	return func(o *serverOptions) {
+		o.personas = registry
+	}
+}
+
+// WithSessions keeps conversation history for requests that set
+// conversation_id and serves the conversation endpoints
+func WithSessions(sessions *session.Manager) Option {
+	return func(o *serverOptions) {
+		o.sessions = sessions
+	}
+}
+
+// WithResponseCache answers repeated identical chat completions from c
+func WithResponseCache(c *cache.Cache) Option {
+	return func(o *serverOptions) {
+		o.responses = c
+	}
+}
+
+// WithSemanticCache answers chat completions whose final user message is
+// similar to an earlier one from c
+func WithSemanticCache(c *semantic.Cache) Option {
+	return func(o *serverOptions) {
+		o.semantic = c
+	}
+}
+
+// WithModels serves the model list from c on /api/models and ListModels
+func WithModels(c *catalog.Catalog) Option {
+	return func(o *serverOptions) {
+		o.catalog = c
+	}
+}
+
+// WithAliases resolves model aliases with r before checking that a caller
+// may use the requested model, so allowed models apply to what is served
+func WithAliases(r *router.Router) Option {
+	return func(o *serverOptions) {
+		o.aliases = r
+	}
+}
@@ -0,0 +1,368 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/persona"
+)
+
+// applyPersona resolves req.PersonaID against registry, which may be nil
+// when the registry is disabled, renders the persona prompt, checks its merge
+// strategy and returns the applied persona
+func applyPersona(registry *persona.Registry, req *models.ChatCompletionRequest) (models.Persona, error) {
+	var applied models.Persona
+	switch {
+	case req.PersonaID == "":
+		if err := persona.RenderPrompt(req, time.Now()); err != nil {
+			return models.Persona{}, err
+		}
+	case registry == nil:
+		return models.Persona{}, fmt.Errorf("%w: %s", persona.ErrNotFound, req.PersonaID)
+	default:
+		p, err := registry.Apply(req)
+		if err != nil {
+			return models.Persona{}, err
+		}
+		applied = p
+	}
+
+	if err := persona.CheckMerge(req); err != nil {
+		return models.Persona{}, err
+	}
+	return applied, nil
+}
+
+// handleListPersonas lists personas, optionally filtered by ?tag=
+func (s *RESTServer) handleListPersonas(w http.ResponseWriter, r *http.Request) {
+	personas, err := s.opts.personas.List(r.URL.Query().Get("tag"))
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to list personas", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, models.PersonaList{Personas: personas})
+}
+
+// handleGetPersona returns a single persona; the ID may pin a version as
+// <id>@<version>
+func (s *RESTServer) handleGetPersona(w http.ResponseWriter, r *http.Request) {
+	p, err := s.opts.personas.Get(mux.Vars(r)["id"])
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to get persona", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, p)
+}
+
+// handleCreatePersona registers a new persona
+func (s *RESTServer) handleCreatePersona(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(r) {
+		s.writeError(w, http.StatusForbidden, "Forbidden", errPersonaAdmin)
+		return
+	}
+
+	var p models.Persona
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	created, err := s.opts.personas.Create(p)
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to create persona", err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, created)
+}
+
+// handleUpdatePersona replaces a persona. The ID is taken from the path;
+// a differing ID in the body is rejected.
+func (s *RESTServer) handleUpdatePersona(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(r) {
+		s.writeError(w, http.StatusForbidden, "Forbidden", errPersonaAdmin)
+		return
+	}
+
+	var p models.Persona
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if p.ID != "" && p.ID != id {
+		s.writeError(w, http.StatusBadRequest, "Invalid request", fmt.Errorf("id %q does not match path", p.ID))
+		return
+	}
+	p.ID = id
+
+	updated, err := s.opts.personas.Update(p)
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to update persona", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, updated)
+}
+
+// handleDeletePersona removes a persona
+func (s *RESTServer) handleDeletePersona(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(r) {
+		s.writeError(w, http.StatusForbidden, "Forbidden", errPersonaAdmin)
+		return
+	}
+	if err := s.opts.personas.Delete(mux.Vars(r)["id"]); err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to delete persona", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListPersonaVersions lists every version of a persona, oldest first
+func (s *RESTServer) handleListPersonaVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := s.opts.personas.Versions(mux.Vars(r)["id"])
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to list persona versions", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, models.PersonaList{Personas: versions})
+}
+
+// handleDiffPersona compares two versions of a persona given as ?from= and
+// ?to=, defaulting to the latest version and the one before it
+func (s *RESTServer) handleDiffPersona(w http.ResponseWriter, r *http.Request) {
+	var versions [2]int
+	for i, name := range []string{"from", "to"} {
+		v := r.URL.Query().Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			s.writeError(w, http.StatusBadRequest, "Invalid request", fmt.Errorf("%s must be a positive version number", name))
+			return
+		}
+		versions[i] = n
+	}
+
+	diff, err := s.opts.personas.Diff(mux.Vars(r)["id"], versions[0], versions[1])
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to diff persona", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, diff)
+}
+
+// handleRollbackPersona restores the content of an earlier version as a new
+// version
+func (s *RESTServer) handleRollbackPersona(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(r) {
+		s.writeError(w, http.StatusForbidden, "Forbidden", errPersonaAdmin)
+		return
+	}
+
+	var req models.RollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	p, err := s.opts.personas.Rollback(mux.Vars(r)["id"], req.Version)
+	if err != nil {
+		s.writeError(w, httpStatusFromError(err), "Failed to roll back persona", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, p)
+}
+
+// writeJSON writes v as a JSON response
+func (s *RESTServer) writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// ListPersonas implements the persona list endpoint
+func (s *GRPCServer) ListPersonas(ctx context.Context, req *pb.ListPersonasRequest) (*pb.ListPersonasResponse, error) {
+	if s.opts.personas == nil {
+		return nil, errPersonasDisabled
+	}
+
+	personas, err := s.opts.personas.List(req.Tag)
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+
+	resp := &pb.ListPersonasResponse{}
+	for _, p := range personas {
+		resp.Personas = append(resp.Personas, personaToProto(p))
+	}
+	return resp, nil
+}
+
+// GetPersona implements the persona get endpoint
+func (s *GRPCServer) GetPersona(ctx context.Context, req *pb.GetPersonaRequest) (*pb.Persona, error) {
+	if s.opts.personas == nil {
+		return nil, errPersonasDisabled
+	}
+
+	p, err := s.opts.personas.Get(req.Id)
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	return personaToProto(p), nil
+}
+
+// CreatePersona implements the persona create endpoint
+func (s *GRPCServer) CreatePersona(ctx context.Context, req *pb.Persona) (*pb.Persona, error) {
+	if s.opts.personas == nil {
+		return nil, errPersonasDisabled
+	}
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	p, err := s.opts.personas.Create(protoToPersona(req))
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	return personaToProto(p), nil
+}
+
+// UpdatePersona implements the persona update endpoint
+func (s *GRPCServer) UpdatePersona(ctx context.Context, req *pb.Persona) (*pb.Persona, error) {
+	if s.opts.personas == nil {
+		return nil, errPersonasDisabled
+	}
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	p, err := s.opts.personas.Update(protoToPersona(req))
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	return personaToProto(p), nil
+}
+
+// DeletePersona implements the persona delete endpoint
+func (s *GRPCServer) DeletePersona(ctx context.Context, req *pb.DeletePersonaRequest) (*pb.DeletePersonaResponse, error) {
+	if s.opts.personas == nil {
+		return nil, errPersonasDisabled
+	}
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.opts.personas.Delete(req.Id); err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	return &pb.DeletePersonaResponse{}, nil
+}
+
+// ListPersonaVersions implements the persona version list endpoint
+func (s *GRPCServer) ListPersonaVersions(ctx context.Context, req *pb.ListPersonaVersionsRequest) (*pb.ListPersonasResponse, error) {
+	if s.opts.personas == nil {
+		return nil, errPersonasDisabled
+	}
+
+	versions, err := s.opts.personas.Versions(req.Id)
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+
+	resp := &pb.ListPersonasResponse{}
+	for _, p := range versions {
+		resp.Personas = append(resp.Personas, personaToProto(p))
+	}
+	return resp, nil
+}
+
+// DiffPersona implements the persona diff endpoint
+func (s *GRPCServer) DiffPersona(ctx context.Context, req *pb.DiffPersonaRequest) (*pb.PersonaDiff, error) {
+	if s.opts.personas == nil {
+		return nil, errPersonasDisabled
+	}
+
+	diff, err := s.opts.personas.Diff(req.Id, int(req.From), int(req.To))
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+
+	resp := &pb.PersonaDiff{
+		Id:         diff.ID,
+		From:       int32(diff.From),
+		To:         int32(diff.To),
+		PromptDiff: diff.PromptDiff,
+	}
+	for _, f := range diff.Fields {
+		resp.Fields = append(resp.Fields, &pb.FieldChange{Field: f.Field, From: f.From, To: f.To})
+	}
+	return resp, nil
+}
+
+// RollbackPersona implements the persona rollback endpoint
+func (s *GRPCServer) RollbackPersona(ctx context.Context, req *pb.RollbackPersonaRequest) (*pb.Persona, error) {
+	if s.opts.personas == nil {
+		return nil, errPersonasDisabled
+	}
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	p, err := s.opts.personas.Rollback(req.Id, int(req.Version))
+	if err != nil {
+		return nil, status.Error(grpcCodeFromError(err), err.Error())
+	}
+	return personaToProto(p), nil
+}
+
+// errPersonaAdmin is returned to REST callers that may not change personas
+var errPersonaAdmin = fmt.Errorf("persona changes require an admin key")
+
+// errPersonasDisabled is returned by the persona RPCs without a registry
+var errPersonasDisabled = status.Error(codes.Unimplemented, "persona registry is not enabled")
+
+// personaToProto converts a persona to protobuf
+func personaToProto(p models.Persona) *pb.Persona {
+	return &pb.Persona{
+		Id:                 p.ID,
+		Version:            int32(p.Version),
+		Name:               p.Name,
+		SystemPrompt:       p.SystemPrompt,
+		DefaultModel:       p.DefaultModel,
+		DefaultTemperature: p.DefaultTemperature,
+		Tags:               p.Tags,
+		MergeStrategy:      p.MergeStrategy,
+		CreatedAt:          p.CreatedAt.Unix(),
+		UpdatedAt:          p.UpdatedAt.Unix(),
+	}
+}
+
+// protoToPersona converts a protobuf persona to the internal model.
+// Timestamps are managed by the registry and ignored.
+func protoToPersona(p *pb.Persona) models.Persona {
+	result := models.Persona{
+		ID:            p.Id,
+		Version:       int(p.Version),
+		Name:          p.Name,
+		SystemPrompt:  p.SystemPrompt,
+		DefaultModel:  p.DefaultModel,
+		Tags:          p.Tags,
+		MergeStrategy: p.MergeStrategy,
+	}
+	if p.DefaultTemperature != nil {
+		t := *p.DefaultTemperature
+		result.DefaultTemperature = &t
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/ratelimit"
+)
+
+// rateLimitMiddleware throttles REST callers and reports their remaining
+// budget in X-RateLimit-* headers. The health endpoint is exempt.
+func (s *RESTServer) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" || r.Method == "OPTIONS" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		principal, _ := auth.FromContext(r.Context())
+		key := ratelimit.KeyFor(principal, s.opts.rateLimitBy, "ip:"+host)
+
+		decision := s.opts.limiter.Allow(key)
+		for name, value := range rateLimitHeaders(decision) {
+			w.Header().Set(name, value)
+		}
+
+		if !decision.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+			s.writeErrorFor(w, r, http.StatusTooManyRequests, "Rate limit exceeded", fmt.Errorf("retry in %v", decision.RetryAfter.Round(time.Second)))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ratelimit.NewContext(r.Context(), key)))
+	})
+}
+
+// RateLimitUnaryInterceptor throttles unary gRPC callers. It must run after
+// the auth interceptor so the caller's key or tenant is known.
+func RateLimitUnaryInterceptor(l *ratelimit.Limiter, by string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == healthCheckMethod {
+			return handler(ctx, req)
+		}
+
+		key, decision := checkGRPCRateLimit(ctx, l, by)
+		grpc.SetHeader(ctx, metadata.New(rateLimitHeaders(decision)))
+		if !decision.Allowed {
+			return nil, rateLimitError(decision)
+		}
+
+		return handler(ratelimit.NewContext(ctx, key), req)
+	}
+}
+
+// RateLimitStreamInterceptor throttles streaming gRPC callers
+func RateLimitStreamInterceptor(l *ratelimit.Limiter, by string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		key, decision := checkGRPCRateLimit(ss.Context(), l, by)
+		ss.SetHeader(metadata.New(rateLimitHeaders(decision)))
+		if !decision.Allowed {
+			return rateLimitError(decision)
+		}
+
+		ctx := ratelimit.NewContext(ss.Context(), key)
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// checkGRPCRateLimit consumes a request from the gRPC caller's budget
+func checkGRPCRateLimit(ctx context.Context, l *ratelimit.Limiter, by string) (string, ratelimit.Decision) {
+	fallback := "ip:unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		fallback = "ip:" + host
+	}
+
+	principal, _ := auth.FromContext(ctx)
+	key := ratelimit.KeyFor(principal, by, fallback)
+	return key, l.Allow(key)
+}
+
+// rateLimitError builds the RESOURCE_EXHAUSTED status for a denied call
+func rateLimitError(decision ratelimit.Decision) error {
+	return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %ds", ceilSeconds(decision.RetryAfter))
+}
+
+// rateLimitHeaders renders a decision as X-RateLimit-* headers. Unlimited
+// budgets are omitted.
+func rateLimitHeaders(decision ratelimit.Decision) map[string]string {
+	headers := make(map[string]string)
+	if decision.RequestLimit > 0 {
+		headers["X-RateLimit-Limit"] = strconv.Itoa(decision.RequestLimit)
+		headers["X-RateLimit-Remaining"] = strconv.Itoa(decision.RequestsLeft)
+	}
+	if decision.TokenLimit > 0 {
+		headers["X-RateLimit-Limit-Tokens"] = strconv.Itoa(decision.TokenLimit)
+		headers["X-RateLimit-Remaining-Tokens"] = strconv.Itoa(decision.TokensLeft)
+	}
+	if len(headers) > 0 {
+		headers["X-RateLimit-Reset"] = strconv.Itoa(ceilSeconds(decision.Reset))
+	}
+	return headers
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/ratelimit"
+)
+
+func TestRESTServer_RateLimit(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{
+		chatResponse: &models.ChatCompletionResponse{ID: "test-id"},
+	}
+	limiter := ratelimit.New(ratelimit.Limits{RequestsPerMinute: 2, TokensPerMinute: 1000}, nil)
+	server := NewRESTServer(mockClient,
+		WithAuthenticator(newTestKeyStore(t)),
+		WithRateLimiter(limiter, "key"),
+	)
+
+	send := func() *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(models.ChatCompletionRequest{
+			Model:    "test-model",
+			Messages: []models.ChatMessage{{Role: "user", Content: "Hello"}},
+		})
+		req := httptest.NewRequest("POST", "/api/chat/completions", bytes.NewBuffer(reqBody))
+		req.Header.Set("X-API-Key", "secret-a")
+		w := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(w, req)
+		return w
+	}
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		w := send()
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("expected X-RateLimit-Limit 2, got %q", got)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != wantRemaining {
+			t.Errorf("expected X-RateLimit-Remaining %s, got %q", wantRemaining, got)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit-Tokens"); got != "1000" {
+			t.Errorf("expected X-RateLimit-Limit-Tokens 1000, got %q", got)
+		}
+	}
+
+	w := send()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+
+	// Health checks are exempt
+	req := httptest.NewRequest("GET", "/health", nil)
+	w = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected health check to bypass rate limiting, got %d", w.Code)
+	}
+}
+
+func TestRateLimitUnaryInterceptor(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limits{RequestsPerMinute: 1}, nil)
+	interceptor := RateLimitUnaryInterceptor(limiter, "key")
+	info := &grpc.UnaryServerInfo{FullMethod: "/fr0g_ai_bridge.Fr0gAiBridge/ChatCompletion"}
+
+	var key string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		key, _ = ratelimit.FromContext(ctx)
+		return &pb.ChatCompletionResponse{}, nil
+	}
+
+	if _, err := interceptor(context.Background(), &pb.ChatCompletionRequest{}, info, handler); err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+	if key != "ip:unknown" {
+		t.Errorf("expected key ip:unknown in context, got %q", key)
+	}
+
+	_, err := interceptor(context.Background(), &pb.ChatCompletionRequest{}, info, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted, got %v", err)
+	}
+
+	// Health checks are exempt
+	healthInfo := &grpc.UnaryServerInfo{FullMethod: healthCheckMethod}
+	if _, err := interceptor(context.Background(), &pb.HealthCheckRequest{}, healthInfo, handler); err != nil {
+		t.Errorf("expected health check to bypass rate limiting, got %v", err)
+	}
+}
+
+func TestRateLimitStreamInterceptor(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limits{RequestsPerMinute: 1}, nil)
+	interceptor := RateLimitStreamInterceptor(limiter, "key")
+	info := &grpc.StreamServerInfo{FullMethod: "/fr0g_ai_bridge.Fr0gAiBridge/StreamChatCompletion"}
+	handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+
+	stream := &mockChatStream{ctx: context.Background()}
+	if err := interceptor(nil, stream, info, handler); err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+	if got := stream.header.Get("x-ratelimit-remaining"); len(got) != 1 || got[0] != "0" {
+		t.Errorf("expected x-ratelimit-remaining 0, got %v", got)
+	}
+
+	stream = &mockChatStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{})}
+	err := interceptor(nil, stream, info, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted, got %v", err)
+	}
+}
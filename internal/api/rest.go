@@ -6,23 +6,28 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/session"
 )
 
 // RESTServer handles REST API requests
 type RESTServer struct {
 	client OpenWebUIClientInterface
 	router *mux.Router
+	opts   serverOptions
 }
 
 // NewRESTServer creates a new REST server
-func NewRESTServer(openWebUIClient OpenWebUIClientInterface) *RESTServer {
+func NewRESTServer(openWebUIClient OpenWebUIClientInterface, opts ...Option) *RESTServer {
 	server := &RESTServer{
 		client: openWebUIClient,
 		router: mux.NewRouter(),
+		opts:   newServerOptions(opts),
 	}
 
 	server.setupRoutes()
@@ -37,9 +42,53 @@ func (s *RESTServer) setupRoutes() {
 	// Chat completion endpoint
 	s.router.HandleFunc("/api/chat/completions", s.handleChatCompletion).Methods("POST")
 
+	// Embeddings endpoint
+	s.router.HandleFunc("/api/embeddings", s.handleEmbeddings).Methods("POST")
+
+	// Model listing endpoint
+	if s.opts.catalog != nil {
+		s.router.HandleFunc("/api/models", s.handleListModels).Methods("GET")
+		s.router.HandleFunc("/api/models/{id:.+}", s.handleGetModel).Methods("GET")
+	}
+
+	// Persona registry endpoints
+	if s.opts.personas != nil {
+		s.router.HandleFunc("/api/personas", s.handleListPersonas).Methods("GET")
+		s.router.HandleFunc("/api/personas", s.handleCreatePersona).Methods("POST")
+		s.router.HandleFunc("/api/personas/{id}", s.handleGetPersona).Methods("GET")
+		s.router.HandleFunc("/api/personas/{id}", s.handleUpdatePersona).Methods("PUT")
+		s.router.HandleFunc("/api/personas/{id}", s.handleDeletePersona).Methods("DELETE")
+		s.router.HandleFunc("/api/personas/{id}/versions", s.handleListPersonaVersions).Methods("GET")
+		s.router.HandleFunc("/api/personas/{id}/diff", s.handleDiffPersona).Methods("GET")
+		s.router.HandleFunc("/api/personas/{id}/rollback", s.handleRollbackPersona).Methods("POST")
+	}
+
+	// Conversation endpoints
+	if s.opts.sessions != nil {
+		s.router.HandleFunc("/api/conversations", s.handleListConversations).Methods("GET")
+		s.router.HandleFunc("/api/conversations", s.handleCreateConversation).Methods("POST")
+		s.router.HandleFunc("/api/conversations/{id}", s.handleGetConversation).Methods("GET")
+		s.router.HandleFunc("/api/conversations/{id}", s.handleDeleteConversation).Methods("DELETE")
+	}
+
+	// Anthropic- and OpenAI-compatible endpoints
+	s.setupAnthropicRoutes()
+	s.setupOpenAIRoutes()
+
+	// Usage report endpoint
+	if s.opts.ledger != nil {
+		s.router.HandleFunc("/admin/usage", s.handleUsageReport).Methods("GET")
+	}
+
 	// Add middleware
 	s.router.Use(s.loggingMiddleware)
 	s.router.Use(s.corsMiddleware)
+	if s.opts.authenticator != nil {
+		s.router.Use(s.authMiddleware)
+	}
+	if s.opts.limiter != nil {
+		s.router.Use(s.rateLimitMiddleware)
+	}
 }
 
 // GetRouter returns the configured router
@@ -53,11 +102,12 @@ func (s *RESTServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	// Check OpenWebUI health
-	err := s.client.HealthCheck(ctx)
+	backends, err := checkHealth(ctx, s.client)
 	
 	response := models.HealthResponse{
-		Time:    time.Now(),
-		Version: "1.0.0",
+		Time:     time.Now(),
+		Version:  "1.0.0",
+		Backends: backends,
 	}
 
 	if err != nil {
@@ -78,25 +128,62 @@ func (s *RESTServer) handleChatCompletion(w http.ResponseWriter, r *http.Request
 	// Parse request
 	var req models.ChatCompletionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		s.writeErrorFor(w, r, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
+	// Prepend the stored history when continuing a conversation
+	turn, err := prepareConversation(r.Context(), s.opts.sessions, &req)
+	if err != nil {
+		s.writeErrorFor(w, r, httpStatusFromError(err), "Invalid conversation", err)
+		return
+	}
+	defer turn.Release()
+
+	// Resolve the persona, which may supply the model
+	applied, err := applyPersona(s.opts.personas, &req)
+	if err != nil {
+		s.writeErrorFor(w, r, httpStatusFromError(err), "Invalid persona", err)
+		return
+	}
+	if applied.ID != "" {
+		w.Header().Set("X-Persona-Id", applied.ID)
+		w.Header().Set("X-Persona-Version", strconv.Itoa(applied.Version))
+	}
+
 	// Validate request
 	if err := s.validateChatCompletionRequest(&req); err != nil {
-		s.writeError(w, http.StatusBadRequest, "Invalid request", err)
+		s.writeErrorFor(w, r, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	// Check the caller may use the requested model
+	if err := s.opts.checkModel(r.Context(), req.Model); err != nil {
+		s.writeErrorFor(w, r, http.StatusForbidden, "Forbidden", err)
 		return
 	}
 
-	// Forward to OpenWebUI
+	// Streaming requests are answered with server-sent events
+	if req.Stream != nil && *req.Stream {
+		s.handleChatCompletionStream(w, r, &req, applied, turn)
+		return
+	}
+
+	// Answer from the response cache or forward to OpenWebUI
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	resp, err := s.client.ChatCompletion(ctx, &req)
+	noCache := strings.Contains(strings.ToLower(r.Header.Get("Cache-Control")), "no-cache")
+	resp, cached, err := cachedCompletion(ctx, s.opts, s.client, &req, noCache)
+	setCacheHeaders(w, cached)
 	if err != nil {
-		s.writeError(w, http.StatusInternalServerError, "Failed to process chat completion", err)
+		s.writeErrorFor(w, r, httpStatusFromError(err), "Failed to process chat completion", err)
 		return
 	}
+	resp.PersonaID = applied.ID
+	resp.PersonaVersion = applied.Version
+	resp.ConversationID = req.ConversationID
+	recordConversation(turn, replyMessage(resp))
 
 	// Return response
 	w.Header().Set("Content-Type", "application/json")
@@ -104,6 +191,90 @@ func (s *RESTServer) handleChatCompletion(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(resp)
 }
 
+// handleChatCompletionStream relays upstream chunks to the caller as
+// OpenAI-style server-sent events terminated by "data: [DONE]". The request
+// context is passed upstream so a client disconnect cancels the backend call.
+// Conversation turns are recorded only when the stream completes.
+func (s *RESTServer) handleChatCompletionStream(w http.ResponseWriter, r *http.Request, req *models.ChatCompletionRequest, applied models.Persona, turn *session.Turn) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeErrorFor(w, r, http.StatusInternalServerError, "Streaming not supported", nil)
+		return
+	}
+
+	started := false
+	includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+	var reply streamReply
+	err := s.client.ChatCompletionStream(r.Context(), req, func(chunk *models.ChatCompletionChunk) error {
+		// Backends are asked for a final usage-only chunk for accounting;
+		// callers only see it when they asked for it too
+		if len(chunk.Choices) == 0 && chunk.Usage != nil && !includeUsage {
+			return nil
+		}
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+
+		chunk.PersonaID = applied.ID
+		chunk.PersonaVersion = applied.Version
+		chunk.ConversationID = req.ConversationID
+		reply.add(chunk)
+		if err := writeSSE(w, chunk); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+
+	if err != nil {
+		if !started {
+			s.writeErrorFor(w, r, httpStatusFromError(err), "Failed to process chat completion", err)
+			return
+		}
+
+		// Headers are already sent, so report the failure in-band
+		log.Printf("API Error: stream interrupted - %v", err)
+		if r.Context().Err() == nil {
+			if strings.HasPrefix(r.URL.Path, openAIPrefix) {
+				writeSSE(w, openAIError(http.StatusInternalServerError, "Stream interrupted", err))
+			} else {
+				writeSSE(w, models.ErrorResponse{
+					Error:   "Stream interrupted",
+					Message: err.Error(),
+					Code:    http.StatusInternalServerError,
+				})
+			}
+			flusher.Flush()
+		}
+		return
+	}
+
+	recordConversation(turn, reply.reply())
+
+	if !started {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+// writeSSE writes a single server-sent event carrying v as JSON
+func writeSSE(w http.ResponseWriter, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
+
 // validateChatCompletionRequest validates the chat completion request
 func (s *RESTServer) validateChatCompletionRequest(req *models.ChatCompletionRequest) error {
 	if req.Model == "" {
@@ -113,14 +284,11 @@ func (s *RESTServer) validateChatCompletionRequest(req *models.ChatCompletionReq
 		return fmt.Errorf("messages are required")
 	}
 	for i, msg := range req.Messages {
-		if msg.Role == "" {
-			return fmt.Errorf("message %d: role is required", i)
-		}
-		if msg.Content == "" {
-			return fmt.Errorf("message %d: content is required", i)
+		if err := validateMessage(i, msg); err != nil {
+			return err
 		}
 	}
-	return nil
+	return validateTools(req)
 }
 
 // writeError writes an error response
@@ -167,6 +335,14 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush forwards to the underlying writer so streaming handlers keep working
+// behind the logging middleware
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // corsMiddleware adds CORS headers
 func (s *RESTServer) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
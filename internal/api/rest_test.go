@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/router"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/window"
 )
 
 // mockOpenWebUIClient is a mock implementation of OpenWebUIClient for testing
@@ -17,6 +21,9 @@ type mockOpenWebUIClient struct {
 	healthCheckError error
 	chatResponse     *models.ChatCompletionResponse
 	chatError        error
+	chatChunks       []*models.ChatCompletionChunk
+	chatRequest      *models.ChatCompletionRequest
+	embedRequest     *models.EmbeddingRequest
 }
 
 func (m *mockOpenWebUIClient) HealthCheck(ctx context.Context) error {
@@ -24,12 +31,38 @@ func (m *mockOpenWebUIClient) HealthCheck(ctx context.Context) error {
 }
 
 func (m *mockOpenWebUIClient) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	m.chatRequest = req
 	if m.chatError != nil {
 		return nil, m.chatError
 	}
 	return m.chatResponse, nil
 }
 
+func (m *mockOpenWebUIClient) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	m.chatRequest = req
+	if m.chatError != nil {
+		return m.chatError
+	}
+	for _, chunk := range m.chatChunks {
+		if err := onChunk(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockOpenWebUIClient) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	m.embedRequest = req
+	if m.chatError != nil {
+		return nil, m.chatError
+	}
+	resp := &models.EmbeddingResponse{Model: req.Model, Usage: models.Usage{PromptTokens: len(req.Input), TotalTokens: len(req.Input)}}
+	for i := range req.Input {
+		resp.Data = append(resp.Data, models.Embedding{Object: "embedding", Index: i, Embedding: []float64{float64(i), 0.5}})
+	}
+	return resp, nil
+}
+
 func TestRESTServer_HealthCheck(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -83,6 +116,44 @@ func TestRESTServer_HealthCheck(t *testing.T) {
 	}
 }
 
+// mockRoutingClient adds per-backend health reporting to the mock client
+type mockRoutingClient struct {
+	mockOpenWebUIClient
+	backends []models.BackendHealth
+}
+
+func (m *mockRoutingClient) BackendHealth(ctx context.Context) []models.BackendHealth {
+	return m.backends
+}
+
+func TestRESTServer_HealthCheckBackends(t *testing.T) {
+	mockClient := &mockRoutingClient{
+		backends: []models.BackendHealth{
+			{Name: "cloud", Status: "healthy", Circuit: "closed"},
+			{Name: "local", Status: "unhealthy", Circuit: "open"},
+		},
+	}
+
+	server := NewRESTServer(mockClient)
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	server.handleHealth(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	var response models.HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(response.Backends) != 2 || response.Backends[1].Circuit != "open" {
+		t.Errorf("expected backend report in health response, got %+v", response.Backends)
+	}
+}
+
 func TestRESTServer_ChatCompletion(t *testing.T) {
 	mockResponse := &models.ChatCompletionResponse{
 		ID:      "test-id",
@@ -134,6 +205,28 @@ func TestRESTServer_ChatCompletion(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "unknown model",
+			request: models.ChatCompletionRequest{
+				Model: "unknown-model",
+				Messages: []models.ChatMessage{
+					{Role: "user", Content: "Hello"},
+				},
+			},
+			mockError:      fmt.Errorf("%w: unknown-model", router.ErrModelNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "context window exceeded",
+			request: models.ChatCompletionRequest{
+				Model: "test-model",
+				Messages: []models.ChatMessage{
+					{Role: "user", Content: "Hello"},
+				},
+			},
+			mockError:      fmt.Errorf("%w: 10 over", window.ErrContextOverflow),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
 		{
 			name: "empty messages",
 			request: models.ChatCompletionRequest{
@@ -244,3 +337,104 @@ func TestRESTServer_ValidateChatCompletionRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestRESTServer_ChatCompletionStream(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{
+		chatChunks: []*models.ChatCompletionChunk{
+			{
+				ID:     "test-id",
+				Object: "chat.completion.chunk",
+				Model:  "test-model",
+				Choices: []models.ChunkChoice{
+					{Delta: models.ChatDelta{Role: "assistant", Content: "Hel"}},
+				},
+			},
+			{
+				ID:     "test-id",
+				Object: "chat.completion.chunk",
+				Model:  "test-model",
+				Choices: []models.ChunkChoice{
+					{Delta: models.ChatDelta{Content: "lo"}, FinishReason: "stop"},
+				},
+			},
+		},
+	}
+
+	server := NewRESTServer(mockClient)
+
+	stream := true
+	reqBody, _ := json.Marshal(models.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []models.ChatMessage{{Role: "user", Content: "Hello"}},
+		Stream:   &stream,
+	})
+	req := httptest.NewRequest("POST", "/api/chat/completions", bytes.NewBuffer(reqBody))
+	w := httptest.NewRecorder()
+
+	server.GetRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %s", ct)
+	}
+
+	var events []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, "data: ") {
+			events = append(events, strings.TrimPrefix(line, "data: "))
+		}
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d: %v", len(events), events)
+	}
+
+	if events[2] != "[DONE]" {
+		t.Errorf("expected final [DONE] event, got %s", events[2])
+	}
+
+	var chunk models.ChatCompletionChunk
+	if err := json.Unmarshal([]byte(events[1]), &chunk); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+
+	if chunk.Choices[0].Delta.Content != "lo" || chunk.Choices[0].FinishReason != "stop" {
+		t.Errorf("unexpected chunk: %+v", chunk)
+	}
+}
+
+func TestRESTServer_ChatCompletionStreamUsage(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{
+		chatChunks: []*models.ChatCompletionChunk{
+			{ID: "test-id", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Content: "Hi"}, FinishReason: "stop"}}},
+			{ID: "test-id", Usage: &models.Usage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4}},
+		},
+	}
+	server := NewRESTServer(mockClient)
+
+	tests := []struct {
+		name           string
+		streamOptions  string
+		expectedEvents int
+	}{
+		{name: "not requested", expectedEvents: 2},
+		{name: "requested", streamOptions: `,"stream_options":{"include_usage":true}`, expectedEvents: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"model":"test-model","stream":true,"messages":[{"role":"user","content":"Hello"}]` + tt.streamOptions + `}`
+			req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			server.GetRouter().ServeHTTP(w, req)
+
+			if events := strings.Count(w.Body.String(), "data: "); events != tt.expectedEvents {
+				t.Errorf("expected %d events, got %d: %s", tt.expectedEvents, events, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+)
+
+// validateMessage validates a single message of a chat completion request
+func validateMessage(i int, msg models.ChatMessage) error {
+	if err := msg.Validate(); err != nil {
+		return fmt.Errorf("message %d: %w", i, err)
+	}
+	return nil
+}
+
+// validateTools validates the tools of a chat completion request and that
+// tool_choice can be honoured with them
+func validateTools(req *models.ChatCompletionRequest) error {
+	names := make(map[string]bool, len(req.Tools))
+	for i, tool := range req.Tools {
+		if tool.Type != "function" {
+			return fmt.Errorf("tool %d: unsupported type %q", i, tool.Type)
+		}
+		if tool.Function.Name == "" {
+			return fmt.Errorf("tool %d: function name is required", i)
+		}
+		if len(tool.Function.Parameters) > 0 && !json.Valid(tool.Function.Parameters) {
+			return fmt.Errorf("tool %d: parameters must be a JSON schema", i)
+		}
+		names[tool.Function.Name] = true
+	}
+
+	choice := req.ToolChoice
+	switch {
+	case choice == nil:
+	case choice.Function != "":
+		if !names[choice.Function] {
+			return fmt.Errorf("tool_choice: unknown function %q", choice.Function)
+		}
+	case choice.Mode == models.ToolChoiceRequired:
+		if len(req.Tools) == 0 {
+			return fmt.Errorf("tool_choice: required needs tools")
+		}
+	case choice.Mode != models.ToolChoiceNone && choice.Mode != models.ToolChoiceAuto:
+		return fmt.Errorf("tool_choice: unsupported mode %q", choice.Mode)
+	}
+	return nil
+}
+
+// messageToProto converts a chat message to protobuf
+func messageToProto(msg models.ChatMessage) *pb.ChatMessage {
+	return &pb.ChatMessage{
+		Role:       msg.Role,
+		Content:    msg.Content,
+		ToolCalls:  toolCallsToProto(msg.ToolCalls),
+		ToolCallId: msg.ToolCallID,
+	}
+}
+
+// protoToMessage converts a protobuf chat message to the internal model
+func protoToMessage(msg *pb.ChatMessage) models.ChatMessage {
+	result := models.ChatMessage{
+		Role:       msg.Role,
+		Content:    msg.Content,
+		ToolCallID: msg.ToolCallId,
+	}
+	for _, call := range msg.ToolCalls {
+		result.ToolCalls = append(result.ToolCalls, models.ToolCall{
+			ID:   call.Id,
+			Type: call.Type,
+			Function: models.FunctionCall{
+				Name:      call.GetFunction().GetName(),
+				Arguments: call.GetFunction().GetArguments(),
+			},
+		})
+	}
+	return result
+}
+
+// toolCallsToProto converts tool calls or their streamed fragments to
+// protobuf
+func toolCallsToProto(calls []models.ToolCall) []*pb.ToolCall {
+	var result []*pb.ToolCall
+	for _, call := range calls {
+		protoCall := &pb.ToolCall{
+			Id:   call.ID,
+			Type: call.Type,
+			Function: &pb.FunctionCall{
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			},
+		}
+		if call.Index != nil {
+			protoCall.Index = int32(*call.Index)
+		}
+		result = append(result, protoCall)
+	}
+	return result
+}
+
+// protoToTools converts the protobuf tools and tool choice of a request to
+// the internal model
+func protoToTools(req *pb.ChatCompletionRequest, modelReq *models.ChatCompletionRequest) {
+	for _, tool := range req.Tools {
+		converted := models.Tool{
+			Type: tool.Type,
+			Function: models.ToolFunction{
+				Name:        tool.GetFunction().GetName(),
+				Description: tool.GetFunction().GetDescription(),
+			},
+		}
+		if parameters := tool.GetFunction().GetParameters(); parameters != "" {
+			converted.Function.Parameters = json.RawMessage(parameters)
+		}
+		modelReq.Tools = append(modelReq.Tools, converted)
+	}
+
+	if req.ToolChoice != nil {
+		modelReq.ToolChoice = &models.ToolChoice{
+			Mode:     req.ToolChoice.Mode,
+			Function: req.ToolChoice.Function,
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	pb "github.com/fr0g-vibe/fr0g-ai-bridge/internal/pb"
+)
+
+func TestValidateMessage(t *testing.T) {
+	call := models.ToolCall{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: "{}"}}
+
+	tests := []struct {
+		name    string
+		msg     models.ChatMessage
+		wantErr bool
+	}{
+		{"user", models.ChatMessage{Role: "user", Content: "Hi"}, false},
+		{"missing role", models.ChatMessage{Content: "Hi"}, true},
+		{"empty user content", models.ChatMessage{Role: "user"}, true},
+		{"empty tool result", models.ChatMessage{Role: "tool", ToolCallID: "call_1"}, false},
+		{"assistant calling tools", models.ChatMessage{Role: "assistant", ToolCalls: []models.ToolCall{call}}, false},
+		{"user calling tools", models.ChatMessage{Role: "user", Content: "Hi", ToolCalls: []models.ToolCall{call}}, true},
+		{"call without name", models.ChatMessage{Role: "assistant", ToolCalls: []models.ToolCall{{ID: "call_1"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMessage(0, tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateTools(t *testing.T) {
+	weather := models.Tool{Type: "function", Function: models.ToolFunction{Name: "get_weather", Parameters: json.RawMessage(`{"type":"object"}`)}}
+
+	tests := []struct {
+		name    string
+		tools   []models.Tool
+		choice  *models.ToolChoice
+		wantErr bool
+	}{
+		{"no tools", nil, nil, false},
+		{"function tool", []models.Tool{weather}, nil, false},
+		{"unsupported type", []models.Tool{{Type: "retrieval", Function: weather.Function}}, nil, true},
+		{"missing name", []models.Tool{{Type: "function"}}, nil, true},
+		{"invalid parameters", []models.Tool{{Type: "function", Function: models.ToolFunction{Name: "f", Parameters: json.RawMessage(`{`)}}}, nil, true},
+		{"auto", []models.Tool{weather}, &models.ToolChoice{Mode: models.ToolChoiceAuto}, false},
+		{"none without tools", nil, &models.ToolChoice{Mode: models.ToolChoiceNone}, false},
+		{"required", []models.Tool{weather}, &models.ToolChoice{Mode: models.ToolChoiceRequired}, false},
+		{"required without tools", nil, &models.ToolChoice{Mode: models.ToolChoiceRequired}, true},
+		{"unknown mode", []models.Tool{weather}, &models.ToolChoice{Mode: "always"}, true},
+		{"named function", []models.Tool{weather}, &models.ToolChoice{Function: "get_weather"}, false},
+		{"unknown function", []models.Tool{weather}, &models.ToolChoice{Function: "get_time"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTools(&models.ChatCompletionRequest{Tools: tt.tools, ToolChoice: tt.choice})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRESTServer_ChatCompletionTools(t *testing.T) {
+	mockClient := &mockOpenWebUIClient{chatResponse: &models.ChatCompletionResponse{
+		ID: "test-id",
+		Choices: []models.Choice{{
+			Message: models.ChatMessage{Role: "assistant", ToolCalls: []models.ToolCall{
+				{ID: "call_2", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Lyon"}`}},
+			}},
+			FinishReason: "tool_calls",
+		}},
+	}}
+	server := NewRESTServer(mockClient)
+
+	body := `{
+		"model": "test-model",
+		"tools": [{"type": "function", "function": {"name": "get_weather", "parameters": {"type": "object"}}}],
+		"tool_choice": {"type": "function", "function": {"name": "get_weather"}},
+		"messages": [
+			{"role": "user", "content": "Weather in Paris?"},
+			{"role": "assistant", "content": "", "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}]},
+			{"role": "tool", "tool_call_id": "call_1", "content": ""}
+		]
+	}`
+	req := httptest.NewRequest("POST", "/api/chat/completions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	sent := mockClient.chatRequest
+	if len(sent.Tools) != 1 || sent.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("expected tools to be passed through, got %+v", sent.Tools)
+	}
+	if sent.ToolChoice == nil || sent.ToolChoice.Function != "get_weather" {
+		t.Errorf("expected tool_choice to be passed through, got %+v", sent.ToolChoice)
+	}
+	expected := []models.ChatMessage{
+		{Role: "user", Content: "Weather in Paris?"},
+		{Role: "assistant", ToolCalls: []models.ToolCall{
+			{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		{Role: "tool", ToolCallID: "call_1"},
+	}
+	if !reflect.DeepEqual(sent.Messages, expected) {
+		t.Errorf("expected messages %+v, got %+v", expected, sent.Messages)
+	}
+
+	var resp models.ChatCompletionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Choices) != 1 || len(resp.Choices[0].Message.ToolCalls) != 1 || resp.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("expected a tool call in the response, got %+v", resp.Choices)
+	}
+}
+
+func TestRESTServer_ChatCompletionInvalidToolChoice(t *testing.T) {
+	server := NewRESTServer(&mockOpenWebUIClient{})
+
+	body := `{"model":"test-model","tool_choice":"required","messages":[{"role":"user","content":"Hi"}]}`
+	req := httptest.NewRequest("POST", "/api/chat/completions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGRPCServer_ToolConversion(t *testing.T) {
+	server := &GRPCServer{}
+
+	protoReq := &pb.ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []*pb.ChatMessage{
+			{Role: "user", Content: "Weather in Paris?"},
+			{Role: "assistant", ToolCalls: []*pb.ToolCall{
+				{Id: "call_1", Type: "function", Function: &pb.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: "tool", ToolCallId: "call_1", Content: "18C"},
+		},
+		Tools: []*pb.Tool{
+			{Type: "function", Function: &pb.ToolFunction{Name: "get_weather", Description: "Current weather", Parameters: `{"type":"object"}`}},
+		},
+		ToolChoice: &pb.ToolChoice{Mode: "required"},
+	}
+
+	modelReq := server.protoToModel(protoReq)
+
+	expectedMessages := []models.ChatMessage{
+		{Role: "user", Content: "Weather in Paris?"},
+		{Role: "assistant", ToolCalls: []models.ToolCall{
+			{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		{Role: "tool", ToolCallID: "call_1", Content: "18C"},
+	}
+	if !reflect.DeepEqual(modelReq.Messages, expectedMessages) {
+		t.Errorf("expected messages %+v, got %+v", expectedMessages, modelReq.Messages)
+	}
+	expectedTools := []models.Tool{
+		{Type: "function", Function: models.ToolFunction{Name: "get_weather", Description: "Current weather", Parameters: json.RawMessage(`{"type":"object"}`)}},
+	}
+	if !reflect.DeepEqual(modelReq.Tools, expectedTools) {
+		t.Errorf("expected tools %+v, got %+v", expectedTools, modelReq.Tools)
+	}
+	if modelReq.ToolChoice == nil || modelReq.ToolChoice.Mode != models.ToolChoiceRequired {
+		t.Errorf("expected tool_choice required, got %+v", modelReq.ToolChoice)
+	}
+
+	protoResp := server.modelToProto(&models.ChatCompletionResponse{
+		Choices: []models.Choice{{Message: expectedMessages[1], FinishReason: "tool_calls"}},
+	})
+	calls := protoResp.Choices[0].Message.ToolCalls
+	if len(calls) != 1 || calls[0].Id != "call_1" || calls[0].Function.GetArguments() != `{"city":"Paris"}` {
+		t.Errorf("tool calls not converted correctly: %+v", calls)
+	}
+
+	index := 1
+	protoChunk := server.chunkToProto(&models.ChatCompletionChunk{
+		Choices: []models.ChunkChoice{{Delta: models.ChatDelta{ToolCalls: []models.ToolCall{
+			{Index: &index, Function: models.FunctionCall{Arguments: `{"ci`}},
+		}}}},
+	})
+	fragments := protoChunk.Choices[0].Delta.ToolCalls
+	if len(fragments) != 1 || fragments[0].Index != 1 || fragments[0].Function.GetArguments() != `{"ci` {
+		t.Errorf("tool call fragments not converted correctly: %+v", fragments)
+	}
+}
+
+func TestStreamReply_ToolCalls(t *testing.T) {
+	index := func(i int) *int { return &i }
+	fragments := [][]models.ToolCall{
+		{{Index: index(0), ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "a", Arguments: `{"x":`}}},
+		{{Index: index(1), ID: "call_2", Type: "function", Function: models.FunctionCall{Name: "b"}}},
+		{{Index: index(0), Function: models.FunctionCall{Arguments: `1}`}}},
+		{{Index: index(1), Function: models.FunctionCall{Arguments: `{}`}}},
+		{{ID: "call_3", Type: "function", Function: models.FunctionCall{Name: "c", Arguments: `{"y"`}}},
+		{{Function: models.FunctionCall{Arguments: `:2}`}}},
+	}
+
+	var reply streamReply
+	for _, calls := range fragments {
+		reply.add(&models.ChatCompletionChunk{Choices: []models.ChunkChoice{{Delta: models.ChatDelta{ToolCalls: calls}}}})
+	}
+
+	expected := models.ChatMessage{Role: "assistant", ToolCalls: []models.ToolCall{
+		{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "a", Arguments: `{"x":1}`}},
+		{ID: "call_2", Type: "function", Function: models.FunctionCall{Name: "b", Arguments: `{}`}},
+		{ID: "call_3", Type: "function", Function: models.FunctionCall{Name: "c", Arguments: `{"y":2}`}},
+	}}
+	if got := reply.reply(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
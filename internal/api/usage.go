@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/usage"
+)
+
+// usageReport is the body of an /admin/usage response
+type usageReport struct {
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	GroupBy []string          `json:"group_by"`
+	Rows    []usage.ReportRow `json:"rows"`
+	Total   usage.Totals      `json:"total"`
+}
+
+// handleUsageReport reports token usage from the ledger. Query parameters:
+// from and to (YYYY-MM-DD, inclusive, default the current month), group_by
+// (comma-separated day, key, tenant and model, default "tenant,model") and
+// key, tenant and model filters.
+func (s *RESTServer) handleUsageReport(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(r, s.opts.usageKeys...) {
+		s.writeError(w, http.StatusForbidden, "Forbidden", fmt.Errorf("usage reports require an admin key"))
+		return
+	}
+
+	query := r.URL.Query()
+	now := time.Now().UTC()
+
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid request", fmt.Errorf("from: %w", err))
+			return
+		}
+		from = t
+	}
+
+	to := now
+	if v := query.Get("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid request", fmt.Errorf("to: %w", err))
+			return
+		}
+		to = t
+	}
+
+	groupBy := []string{usage.GroupTenant, usage.GroupModel}
+	if v := query.Get("group_by"); v != "" {
+		groupBy = strings.Split(v, ",")
+	}
+
+	filter := usage.Filter{
+		Key:    query.Get("key"),
+		Tenant: query.Get("tenant"),
+		Model:  query.Get("model"),
+	}
+
+	rows, total, err := s.opts.ledger.Report(from, to, filter, groupBy)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(usageReport{
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		GroupBy: groupBy,
+		Rows:    rows,
+		Total:   total,
+	})
+}
+
+// isAdmin reports whether the caller is an admin key or one of extra.
+// Without authentication every caller is trusted, like every other endpoint.
+func (s *RESTServer) isAdmin(r *http.Request, extra ...string) bool {
+	if s.opts.authenticator == nil {
+		return true
+	}
+	return s.opts.isAdmin(r.Context(), extra)
+}
+
+// requireAdmin rejects gRPC callers that may not use admin endpoints. gRPC
+// authentication is done by the interceptors, which reject unauthenticated
+// calls, so a context without a principal means authentication is disabled.
+func (s *GRPCServer) requireAdmin(ctx context.Context) error {
+	if _, ok := auth.FromContext(ctx); !ok {
+		return nil
+	}
+	if !s.opts.isAdmin(ctx, nil) {
+		return status.Error(codes.PermissionDenied, "this call requires an admin key")
+	}
+	return nil
+}
+
+// isAdmin reports whether the principal in ctx is an admin key or one of
+// extra. Only API keys are matched: a token subject equal to an admin key ID
+// gets no admin rights.
+func (o *serverOptions) isAdmin(ctx context.Context, extra []string) bool {
+	principal, ok := auth.FromContext(ctx)
+	if !ok || principal.Method != "api_key" {
+		return false
+	}
+	for _, keys := range [][]string{o.adminKeys, extra} {
+		for _, id := range keys {
+			if id == principal.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/usage"
+)
+
+func TestRESTServer_UsageReport(t *testing.T) {
+	ledger, _ := usage.Open("")
+	ledger.Record(usage.Record{Key: "team-a", Tenant: "acme", Model: "gpt-4o", TotalTokens: 15})
+	ledger.Record(usage.Record{Key: "team-a", Tenant: "acme", Model: "llama3", TotalTokens: 5})
+
+	store, err := auth.NewKeyStore([]config.APIKeyConfig{
+		{ID: "team-a", Hash: auth.HashKey("secret-a")},
+		{ID: "finance", Hash: auth.HashKey("secret-finance")},
+	})
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+	server := NewRESTServer(&mockOpenWebUIClient{},
+		WithAuthenticator(store),
+		WithUsageLedger(ledger, []string{"finance"}),
+	)
+
+	tests := []struct {
+		name           string
+		key            string
+		query          string
+		expectedStatus int
+		expectedRows   int
+	}{
+		{name: "not an admin", key: "secret-a", expectedStatus: http.StatusForbidden},
+		{name: "default grouping", key: "secret-finance", expectedStatus: http.StatusOK, expectedRows: 2},
+		{name: "group by tenant", key: "secret-finance", query: "?group_by=tenant", expectedStatus: http.StatusOK, expectedRows: 1},
+		{name: "model filter", key: "secret-finance", query: "?model=llama3", expectedStatus: http.StatusOK, expectedRows: 1},
+		{name: "bad date", key: "secret-finance", query: "?from=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "bad group", key: "secret-finance", query: "?group_by=project", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/admin/usage"+tt.query, nil)
+			req.Header.Set("X-API-Key", tt.key)
+			w := httptest.NewRecorder()
+
+			server.GetRouter().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var report usageReport
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatalf("failed to decode report: %v", err)
+			}
+			if len(report.Rows) != tt.expectedRows {
+				t.Errorf("expected %d rows, got %d: %+v", tt.expectedRows, len(report.Rows), report.Rows)
+			}
+		})
+	}
+}
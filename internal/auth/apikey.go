@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+// hashPrefix marks the hashing scheme of stored key hashes
+const hashPrefix = "sha256:"
+
+// HashKey returns the at-rest representation of a raw API key
+func HashKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// KeyStore authenticates bridge-issued API keys against their hashes
+type KeyStore struct {
+	byHash map[string]config.APIKeyConfig
+}
+
+// NewKeyStore creates a key store from key records
+func NewKeyStore(keys []config.APIKeyConfig) (*KeyStore, error) {
+	s := &KeyStore{byHash: make(map[string]config.APIKeyConfig)}
+
+	for i, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("API key %d: id is required", i)
+		}
+		if strings.Contains(key.ID, ":") {
+			return nil, fmt.Errorf("API key %q: id must not contain ':'", key.ID)
+		}
+		if !strings.HasPrefix(key.Hash, hashPrefix) {
+			return nil, fmt.Errorf("API key %q: hash must start with %q", key.ID, hashPrefix)
+		}
+
+		hash := strings.ToLower(key.Hash)
+		if _, exists := s.byHash[hash]; exists {
+			return nil, fmt.Errorf("API key %q: duplicate hash", key.ID)
+		}
+		s.byHash[hash] = key
+	}
+
+	return s, nil
+}
+
+// NewKeyStoreFromConfig creates a key store from the keys listed in the auth
+// config and, if set, its key file
+func NewKeyStoreFromConfig(cfg config.AuthConfig) (*KeyStore, error) {
+	keys := append([]config.APIKeyConfig(nil), cfg.Keys...)
+
+	if cfg.KeyFile != "" {
+		fileKeys, err := LoadKeyFile(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	return NewKeyStore(keys)
+}
+
+// LoadKeyFile reads key records from a YAML file with a top-level keys list
+func LoadKeyFile(path string) ([]config.APIKeyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var file struct {
+		Keys []config.APIKeyConfig `yaml:"keys"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %w", err)
+	}
+
+	return file.Keys, nil
+}
+
+// Authenticate looks up a raw API key
+func (s *KeyStore) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	key, ok := s.byHash[HashKey(credential)]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	if key.Enabled != nil && !*key.Enabled {
+		return nil, ErrKeyDisabled
+	}
+
+	return &Principal{
+		ID:            key.ID,
+		Owner:         key.Owner,
+		Tenant:        key.Tenant,
+		AllowedModels: key.AllowedModels,
+		Method:        "api_key",
+	}, nil
+}
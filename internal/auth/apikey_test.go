@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+func TestKeyStore_Authenticate(t *testing.T) {
+	disabled := false
+	store, err := NewKeyStore([]config.APIKeyConfig{
+		{ID: "team-a", Hash: HashKey("secret-a"), Owner: "alice", Tenant: "team-a", AllowedModels: []string{"llama3*"}},
+		{ID: "old", Hash: HashKey("secret-old"), Enabled: &disabled},
+	})
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		credential string
+		wantErr    error
+	}{
+		{name: "valid", credential: "secret-a"},
+		{name: "missing", credential: "", wantErr: ErrMissingCredentials},
+		{name: "unknown", credential: "nope", wantErr: ErrInvalidCredentials},
+		{name: "disabled", credential: "secret-old", wantErr: ErrKeyDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := store.Authenticate(context.Background(), tt.credential)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil {
+				if principal.ID != "team-a" || principal.Owner != "alice" || principal.Tenant != "team-a" {
+					t.Errorf("unexpected principal: %+v", principal)
+				}
+				if principal.Method != "api_key" {
+					t.Errorf("expected method api_key, got %s", principal.Method)
+				}
+			}
+		})
+	}
+}
+
+func TestNewKeyStore_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []config.APIKeyConfig
+	}{
+		{name: "missing id", keys: []config.APIKeyConfig{{Hash: HashKey("a")}}},
+		{name: "plaintext hash", keys: []config.APIKeyConfig{{ID: "a", Hash: "secret"}}},
+		{name: "qualified id", keys: []config.APIKeyConfig{{ID: "jwt:a", Hash: HashKey("a")}}},
+		{name: "duplicate", keys: []config.APIKeyConfig{{ID: "a", Hash: HashKey("a")}, {ID: "b", Hash: HashKey("a")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyStore(tt.keys); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestNewKeyStoreFromConfig_KeyFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys.yaml")
+	content := "keys:\n  - id: \"from-file\"\n    hash: \"" + HashKey("file-secret") + "\"\n    tenant: \"ops\"\n"
+	if err := os.WriteFile(keyFile, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	store, err := NewKeyStoreFromConfig(config.AuthConfig{
+		Keys:    []config.APIKeyConfig{{ID: "inline", Hash: HashKey("inline-secret")}},
+		KeyFile: keyFile,
+	})
+	if err != nil {
+		t.Fatalf("NewKeyStoreFromConfig failed: %v", err)
+	}
+
+	for _, credential := range []string{"inline-secret", "file-secret"} {
+		if _, err := store.Authenticate(context.Background(), credential); err != nil {
+			t.Errorf("expected %s to authenticate, got %v", credential, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+// Authentication errors
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrKeyDisabled        = errors.New("API key disabled")
+	ErrModelNotAllowed    = errors.New("model not allowed for this caller")
+)
+
+// Principal identifies an authenticated caller
+type Principal struct {
+	ID            string   // Key ID or token subject
+	Owner         string   // Human or service owning the credential
+	Tenant        string   // Tenant the caller is accounted to
+	AllowedModels []string // Model names or glob patterns; empty allows all
+	Method        string   // Authentication method, e.g. "api_key"
+}
+
+// Key returns the identity the principal is accounted under: the key ID for
+// API keys, and the ID qualified by the authentication method otherwise, so
+// a token subject never shares limits, quotas or conversations with an API
+// key of the same ID
+func (p *Principal) Key() string {
+	if p.Method == "" || p.Method == "api_key" {
+		return p.ID
+	}
+	return p.Method + ":" + p.ID
+}
+
+// AllowsModel reports whether the principal may use model
+func (p *Principal) AllowsModel(model string) bool {
+	if len(p.AllowedModels) == 0 {
+		return true
+	}
+
+	for _, pattern := range p.AllowedModels {
+		if pattern == model {
+			return true
+		}
+		if ok, _ := path.Match(pattern, model); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authenticator validates a raw credential taken from a request
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, if any
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// CheckModel returns ErrModelNotAllowed when the principal in ctx may not use
+// model. Unauthenticated contexts are allowed, since authentication is
+// optional.
+func CheckModel(ctx context.Context, model string) error {
+	if p, ok := FromContext(ctx); ok && !p.AllowsModel(model) {
+		return ErrModelNotAllowed
+	}
+	return nil
+}
+
+// BearerToken extracts the credential from an Authorization header value
+func BearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+// Chain tries each authenticator in order and returns the first principal
+// accepted by any of them
+type Chain []Authenticator
+
+// Authenticate implements Authenticator
+func (c Chain) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	var lastErr error = ErrInvalidCredentials
+	for _, a := range c {
+		principal, err := a.Authenticate(ctx, credential)
+		if err == nil {
+			return principal, nil
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// NewFromConfig creates the authenticator described by the auth config: the
+// API key store, followed by JWT validation when enabled
+func NewFromConfig(cfg config.AuthConfig) (Authenticator, error) {
+	keyStore, err := NewKeyStoreFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := Chain{keyStore}
+	if cfg.JWT.Enabled {
+		validator, err := NewJWTValidator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, validator)
+	}
+
+	return chain, nil
+}
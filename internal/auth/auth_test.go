@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPrincipal_AllowsModel(t *testing.T) {
+	p := &Principal{AllowedModels: []string{"gpt-4o", "llama3*"}}
+
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{model: "gpt-4o", want: true},
+		{model: "llama3.1:8b", want: true},
+		{model: "gpt-4o-mini", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := p.AllowsModel(tt.model); got != tt.want {
+			t.Errorf("AllowsModel(%s) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+
+	if !(&Principal{}).AllowsModel("anything") {
+		t.Error("expected empty allow list to allow all models")
+	}
+}
+
+func TestPrincipal_Key(t *testing.T) {
+	tests := []struct {
+		principal Principal
+		want      string
+	}{
+		{principal: Principal{ID: "team-a", Method: "api_key"}, want: "team-a"},
+		{principal: Principal{ID: "team-a", Method: "jwt"}, want: "jwt:team-a"},
+		{principal: Principal{ID: "team-a"}, want: "team-a"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.principal.Key(); got != tt.want {
+			t.Errorf("Key() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCheckModel(t *testing.T) {
+	if err := CheckModel(context.Background(), "gpt-4o"); err != nil {
+		t.Errorf("expected unauthenticated context to be allowed, got %v", err)
+	}
+
+	ctx := NewContext(context.Background(), &Principal{AllowedModels: []string{"llama3*"}})
+	if err := CheckModel(ctx, "gpt-4o"); !errors.Is(err, ErrModelNotAllowed) {
+		t.Errorf("expected ErrModelNotAllowed, got %v", err)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "Bearer abc", want: "abc"},
+		{header: "bearer  abc ", want: "abc"},
+		{header: "Basic abc", want: ""},
+		{header: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := BearerToken(tt.header); got != tt.want {
+			t.Errorf("BearerToken(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+// staticAuthenticator accepts a single credential
+type staticAuthenticator struct {
+	credential string
+	principal  *Principal
+}
+
+func (s *staticAuthenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if credential != s.credential {
+		return nil, ErrInvalidCredentials
+	}
+	return s.principal, nil
+}
+
+func TestChain(t *testing.T) {
+	chain := Chain{
+		&staticAuthenticator{credential: "key", principal: &Principal{ID: "from-key"}},
+		&staticAuthenticator{credential: "token", principal: &Principal{ID: "from-token"}},
+	}
+
+	for credential, want := range map[string]string{"key": "from-key", "token": "from-token"} {
+		principal, err := chain.Authenticate(context.Background(), credential)
+		if err != nil {
+			t.Fatalf("Authenticate(%s) failed: %v", credential, err)
+		}
+		if principal.ID != want {
+			t.Errorf("expected %s, got %s", want, principal.ID)
+		}
+	}
+
+	if _, err := chain.Authenticate(context.Background(), "nope"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	if _, err := chain.Authenticate(context.Background(), ""); !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("expected ErrMissingCredentials, got %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// minJWKSRefresh limits how often an unknown key ID triggers a refetch
+const minJWKSRefresh = 10 * time.Second
+
+// jwksFetchTimeout bounds a key set fetch, which runs on behalf of every
+// caller waiting for it rather than on any one request's context
+const jwksFetchTimeout = 30 * time.Second
+
+// jsonWebKey is a single entry of a JSON Web Key Set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS is a cached JSON Web Key Set loaded from a file or URL. Fetches run
+// outside the lock and are shared by concurrent callers, so a slow identity
+// provider only delays the requests that cannot be served from the cache.
+type JWKS struct {
+	mu        sync.Mutex
+	load      func(ctx context.Context) ([]byte, error)
+	ttl       time.Duration
+	keys      map[string]crypto.PublicKey // replaced, never modified
+	fetchedAt time.Time
+	fetching  *jwksFetch
+	now       func() time.Time
+}
+
+// jwksFetch is a key set fetch in progress
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWKSFromFile creates a key set read from a local file, re-read every ttl
+func NewJWKSFromFile(path string, ttl time.Duration) *JWKS {
+	return newJWKS(func(ctx context.Context) ([]byte, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		return data, nil
+	}, ttl)
+}
+
+// NewJWKSFromURL creates a key set fetched over HTTP and cached for ttl
+func NewJWKSFromURL(url string, ttl time.Duration, httpClient *http.Client) *JWKS {
+	return newJWKS(func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+		}
+
+		return io.ReadAll(resp.Body)
+	}, ttl)
+}
+
+func newJWKS(load func(ctx context.Context) ([]byte, error), ttl time.Duration) *JWKS {
+	return &JWKS{
+		load: load,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Key returns the public key with the given ID. An empty kid is accepted
+// when the set holds exactly one key. Stale sets are refreshed, and an
+// unknown kid triggers a rate-limited refresh to pick up rotated keys.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	keys, fetchedAt := j.cached()
+
+	stale := keys == nil || j.now().Sub(fetchedAt) > j.ttl
+	if stale {
+		// Without a cached set every caller waits for the fetch; with a
+		// stale one, callers arriving during a refresh keep using it
+		if err := j.refresh(ctx, keys == nil); err != nil && keys == nil {
+			return nil, err
+		}
+		keys, fetchedAt = j.cached()
+	}
+
+	key, ok := lookup(keys, kid)
+	if !ok && !stale && j.now().Sub(fetchedAt) > minJWKSRefresh {
+		if err := j.refresh(ctx, true); err != nil {
+			return nil, err
+		}
+		keys, _ = j.cached()
+		key, ok = lookup(keys, kid)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// cached returns the current key set and when it was fetched
+func (j *JWKS) cached() (map[string]crypto.PublicKey, time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.keys, j.fetchedAt
+}
+
+// lookup finds a key by ID
+func lookup(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	key, ok := keys[kid]
+	return key, ok
+}
+
+// refresh reloads the key set. Only one fetch runs at a time: when one is
+// already in progress, refresh waits for it if wait is set and otherwise
+// returns immediately. The fetch is not tied to ctx, so a caller giving up
+// does not fail it for the others waiting on it.
+func (j *JWKS) refresh(ctx context.Context, wait bool) error {
+	j.mu.Lock()
+	f := j.fetching
+	if f == nil {
+		f = &jwksFetch{done: make(chan struct{})}
+		j.fetching = f
+		go j.fetch(f)
+	} else if !wait {
+		j.mu.Unlock()
+		return nil
+	}
+	j.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch loads and parses the key set, then completes f
+func (j *JWKS) fetch(f *jwksFetch) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+
+	var keys map[string]crypto.PublicKey
+	data, err := j.load(ctx)
+	if err == nil {
+		keys, err = parseJWKS(data)
+	}
+
+	j.mu.Lock()
+	if err == nil {
+		j.keys = keys
+		j.fetchedAt = j.now()
+	}
+	j.fetching = nil
+	j.mu.Unlock()
+
+	f.err = err
+	close(f.done)
+}
+
+// parseJWKS decodes the RSA and P-256 signing keys of a key set, skipping
+// keys of other types
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		switch jwk.Kty {
+		case "RSA":
+			n, err := decodeBigInt(jwk.N)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: invalid modulus: %w", jwk.Kid, err)
+			}
+			e, err := decodeBigInt(jwk.E)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: invalid exponent: %w", jwk.Kid, err)
+			}
+			keys[jwk.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			if jwk.Crv != "P-256" {
+				continue
+			}
+			x, err := decodeBigInt(jwk.X)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: invalid x coordinate: %w", jwk.Kid, err)
+			}
+			y, err := decodeBigInt(jwk.Y)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: invalid y coordinate: %w", jwk.Kid, err)
+			}
+			keys[jwk.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		}
+	}
+
+	return keys, nil
+}
+
+// decodeBigInt decodes a base64url-encoded unsigned big-endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
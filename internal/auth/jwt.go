@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+// JWTValidator authenticates RS256 and ES256 signed bearer tokens issued by
+// an external identity provider
+type JWTValidator struct {
+	keys        *JWKS
+	issuer      string
+	audience    string
+	leeway      time.Duration
+	tenantClaim string
+	ownerClaim  string
+	modelsClaim string
+	now         func() time.Time
+}
+
+// NewJWTValidator creates a validator from the JWT auth config
+func NewJWTValidator(cfg config.JWTConfig) (*JWTValidator, error) {
+	ttl := time.Duration(cfg.JWKSCacheSeconds) * time.Second
+
+	var keys *JWKS
+	switch {
+	case cfg.JWKSFile != "":
+		keys = NewJWKSFromFile(cfg.JWKSFile, ttl)
+	case cfg.JWKSURL != "":
+		keys = NewJWKSFromURL(cfg.JWKSURL, ttl, &http.Client{Timeout: 10 * time.Second})
+	default:
+		return nil, fmt.Errorf("jwt: jwks_file or jwks_url is required")
+	}
+
+	return &JWTValidator{
+		keys:        keys,
+		issuer:      cfg.Issuer,
+		audience:    cfg.Audience,
+		leeway:      time.Duration(cfg.LeewaySeconds) * time.Second,
+		tenantClaim: cfg.TenantClaim,
+		ownerClaim:  cfg.OwnerClaim,
+		modelsClaim: cfg.ModelsClaim,
+		now:         time.Now,
+	}, nil
+}
+
+// Authenticate verifies a compact-serialized JWT and maps its claims to a
+// principal
+func (v *JWTValidator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	parts := strings.Split(credential, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header", ErrInvalidCredentials)
+	}
+
+	key, err := v.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid signature encoding", ErrInvalidCredentials)
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid claims", ErrInvalidCredentials)
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	principal := &Principal{
+		ID:     stringClaim(claims, "sub"),
+		Owner:  stringClaim(claims, v.ownerClaim),
+		Tenant: stringClaim(claims, v.tenantClaim),
+		Method: "jwt",
+	}
+	if v.modelsClaim != "" {
+		principal.AllowedModels = stringsClaim(claims, v.modelsClaim)
+	}
+
+	return principal, nil
+}
+
+// validateClaims checks expiry, not-before, issuer and audience
+func (v *JWTValidator) validateClaims(claims map[string]interface{}) error {
+	now := v.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("missing exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.leeway)) {
+		return fmt.Errorf("token expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token not yet valid")
+	}
+
+	if v.issuer != "" && stringClaim(claims, "iss") != v.issuer {
+		return fmt.Errorf("unexpected issuer")
+	}
+
+	if v.audience != "" {
+		found := false
+		for _, aud := range stringsClaim(claims, "aud") {
+			if aud == v.audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unexpected audience")
+		}
+	}
+
+	return nil
+}
+
+// verifySignature checks the JWS signature for the supported algorithms
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		if len(signature) != 64 {
+			return fmt.Errorf("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	return nil
+}
+
+// decodeSegment decodes a base64url JSON token segment into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// lookupClaim resolves a claim name, following dots into nested objects
+func lookupClaim(claims map[string]interface{}, name string) interface{} {
+	if name == "" {
+		return nil
+	}
+	if value, ok := claims[name]; ok {
+		return value
+	}
+
+	var current interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = object[part]
+	}
+	return current
+}
+
+// stringClaim returns a string claim, or "" when absent
+func stringClaim(claims map[string]interface{}, name string) string {
+	value, _ := lookupClaim(claims, name).(string)
+	return value
+}
+
+// stringsClaim returns a claim given either as a string array or as a
+// space-separated string
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	switch value := lookupClaim(claims, name).(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,344 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+// testKeys holds locally generated signing keys and their JWKS document
+type testKeys struct {
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	jwks   []byte
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+
+	enc := base64.RawURLEncoding.EncodeToString
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-1",
+				"use": "sig",
+				"n":   enc(rsaKey.N.Bytes()),
+				"e":   enc(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec-1",
+				"crv": "P-256",
+				"x":   enc(ecKey.X.FillBytes(make([]byte, 32))),
+				"y":   enc(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	})
+
+	return &testKeys{rsaKey: rsaKey, ecKey: ecKey, jwks: jwks}
+}
+
+// sign creates a compact JWT with the given algorithm, key ID and claims
+func (k *testKeys) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	enc := base64.RawURLEncoding.EncodeToString
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := enc(header) + "." + enc(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		sig, err := rsa.SignPKCS1v15(rand.Reader, k.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		signature = sig
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, k.ecKey, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signingInput + "." + enc(signature)
+}
+
+func newTestValidator(t *testing.T, keys *testKeys) *JWTValidator {
+	t.Helper()
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwksFile, keys.jwks, 0644); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+
+	v, err := NewJWTValidator(config.JWTConfig{
+		Issuer:           "https://idp.example.com",
+		Audience:         "fr0g-ai-bridge",
+		JWKSFile:         jwksFile,
+		JWKSCacheSeconds: 300,
+		LeewaySeconds:    30,
+		TenantClaim:      "org.id",
+		OwnerClaim:       "email",
+		ModelsClaim:      "models",
+	})
+	if err != nil {
+		t.Fatalf("NewJWTValidator failed: %v", err)
+	}
+	return v
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":    "svc-search",
+		"iss":    "https://idp.example.com",
+		"aud":    []string{"other", "fr0g-ai-bridge"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"email":  "search@example.com",
+		"org":    map[string]string{"id": "team-search"},
+		"models": "llama3* gpt-4o",
+	}
+}
+
+func TestJWTValidator_Authenticate(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newTestValidator(t, keys)
+
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "valid RS256", token: keys.sign(t, "RS256", "rsa-1", validClaims())},
+		{name: "valid ES256", token: keys.sign(t, "ES256", "ec-1", validClaims())},
+		{name: "expired", token: keys.sign(t, "RS256", "rsa-1", with("exp", time.Now().Add(-time.Hour).Unix())), wantErr: true},
+		{name: "expired within leeway", token: keys.sign(t, "RS256", "rsa-1", with("exp", time.Now().Add(-10*time.Second).Unix()))},
+		{name: "missing exp", token: keys.sign(t, "RS256", "rsa-1", with("exp", nil)), wantErr: true},
+		{name: "not yet valid", token: keys.sign(t, "RS256", "rsa-1", with("nbf", time.Now().Add(time.Hour).Unix())), wantErr: true},
+		{name: "wrong issuer", token: keys.sign(t, "RS256", "rsa-1", with("iss", "https://evil.example.com")), wantErr: true},
+		{name: "wrong audience", token: keys.sign(t, "RS256", "rsa-1", with("aud", "other")), wantErr: true},
+		{name: "unknown kid", token: keys.sign(t, "RS256", "rsa-2", validClaims()), wantErr: true},
+		{name: "algorithm mismatch", token: keys.sign(t, "ES256", "rsa-1", validClaims()), wantErr: true},
+		{name: "malformed", token: "not-a-jwt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := v.Authenticate(context.Background(), tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Errorf("expected ErrInvalidCredentials, got %v", err)
+				}
+				return
+			}
+
+			if principal.ID != "svc-search" || principal.Owner != "search@example.com" || principal.Tenant != "team-search" {
+				t.Errorf("unexpected principal: %+v", principal)
+			}
+
+			if len(principal.AllowedModels) != 2 || principal.Method != "jwt" {
+				t.Errorf("unexpected principal: %+v", principal)
+			}
+		})
+	}
+}
+
+func TestJWTValidator_RejectsTamperedToken(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newTestValidator(t, keys)
+
+	token := keys.sign(t, "RS256", "rsa-1", validClaims())
+	claims := validClaims()
+	claims["sub"] = "admin"
+	forged := keys.sign(t, "RS256", "rsa-1", claims)
+
+	// Combine the header and signature of one token with another's claims
+	parts := strings.Split(token, ".")
+	forgedParts := strings.Split(forged, ".")
+	tampered := parts[0] + "." + forgedParts[1] + "." + parts[2]
+
+	if _, err := v.Authenticate(context.Background(), tampered); err == nil {
+		t.Error("expected tampered token to be rejected")
+	}
+
+	// alg "none" is never accepted
+	enc := base64.RawURLEncoding.EncodeToString
+	payload, _ := json.Marshal(validClaims())
+	unsigned := enc([]byte(`{"alg":"none","kid":"rsa-1"}`)) + "." + enc(payload) + "."
+	if _, err := v.Authenticate(context.Background(), unsigned); err == nil {
+		t.Error("expected unsigned token to be rejected")
+	}
+}
+
+func TestJWKS_URLCaching(t *testing.T) {
+	keys := newTestKeys(t)
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(keys.jwks)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	jwks := NewJWKSFromURL(server.URL, time.Minute, server.Client())
+	jwks.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := jwks.Key(context.Background(), "rsa-1"); err != nil {
+			t.Fatalf("Key failed: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected 1 fetch while cached, got %d", fetches)
+	}
+
+	// Unknown key IDs only trigger a refetch after the minimum interval
+	jwks.Key(context.Background(), "rotated")
+	if fetches != 1 {
+		t.Errorf("expected no refetch within minimum interval, got %d", fetches)
+	}
+	now = now.Add(minJWKSRefresh + time.Second)
+	jwks.Key(context.Background(), "rotated")
+	if fetches != 2 {
+		t.Errorf("expected refetch for unknown kid, got %d", fetches)
+	}
+
+	// Expired caches are refreshed
+	now = now.Add(2 * time.Minute)
+	jwks.Key(context.Background(), "rsa-1")
+	if fetches != 3 {
+		t.Errorf("expected refetch after ttl, got %d", fetches)
+	}
+}
+
+func TestJWKS_RefreshKeepsServingCachedKeys(t *testing.T) {
+	keys := newTestKeys(t)
+
+	var fetches int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			close(started)
+			<-release
+		}
+		w.Write(keys.jwks)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	now := time.Unix(1700000000, 0)
+	jwks := NewJWKSFromURL(server.URL, time.Minute, server.Client())
+	jwks.now = func() time.Time { return now }
+	if _, err := jwks.Key(context.Background(), "rsa-1"); err != nil {
+		t.Fatalf("Key failed: %v", err)
+	}
+
+	// The first caller after expiry refreshes; others keep the cached set
+	now = now.Add(2 * time.Minute)
+	done := make(chan error, 1)
+	go func() {
+		_, err := jwks.Key(context.Background(), "rsa-1")
+		done <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := jwks.Key(ctx, "rsa-1"); err != nil {
+		t.Fatalf("expected cached key during refresh, got %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected a single refresh, got %d fetches", n)
+	}
+
+	release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Errorf("refresh failed: %v", err)
+	}
+}
+
+func TestJWKS_FetchOutlivesCaller(t *testing.T) {
+	keys := newTestKeys(t)
+
+	var fetches int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		close(started)
+		<-release
+		w.Write(keys.jwks)
+	}))
+	defer server.Close()
+
+	jwks := NewJWKSFromURL(server.URL, time.Minute, server.Client())
+
+	// The caller that starts the fetch gives up on it
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := jwks.Key(ctx, "rsa-1")
+		done <- err
+	}()
+	<-started
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancelled caller to give up, got %v", err)
+	}
+
+	// Others still get the keys from the same fetch
+	close(release)
+	if _, err := jwks.Key(context.Background(), "rsa-1"); err != nil {
+		t.Errorf("expected the fetch to complete, got %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("expected a single fetch, got %d", n)
+	}
+}
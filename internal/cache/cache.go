@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Cache statuses reported to callers in the X-Cache header
+const (
+	Hit  = "HIT"
+	Miss = "MISS"
+)
+
+// keyVersion is mixed into every key so a change to the key fields never
+// serves entries written by an older bridge
+const keyVersion = "v2"
+
+// Cache answers identical chat completion requests with a stored response
+// instead of calling upstream again. Store failures are logged and treated
+// as misses, so a broken cache never fails a request.
+type Cache struct {
+	store             Store
+	ttl               time.Duration
+	deterministicOnly bool
+	now               func() time.Time
+}
+
+// New creates a cache backed by store with the TTL and policy of cfg
+func New(store Store, cfg config.CacheConfig) *Cache {
+	return &Cache{
+		store:             store,
+		ttl:               time.Duration(cfg.TTLSeconds) * time.Second,
+		deterministicOnly: cfg.DeterministicOnly,
+		now:               time.Now,
+	}
+}
+
+// Cacheable reports whether the response to req may be served from and
+// stored in the cache. Streams are never cached, requests can opt out with
+// cache: false, and with deterministic_only only requests sampled at
+// temperature 0 are cached.
+func (c *Cache) Cacheable(req *models.ChatCompletionRequest) bool {
+	if req.Stream != nil && *req.Stream {
+		return false
+	}
+	if req.Cache != nil && !*req.Cache {
+		return false
+	}
+	if c.deterministicOnly && (req.Temperature == nil || *req.Temperature != 0) {
+		return false
+	}
+	return true
+}
+
+// Key returns the canonical hash of everything that shapes the reply to req:
+// the model, the messages, the rendered persona prompt and how it is merged,
+// the sampling parameters and the tools on offer. Persona IDs and variables are covered by the
+// prompt they render to; conversation IDs by the history they expand to.
+func Key(req *models.ChatCompletionRequest) string {
+	data, _ := json.Marshal(struct {
+		Version       string               `json:"v"`
+		Model         string               `json:"model"`
+		Messages      []models.ChatMessage `json:"messages"`
+		PersonaPrompt string               `json:"persona_prompt"`
+		PersonaMerge  string               `json:"persona_merge"`
+		Temperature   *float64             `json:"temperature"`
+		MaxTokens     *int                 `json:"max_tokens"`
+		Tools         []models.Tool        `json:"tools"`
+		ToolChoice    *models.ToolChoice   `json:"tool_choice"`
+	}{
+		Version:       keyVersion,
+		Model:         req.Model,
+		Messages:      req.Messages,
+		PersonaPrompt: req.PersonaPrompt,
+		PersonaMerge:  req.PersonaMerge,
+		Temperature:   req.Temperature,
+		MaxTokens:     req.MaxTokens,
+		Tools:         req.Tools,
+		ToolChoice:    req.ToolChoice,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the unexpired response stored under key
+func (c *Cache) Get(key string) (*models.ChatCompletionResponse, bool) {
+	e, ok, err := c.store.Get(key)
+	if err != nil {
+		log.Printf("Response cache: failed to read %s: %v", key, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	if expired(e, c.now()) {
+		if err := c.store.Delete(key); err != nil {
+			log.Printf("Response cache: failed to remove %s: %v", key, err)
+		}
+		return nil, false
+	}
+	return &e.Response, true
+}
+
+// Put stores resp under key for the configured TTL
+func (c *Cache) Put(key string, resp *models.ChatCompletionResponse) {
+	e := Entry{Response: *resp}
+	if c.ttl > 0 {
+		e.ExpiresAt = c.now().Add(c.ttl)
+	}
+	if err := c.store.Put(key, e); err != nil {
+		log.Printf("Response cache: failed to write %s: %v", key, err)
+	}
+}
+
+// Close closes the underlying store
+func (c *Cache) Close() error {
+	return c.store.Close()
+}
+
+// expired reports whether e is past its expiry at t
+func expired(e Entry, t time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !t.Before(e.ExpiresAt)
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func float(f float64) *float64 { return &f }
+func boolean(b bool) *bool     { return &b }
+
+func baseRequest() *models.ChatCompletionRequest {
+	maxTokens := 100
+	return &models.ChatCompletionRequest{
+		Model:       "test-model",
+		Messages:    []models.ChatMessage{{Role: "user", Content: "Hello"}},
+		Temperature: float(0),
+		MaxTokens:   &maxTokens,
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := Key(baseRequest())
+
+	tests := []struct {
+		name   string
+		modify func(*models.ChatCompletionRequest)
+		same   bool
+	}{
+		{name: "identical", modify: func(*models.ChatCompletionRequest) {}, same: true},
+		{name: "conversation id", modify: func(r *models.ChatCompletionRequest) { r.ConversationID = "conv-1" }, same: true},
+		{name: "cache flag", modify: func(r *models.ChatCompletionRequest) { r.Cache = boolean(true) }, same: true},
+		{name: "model", modify: func(r *models.ChatCompletionRequest) { r.Model = "other" }},
+		{name: "message", modify: func(r *models.ChatCompletionRequest) { r.Messages[0].Content = "Hi" }},
+		{name: "role", modify: func(r *models.ChatCompletionRequest) { r.Messages[0].Role = "system" }},
+		{name: "extra message", modify: func(r *models.ChatCompletionRequest) {
+			r.Messages = append(r.Messages, models.ChatMessage{Role: "user", Content: ""})
+		}},
+		{name: "persona prompt", modify: func(r *models.ChatCompletionRequest) { r.PersonaPrompt = "Be terse." }},
+		{name: "persona merge", modify: func(r *models.ChatCompletionRequest) { r.PersonaMerge = models.MergeAppend }},
+		{name: "temperature", modify: func(r *models.ChatCompletionRequest) { r.Temperature = float(0.5) }},
+		{name: "unset temperature", modify: func(r *models.ChatCompletionRequest) { r.Temperature = nil }},
+		{name: "max tokens", modify: func(r *models.ChatCompletionRequest) { r.MaxTokens = nil }},
+		{name: "tools", modify: func(r *models.ChatCompletionRequest) {
+			r.Tools = []models.Tool{{Type: "function", Function: models.ToolFunction{Name: "get_weather"}}}
+		}},
+		{name: "tool choice", modify: func(r *models.ChatCompletionRequest) { r.ToolChoice = &models.ToolChoice{Mode: models.ToolChoiceNone} }},
+		{name: "tool call", modify: func(r *models.ChatCompletionRequest) {
+			r.Messages = append(r.Messages, models.ChatMessage{Role: "assistant", ToolCalls: []models.ToolCall{{ID: "call_1"}}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := baseRequest()
+			tt.modify(req)
+			if got := Key(req) == base; got != tt.same {
+				t.Errorf("expected same key = %v, got %v", tt.same, got)
+			}
+		})
+	}
+}
+
+func TestCache_Cacheable(t *testing.T) {
+	deterministic := New(NewMemoryStore(10), config.CacheConfig{DeterministicOnly: true})
+	all := New(NewMemoryStore(10), config.CacheConfig{})
+
+	tests := []struct {
+		name             string
+		modify           func(*models.ChatCompletionRequest)
+		deterministicHit bool
+		allHit           bool
+	}{
+		{name: "temperature 0", modify: func(*models.ChatCompletionRequest) {}, deterministicHit: true, allHit: true},
+		{name: "sampled", modify: func(r *models.ChatCompletionRequest) { r.Temperature = float(0.7) }, allHit: true},
+		{name: "default temperature", modify: func(r *models.ChatCompletionRequest) { r.Temperature = nil }, allHit: true},
+		{name: "opted out", modify: func(r *models.ChatCompletionRequest) { r.Cache = boolean(false) }},
+		{name: "stream", modify: func(r *models.ChatCompletionRequest) { r.Stream = boolean(true) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := baseRequest()
+			tt.modify(req)
+			if got := deterministic.Cacheable(req); got != tt.deterministicHit {
+				t.Errorf("deterministic_only: expected %v, got %v", tt.deterministicHit, got)
+			}
+			if got := all.Cacheable(req); got != tt.allHit {
+				t.Errorf("expected %v, got %v", tt.allHit, got)
+			}
+		})
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	store := NewMemoryStore(10)
+	c := New(store, config.CacheConfig{TTLSeconds: 60})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	resp := testResponse("cached")
+	c.Put(keyA, &resp)
+
+	now = now.Add(59 * time.Second)
+	got, ok := c.Get(keyA)
+	if !ok || got.ID != "resp-cached" {
+		t.Fatalf("expected a hit before the TTL, got %v %+v", ok, got)
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := c.Get(keyA); ok {
+		t.Error("expected a miss once the TTL has passed")
+	}
+	if _, ok, _ := store.Get(keyA); ok {
+		t.Error("expected the expired entry to be removed")
+	}
+
+	// A zero TTL never expires
+	forever := New(store, config.CacheConfig{})
+	forever.now = func() time.Time { return now }
+	forever.Put(keyB, &resp)
+	now = now.AddDate(10, 0, 0)
+	if _, ok := forever.Get(keyB); !ok {
+		t.Error("expected an entry without TTL to be kept")
+	}
+}
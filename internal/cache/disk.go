@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DiskStore keeps one JSON file per entry in a directory, so cached
+// responses survive restarts. Expired entries are removed when they are
+// read and when the store is opened.
+type DiskStore struct {
+	dir string
+}
+
+// OpenDiskStore opens or creates the cache directory at dir
+func OpenDiskStore(dir string) (*DiskStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("disk cache requires a path")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	d := &DiskStore{dir: dir}
+	d.sweep()
+	return d, nil
+}
+
+// Get returns the entry stored under key
+func (d *DiskStore) Get(key string) (Entry, bool, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Entry{}, false, fmt.Errorf("failed to parse cache entry %s: %w", key, err)
+	}
+	return e, true, nil
+}
+
+// Put stores an entry, replacing any existing one
+func (d *DiskStore) Put(key string, e Entry) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// Delete removes the entry stored under key, if any
+func (d *DiskStore) Delete(key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Close is a no-op for the disk store
+func (d *DiskStore) Close() error {
+	return nil
+}
+
+// path returns the file of key. Keys are hex digests; anything else is
+// rejected so a key can never name a file outside the directory.
+func (d *DiskStore) path(key string) (string, error) {
+	if key == "" || strings.Trim(key, "0123456789abcdef") != "" {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+	return filepath.Join(d.dir, key+".json"), nil
+}
+
+// sweep removes expired and unreadable entries
+func (d *DiskStore) sweep() {
+	files, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		key := strings.TrimSuffix(filepath.Base(file), ".json")
+		if _, err := d.path(key); err != nil {
+			continue // not ours
+		}
+		e, ok, err := d.Get(key)
+		if err != nil || (ok && expired(e, time.Now())) {
+			os.Remove(file)
+		}
+	}
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path,
+// so concurrent readers never observe a partial entry
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
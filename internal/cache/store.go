@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Storage types accepted in the cache config
+const (
+	StorageMemory = "memory"
+	StorageDisk   = "disk"
+)
+
+// Entry is a cached response and the time it stops being served. A zero
+// ExpiresAt never expires.
+type Entry struct {
+	Response  models.ChatCompletionResponse `json:"response"`
+	ExpiresAt time.Time                     `json:"expires_at"`
+}
+
+// Store persists cache entries by key. Implementations must be safe for
+// concurrent use and report unknown keys with ok == false.
+type Store interface {
+	Get(key string) (entry Entry, ok bool, err error)
+	Put(key string, e Entry) error
+	Delete(key string) error
+	Close() error
+}
+
+// OpenStore opens the cache store selected by the cache config
+func OpenStore(cfg config.CacheConfig) (Store, error) {
+	switch cfg.Type {
+	case "", StorageMemory:
+		return NewMemoryStore(cfg.MaxEntries), nil
+	case StorageDisk:
+		store, err := OpenDiskStore(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown cache type %q", cfg.Type)
+	}
+}
+
+// MemoryStore keeps up to a fixed number of entries in memory, evicting the
+// least recently used first; they are lost on restart
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *memoryEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key   string
+	entry Entry
+}
+
+// NewMemoryStore creates an empty store holding at most size entries, or
+// any number when size is 0
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored under key
+func (m *MemoryStore) Get(key string) (Entry, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	m.order.MoveToFront(e)
+	return clone(e.Value.(*memoryEntry).entry), true, nil
+}
+
+// Put stores an entry, evicting the least recently used ones beyond the size
+func (m *MemoryStore) Put(key string, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		e.Value.(*memoryEntry).entry = clone(entry)
+		m.order.MoveToFront(e)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, entry: clone(entry)})
+	for m.size > 0 && m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// Delete removes the entry stored under key, if any
+func (m *MemoryStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		m.order.Remove(e)
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// Close is a no-op for the memory store
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// clone returns a copy of e that shares no choices with it
+func clone(e Entry) Entry {
+	e.Response.Choices = append([]models.Choice(nil), e.Response.Choices...)
+	return e
+}
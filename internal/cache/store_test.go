@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+const (
+	keyA = "aaaa"
+	keyB = "bbbb"
+)
+
+func testResponse(content string) models.ChatCompletionResponse {
+	return models.ChatCompletionResponse{
+		ID:      "resp-" + content,
+		Model:   "test-model",
+		Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: content}, FinishReason: "stop"}},
+	}
+}
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	if _, ok, err := store.Get(keyA); ok || err != nil {
+		t.Errorf("expected a miss on an empty store, got ok=%v err=%v", ok, err)
+	}
+
+	expires := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.Put(keyA, Entry{Response: testResponse("first"), ExpiresAt: expires}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Put(keyA, Entry{Response: testResponse("second"), ExpiresAt: expires}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	e, ok, err := store.Get(keyA)
+	if err != nil || !ok {
+		t.Fatalf("Get failed: ok=%v err=%v", ok, err)
+	}
+	if len(e.Response.Choices) != 1 || e.Response.Choices[0].Message.Content != "second" {
+		t.Errorf("expected the replaced response, got %+v", e.Response)
+	}
+	if !e.ExpiresAt.Equal(expires) {
+		t.Errorf("expected expiry %v, got %v", expires, e.ExpiresAt)
+	}
+
+	// Entries are copies
+	e.Response.Choices[0].Message.Content = "changed"
+	if e, _, _ := store.Get(keyA); e.Response.Choices[0].Message.Content != "second" {
+		t.Error("expected the stored entry to be unaffected by changes to a returned one")
+	}
+
+	if err := store.Delete(keyA); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok, _ := store.Get(keyA); ok {
+		t.Error("expected a miss after Delete")
+	}
+	if err := store.Delete(keyB); err != nil {
+		t.Errorf("expected deleting a missing key to succeed, got %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore(10))
+}
+
+func TestMemoryStore_Evicts(t *testing.T) {
+	store := NewMemoryStore(2)
+	store.Put("a", Entry{Response: testResponse("a")})
+	store.Put("b", Entry{Response: testResponse("b")})
+	store.Get("a")
+	store.Put("c", Entry{Response: testResponse("c")})
+
+	if _, ok, _ := store.Get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := store.Get(key); !ok {
+			t.Errorf("expected %q to be kept", key)
+		}
+	}
+}
+
+func TestDiskStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenDiskStore(dir)
+	if err != nil {
+		t.Fatalf("OpenDiskStore failed: %v", err)
+	}
+	testStore(t, store)
+
+	if err := store.Put("../escape", Entry{}); err == nil {
+		t.Error("expected a key that is not a digest to be rejected")
+	}
+
+	// Entries survive a restart; expired ones are swept
+	store.Put(keyA, Entry{Response: testResponse("kept"), ExpiresAt: time.Now().Add(time.Hour)})
+	store.Put(keyB, Entry{Response: testResponse("stale"), ExpiresAt: time.Now().Add(-time.Hour)})
+	store.Close()
+
+	store, err = OpenDiskStore(dir)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+	if e, ok, _ := store.Get(keyA); !ok || e.Response.ID != "resp-kept" {
+		t.Errorf("expected %s after reopening, got %+v", keyA, e)
+	}
+	if _, err := os.Stat(filepath.Join(dir, keyB+".json")); !os.IsNotExist(err) {
+		t.Errorf("expected the expired entry to be swept, got %v", err)
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.CacheConfig
+		wantErr bool
+	}{
+		{name: "default", cfg: config.CacheConfig{}},
+		{name: "memory", cfg: config.CacheConfig{Type: "memory", MaxEntries: 10}},
+		{name: "disk", cfg: config.CacheConfig{Type: "disk", Path: t.TempDir()}},
+		{name: "disk without path", cfg: config.CacheConfig{Type: "disk"}, wantErr: true},
+		{name: "unknown", cfg: config.CacheConfig{Type: "redis"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := OpenStore(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if store != nil {
+				store.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package catalog
+
+import (
+	"context"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/window"
+)
+
+// refreshTimeout bounds a backend model listing
+const refreshTimeout = 30 * time.Second
+
+// Catalog lists the models the backends serve, merged with the capabilities,
+// costs and aliases declared in config. Backend lists are reused for the
+// configured TTL. Refreshes run outside the lock, one at a time, and callers
+// arriving during one are served the previous list.
+type Catalog struct {
+	lister   client.ModelLister
+	metadata []config.ModelConfig
+	aliases  map[string][]string // model -> aliases routed to it
+	limits   *window.Window      // context lengths not declared in metadata, nil when disabled
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	models    []models.Model
+	fetchedAt time.Time
+	fetching  *listFetch
+}
+
+// listFetch is a model list refresh in progress
+type listFetch struct {
+	done   chan struct{}
+	models []models.Model
+	err    error
+}
+
+// New creates a catalog of the models lister serves
+func New(lister client.ModelLister, cfg *config.Config) *Catalog {
+	c := &Catalog{
+		lister:   lister,
+		metadata: cfg.Models.Metadata,
+		aliases:  make(map[string][]string),
+		ttl:      time.Duration(cfg.Models.CacheTTLSeconds) * time.Second,
+		now:      time.Now,
+	}
+	if cfg.Context.Enabled {
+		c.limits = window.NewFromConfig(cfg.Context)
+	}
+
+	for alias, model := range cfg.Routing.Aliases {
+		c.aliases[model] = append(c.aliases[model], alias)
+	}
+	for _, aliases := range c.aliases {
+		sort.Strings(aliases)
+	}
+	return c
+}
+
+// List returns the models sorted by ID. When refreshing fails the last
+// list is served instead, if there is one.
+func (c *Catalog) List(ctx context.Context) ([]models.Model, error) {
+	c.mu.Lock()
+	if c.models != nil && (c.fetching != nil || c.now().Sub(c.fetchedAt) < c.ttl) {
+		defer c.mu.Unlock()
+		return c.models, nil
+	}
+	f := c.fetching
+	if f == nil {
+		f = &listFetch{done: make(chan struct{})}
+		c.fetching = f
+		go c.refresh(f)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.models, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// refresh fetches the model list and completes f. It is shared by every
+// caller waiting for it, so it runs on its own context rather than theirs.
+func (c *Catalog) refresh(f *listFetch) {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+
+	list, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	if err == nil {
+		c.models = list
+		c.fetchedAt = c.now()
+	} else if c.models != nil {
+		log.Printf("Failed to refresh model list, serving the previous one: %v", err)
+		list, err = c.models, nil
+	}
+	c.fetching = nil
+	c.mu.Unlock()
+
+	f.models, f.err = list, err
+	close(f.done)
+}
+
+// fetch lists the backend models with their declared metadata
+func (c *Catalog) fetch(ctx context.Context) ([]models.Model, error) {
+	list, err := c.lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Model, 0, len(list))
+	for _, m := range list {
+		result = append(result, c.describe(m))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// describe merges the declared metadata of m into it
+func (c *Catalog) describe(m models.Model) models.Model {
+	m.Object = "model"
+	if m.OwnedBy == "" {
+		m.OwnedBy = m.Backend
+	}
+	m.Capabilities = models.ModelCapabilities{Streaming: true}
+	m.Aliases = c.aliases[m.ID]
+
+	if meta, ok := c.lookup(m.ID); ok {
+		m.ContextLength = meta.ContextLength
+		m.Capabilities.Tools = meta.Tools
+		m.Capabilities.Vision = meta.Vision
+		if meta.Streaming != nil {
+			m.Capabilities.Streaming = *meta.Streaming
+		}
+		if meta.InputCost > 0 || meta.OutputCost > 0 {
+			m.Pricing = &models.ModelPricing{Input: meta.InputCost, Output: meta.OutputCost}
+		}
+	}
+	if m.ContextLength == 0 && c.limits != nil {
+		m.ContextLength = c.limits.Limit(m.ID)
+	}
+	return m
+}
+
+// lookup returns the metadata declared for model. Exact names take
+// precedence over patterns, which are tried in order.
+func (c *Catalog) lookup(model string) (config.ModelConfig, bool) {
+	for _, meta := range c.metadata {
+		if meta.Name == model {
+			return meta, true
+		}
+	}
+	for _, meta := range c.metadata {
+		if strings.ContainsAny(meta.Name, "*?[") {
+			if ok, _ := path.Match(meta.Name, model); ok {
+				return meta, true
+			}
+		}
+	}
+	return config.ModelConfig{}, false
+}
@@ -0,0 +1,207 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// fakeLister returns a fixed model list
+type fakeLister struct {
+	ids   []string
+	err   error
+	calls int
+}
+
+func (f *fakeLister) ListModels(ctx context.Context) ([]models.Model, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var list []models.Model
+	for _, id := range f.ids {
+		list = append(list, models.Model{ID: id, Backend: "test"})
+	}
+	return list, nil
+}
+
+func testConfig() *config.Config {
+	noStreaming := false
+	cfg := &config.Config{}
+	cfg.Models.CacheTTLSeconds = 60
+	cfg.Models.Metadata = []config.ModelConfig{
+		{Name: "gpt-4o*", ContextLength: 128000, Tools: true, Vision: true, InputCost: 0.0000025, OutputCost: 0.00001},
+		{Name: "gpt-4o-mini", ContextLength: 64000, Tools: true},
+		{Name: "o1", Streaming: &noStreaming},
+	}
+	cfg.Routing.Aliases = map[string]string{"smart": "gpt-4o", "default": "gpt-4o", "fast": "llama3.1"}
+	cfg.Context = config.ContextConfig{Enabled: true, DefaultTokens: 8192, Models: map[string]int{"llama3*": 131072}}
+	return cfg
+}
+
+func TestCatalog_List(t *testing.T) {
+	lister := &fakeLister{ids: []string{"o1", "gpt-4o-mini", "llama3.1", "gpt-4o"}}
+	c := New(lister, testConfig())
+
+	list, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	byID := make(map[string]models.Model)
+	var ids []string
+	for _, m := range list {
+		byID[m.ID] = m
+		ids = append(ids, m.ID)
+	}
+	if len(ids) != 4 || ids[0] != "gpt-4o" || ids[3] != "o1" {
+		t.Errorf("expected models sorted by ID, got %v", ids)
+	}
+
+	tests := []struct {
+		id            string
+		contextLength int
+		capabilities  models.ModelCapabilities
+		pricing       *models.ModelPricing
+		aliases       int
+	}{
+		{id: "gpt-4o", contextLength: 128000, capabilities: models.ModelCapabilities{Tools: true, Vision: true, Streaming: true}, pricing: &models.ModelPricing{Input: 0.0000025, Output: 0.00001}, aliases: 2},
+		{id: "gpt-4o-mini", contextLength: 64000, capabilities: models.ModelCapabilities{Tools: true, Streaming: true}},
+		{id: "llama3.1", contextLength: 131072, capabilities: models.ModelCapabilities{Streaming: true}, aliases: 1},
+		{id: "o1", contextLength: 8192},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			m := byID[tt.id]
+			if m.Object != "model" || m.Backend != "test" {
+				t.Errorf("expected object model on backend test, got %+v", m)
+			}
+			if m.ContextLength != tt.contextLength {
+				t.Errorf("expected context length %d, got %d", tt.contextLength, m.ContextLength)
+			}
+			if m.Capabilities != tt.capabilities {
+				t.Errorf("expected capabilities %+v, got %+v", tt.capabilities, m.Capabilities)
+			}
+			if (m.Pricing == nil) != (tt.pricing == nil) || (m.Pricing != nil && *m.Pricing != *tt.pricing) {
+				t.Errorf("expected pricing %+v, got %+v", tt.pricing, m.Pricing)
+			}
+			if len(m.Aliases) != tt.aliases {
+				t.Errorf("expected %d aliases, got %v", tt.aliases, m.Aliases)
+			}
+		})
+	}
+
+	if byID["gpt-4o"].Aliases[0] != "default" {
+		t.Errorf("expected aliases sorted, got %v", byID["gpt-4o"].Aliases)
+	}
+}
+
+func TestCatalog_Cache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lister := &fakeLister{ids: []string{"gpt-4o"}}
+	c := New(lister, testConfig())
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.List(ctx)
+	now = now.Add(59 * time.Second)
+	c.List(ctx)
+	if lister.calls != 1 {
+		t.Errorf("expected the list to be reused within the TTL, got %d calls", lister.calls)
+	}
+
+	now = now.Add(time.Second)
+	lister.ids = append(lister.ids, "gpt-4o-mini")
+	if list, _ := c.List(ctx); len(list) != 2 || lister.calls != 2 {
+		t.Errorf("expected a refresh after the TTL, got %d models and %d calls", len(list), lister.calls)
+	}
+
+	// A failed refresh serves the previous list
+	now = now.Add(time.Minute)
+	lister.err = errors.New("backend down")
+	if list, err := c.List(ctx); err != nil || len(list) != 2 {
+		t.Errorf("expected the previous list, got %v %v", list, err)
+	}
+}
+
+func TestCatalog_ListError(t *testing.T) {
+	c := New(&fakeLister{err: errors.New("backend down")}, testConfig())
+	if _, err := c.List(context.Background()); err == nil {
+		t.Error("expected an error without a previous list")
+	}
+}
+
+// slowLister blocks refreshes after the first until released
+type slowLister struct {
+	fakeLister
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowLister) ListModels(ctx context.Context) ([]models.Model, error) {
+	if s.calls > 0 {
+		close(s.started)
+		<-s.release
+	}
+	return s.fakeLister.ListModels(ctx)
+}
+
+func TestCatalog_RefreshServesPreviousList(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lister := &slowLister{fakeLister: fakeLister{ids: []string{"gpt-4o"}}, started: make(chan struct{}), release: make(chan struct{})}
+	c := New(lister, testConfig())
+	c.now = func() time.Time { return now }
+	c.List(context.Background())
+
+	now = now.Add(time.Minute)
+	done := make(chan []models.Model, 1)
+	go func() {
+		list, _ := c.List(context.Background())
+		done <- list
+	}()
+	<-lister.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if list, err := c.List(ctx); err != nil || len(list) != 1 {
+		t.Errorf("expected the previous list during a refresh, got %v %v", list, err)
+	}
+
+	close(lister.release)
+	if list := <-done; len(list) != 1 || lister.calls != 2 {
+		t.Errorf("expected a single refresh, got %v and %d calls", list, lister.calls)
+	}
+}
+
+func TestCatalog_RefreshOutlivesCaller(t *testing.T) {
+	// Starting at one call makes the very first listing block
+	lister := &slowLister{fakeLister: fakeLister{ids: []string{"gpt-4o"}, calls: 1}, started: make(chan struct{}), release: make(chan struct{})}
+	c := New(lister, testConfig())
+
+	// The caller that starts the listing gives up on it
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.List(ctx)
+		done <- err
+	}()
+	<-lister.started
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancelled caller to give up, got %v", err)
+	}
+
+	// Others still get the list from the same fetch
+	close(lister.release)
+	if list, err := c.List(context.Background()); err != nil || len(list) != 1 {
+		t.Errorf("expected the listing to complete, got %v %v", list, err)
+	}
+	if lister.calls != 2 {
+		t.Errorf("expected a single listing, got %d", lister.calls-1)
+	}
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Embedder embeds text. Every Provider is an Embedder; components that only
+// need embeddings depend on this narrower interface.
+type Embedder interface {
+	Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error)
+}
+
+// ollamaEmbedRequest is the request body of POST /api/embed
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaEmbedResponse is the response body of POST /api/embed
+type ollamaEmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float64 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
+
+// Embed sends an embeddings request to OpenWebUI
+func (c *OpenWebUIClient) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	// Vectors are always requested as floats
+	upstreamReq := *req
+	upstreamReq.EncodingFormat = ""
+
+	var embedResp models.EmbeddingResponse
+	if err := postJSON(ctx, c.httpClient, c.name, c.baseURL+c.embedPath, c.apiKey, &upstreamReq, &embedResp); err != nil {
+		return nil, err
+	}
+	return &embedResp, nil
+}
+
+// Embed sends an embeddings request to Ollama's native API
+func (c *OllamaClient) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	var ollamaResp ollamaEmbedResponse
+	ollamaReq := ollamaEmbedRequest{Model: req.Model, Input: req.Input}
+	if err := postJSON(ctx, c.httpClient, "Ollama", c.baseURL+"/api/embed", c.apiKey, ollamaReq, &ollamaResp); err != nil {
+		return nil, err
+	}
+
+	embedResp := &models.EmbeddingResponse{
+		Object: "list",
+		Model:  ollamaResp.Model,
+		Usage: models.Usage{
+			PromptTokens: ollamaResp.PromptEvalCount,
+			TotalTokens:  ollamaResp.PromptEvalCount,
+		},
+	}
+	for i, vector := range ollamaResp.Embeddings {
+		embedResp.Data = append(embedResp.Data, models.Embedding{Object: "embedding", Index: i, Embedding: vector})
+	}
+	return embedResp, nil
+}
+
+// postJSON posts body as JSON to url and decodes a 200 response into out
+func postJSON(ctx context.Context, httpClient *http.Client, provider, url, apiKey string, body, out interface{}) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	return doJSON(httpClient, provider, httpReq, apiKey, out)
+}
+
+// doJSON sends httpReq and decodes a 200 response into out
+func doJSON(httpClient *http.Client, provider string, httpReq *http.Request, apiKey string, out interface{}) error {
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newStatusError(provider, resp, respBody)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
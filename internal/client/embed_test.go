@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestOpenWebUIClient_Embed(t *testing.T) {
+	tests := []struct {
+		name     string
+		newFunc  func(baseURL string) Embedder
+		expected string
+	}{
+		{name: "openwebui", newFunc: func(u string) Embedder { return NewOpenWebUIClient(u, "test-key", 30*time.Second) }, expected: "/api/embeddings"},
+		{name: "openai", newFunc: func(u string) Embedder { return NewOpenAIClient(u, "test-key", 30*time.Second) }, expected: "/v1/embeddings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.expected {
+					t.Errorf("expected path %s, got %s", tt.expected, r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Bearer test-key" {
+					t.Errorf("expected bearer auth, got %q", r.Header.Get("Authorization"))
+				}
+
+				var req models.EmbeddingRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				if req.Model != "nomic-embed-text" || len(req.Input) != 2 {
+					t.Errorf("unexpected request %+v", req)
+				}
+
+				fmt.Fprint(w, `{"object":"list","model":"nomic-embed-text","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]},{"object":"embedding","index":1,"embedding":[0.3,0.4]}],"usage":{"prompt_tokens":4,"total_tokens":4}}`)
+			}))
+			defer server.Close()
+
+			resp, err := tt.newFunc(server.URL).Embed(context.Background(), &models.EmbeddingRequest{
+				Model: "nomic-embed-text",
+				Input: []string{"hello", "world"},
+			})
+			if err != nil {
+				t.Fatalf("Embed failed: %v", err)
+			}
+			if len(resp.Data) != 2 || resp.Data[1].Embedding[1] != 0.4 || resp.Usage.PromptTokens != 4 {
+				t.Errorf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
+
+func TestOllamaClient_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("expected path /api/embed, got %s", r.URL.Path)
+		}
+
+		var req ollamaEmbedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" || len(req.Input) != 2 {
+			t.Errorf("unexpected request %+v", req)
+		}
+
+		fmt.Fprint(w, `{"model":"nomic-embed-text","embeddings":[[0.1,0.2],[0.3,0.4]],"prompt_eval_count":4}`)
+	}))
+	defer server.Close()
+
+	resp, err := NewOllamaClient(server.URL, "", 30*time.Second).Embed(context.Background(), &models.EmbeddingRequest{
+		Model: "nomic-embed-text",
+		Input: []string{"hello", "world"},
+	})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if resp.Object != "list" || resp.Model != "nomic-embed-text" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if len(resp.Data) != 2 || resp.Data[1].Index != 1 || resp.Data[1].Embedding[0] != 0.3 {
+		t.Errorf("expected embeddings in input order, got %+v", resp.Data)
+	}
+	if resp.Usage.PromptTokens != 4 || resp.Usage.TotalTokens != 4 {
+		t.Errorf("expected usage from prompt_eval_count, got %+v", resp.Usage)
+	}
+}
+
+func TestEmbed_StatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := NewOllamaClient(server.URL, "", 30*time.Second).Embed(context.Background(), &models.EmbeddingRequest{Model: "missing"})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 StatusError, got %v", err)
+	}
+}
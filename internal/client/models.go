@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// ModelLister lists the models a backend serves. Every Provider is a
+// ModelLister.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]models.Model, error)
+}
+
+// modelList is the response body of GET /api/models on OpenWebUI and
+// GET /v1/models on OpenAI-compatible servers
+type modelList struct {
+	Data []models.Model `json:"data"`
+}
+
+// ollamaTagsResponse is the response body of GET /api/tags
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name       string    `json:"name"`
+		ModifiedAt time.Time `json:"modified_at"`
+	} `json:"models"`
+}
+
+// ListModels lists the models OpenWebUI serves
+func (c *OpenWebUIClient) ListModels(ctx context.Context) ([]models.Model, error) {
+	var list modelList
+	if err := getJSON(ctx, c.httpClient, c.name, c.baseURL+c.modelsPath, c.apiKey, &list); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Model, 0, len(list.Data))
+	for _, m := range list.Data {
+		if m.ID == "" {
+			continue
+		}
+		result = append(result, models.Model{ID: m.ID, Object: "model", Created: m.Created, OwnedBy: m.OwnedBy})
+	}
+	return result, nil
+}
+
+// ListModels lists the models pulled into Ollama
+func (c *OllamaClient) ListModels(ctx context.Context) ([]models.Model, error) {
+	var tags ollamaTagsResponse
+	if err := getJSON(ctx, c.httpClient, "Ollama", c.baseURL+"/api/tags", c.apiKey, &tags); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Model, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		model := models.Model{ID: m.Name, Object: "model", OwnedBy: "ollama"}
+		if !m.ModifiedAt.IsZero() {
+			model.Created = m.ModifiedAt.Unix()
+		}
+		result = append(result, model)
+	}
+	return result, nil
+}
+
+// getJSON fetches url and decodes a 200 response into out
+func getJSON(ctx context.Context, httpClient *http.Client, provider, url, apiKey string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	return doJSON(httpClient, provider, httpReq, apiKey, out)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenWebUIClient_ListModels(t *testing.T) {
+	tests := []struct {
+		name     string
+		newFunc  func(baseURL string) ModelLister
+		expected string
+	}{
+		{name: "openwebui", newFunc: func(u string) ModelLister { return NewOpenWebUIClient(u, "test-key", 30*time.Second) }, expected: "/api/models"},
+		{name: "openai", newFunc: func(u string) ModelLister { return NewOpenAIClient(u, "test-key", 30*time.Second) }, expected: "/v1/models"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "GET" || r.URL.Path != tt.expected {
+					t.Errorf("expected GET %s, got %s %s", tt.expected, r.Method, r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Bearer test-key" {
+					t.Errorf("expected bearer auth, got %q", r.Header.Get("Authorization"))
+				}
+				fmt.Fprint(w, `{"object":"list","data":[{"id":"gpt-4o","object":"model","created":1715367049,"owned_by":"openai","name":"GPT-4o"},{"name":"no id"}]}`)
+			}))
+			defer server.Close()
+
+			list, err := tt.newFunc(server.URL).ListModels(context.Background())
+			if err != nil {
+				t.Fatalf("ListModels failed: %v", err)
+			}
+			if len(list) != 1 {
+				t.Fatalf("expected 1 model, got %+v", list)
+			}
+			if m := list[0]; m.ID != "gpt-4o" || m.Object != "model" || m.Created != 1715367049 || m.OwnedBy != "openai" {
+				t.Errorf("unexpected model %+v", m)
+			}
+		})
+	}
+}
+
+func TestOllamaClient_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("expected path /api/tags, got %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"models":[{"name":"llama3.1:8b","modified_at":"2024-05-01T10:00:00Z"},{"name":"nomic-embed-text:latest"}]}`)
+	}))
+	defer server.Close()
+
+	list, err := NewOllamaClient(server.URL, "", 30*time.Second).ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "llama3.1:8b" || list[0].OwnedBy != "ollama" {
+		t.Fatalf("unexpected models %+v", list)
+	}
+	if list[0].Created != time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC).Unix() || list[1].Created != 0 {
+		t.Errorf("expected the modification time as creation time, got %d and %d", list[0].Created, list[1].Created)
+	}
+}
+
+func TestListModels_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid key"}`)
+	}))
+	defer server.Close()
+
+	_, err := NewOpenWebUIClient(server.URL, "", 30*time.Second).ListModels(context.Background())
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 status error, got %v", err)
+	}
+}
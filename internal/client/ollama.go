@@ -0,0 +1,361 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// OllamaClient handles communication with Ollama's native chat API
+type OllamaClient struct {
+	baseURL      string
+	apiKey       string
+	httpClient   *http.Client
+	streamClient *http.Client
+}
+
+// ollamaChatRequest is the request body of POST /api/chat
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []models.Tool   `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+	Options  *ollamaOptions  `json:"options,omitempty"`
+}
+
+// ollamaMessage is a chat message in Ollama's format, which passes tool
+// call arguments as objects and names the tool a result belongs to instead
+// of its call ID
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+// ollamaToolCall is a tool call in Ollama's format
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// ollamaOptions holds the sampling parameters Ollama accepts
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+}
+
+// ollamaChatResponse is a full response or a single streamed line of
+// POST /api/chat
+type ollamaChatResponse struct {
+	Model           string             `json:"model"`
+	CreatedAt       time.Time          `json:"created_at"`
+	Message         models.ChatMessage `json:"message"`
+	Done            bool               `json:"done"`
+	DoneReason      string             `json:"done_reason"`
+	PromptEvalCount int                `json:"prompt_eval_count"`
+	EvalCount       int                `json:"eval_count"`
+	Error           string             `json:"error"`
+}
+
+// NewOllamaClient creates a new Ollama client
+func NewOllamaClient(baseURL, apiKey string, timeout time.Duration) *OllamaClient {
+	return &OllamaClient{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		streamClient: &http.Client{},
+	}
+}
+
+// ChatCompletion sends a chat completion request to Ollama
+func (c *OllamaClient) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	resp, err := c.send(ctx, c.httpClient, c.prepareOllamaRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var ollamaResp ollamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	id := ollamaResponseID()
+	message := ollamaResp.Message
+	message.ToolCalls = ollamaToolCalls(id, message.ToolCalls, 0, false)
+	finishReason := ollamaFinishReason(ollamaResp.DoneReason)
+	if len(message.ToolCalls) > 0 {
+		finishReason = "tool_calls"
+	}
+
+	return &models.ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: ollamaCreated(ollamaResp.CreatedAt),
+		Model:   ollamaResp.Model,
+		Choices: []models.Choice{
+			{
+				Index:        0,
+				Message:      message,
+				FinishReason: finishReason,
+			},
+		},
+		Usage: ollamaUsage(&ollamaResp),
+	}, nil
+}
+
+// ChatCompletionStream sends a streaming chat completion request to Ollama.
+// Ollama streams newline-delimited JSON objects which are translated into
+// OpenAI-style chunks.
+func (c *OllamaClient) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	resp, err := c.send(ctx, c.streamClient, c.prepareOllamaRequest(req, true))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	id := ollamaResponseID()
+	first := true
+	toolCalls := 0
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var ollamaResp ollamaChatResponse
+		if err := json.Unmarshal(line, &ollamaResp); err != nil {
+			return fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		if ollamaResp.Error != "" {
+			return fmt.Errorf("Ollama stream error: %s", ollamaResp.Error)
+		}
+
+		chunk := &models.ChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: ollamaCreated(ollamaResp.CreatedAt),
+			Model:   ollamaResp.Model,
+			Choices: []models.ChunkChoice{
+				{
+					Index: 0,
+					Delta: models.ChatDelta{
+						Content:   ollamaResp.Message.Content,
+						ToolCalls: ollamaToolCalls(id, ollamaResp.Message.ToolCalls, toolCalls, true),
+					},
+				},
+			},
+		}
+		toolCalls += len(ollamaResp.Message.ToolCalls)
+		if first {
+			chunk.Choices[0].Delta.Role = "assistant"
+			first = false
+		}
+		if ollamaResp.Done {
+			chunk.Choices[0].FinishReason = ollamaFinishReason(ollamaResp.DoneReason)
+			if toolCalls > 0 {
+				chunk.Choices[0].FinishReason = "tool_calls"
+			}
+			usage := ollamaUsage(&ollamaResp)
+			chunk.Usage = &usage
+		}
+
+		if err := onChunk(chunk); err != nil {
+			return err
+		}
+
+		if ollamaResp.Done {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	return nil
+}
+
+// HealthCheck performs a health check against Ollama
+func (c *OllamaClient) HealthCheck(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request: %w", err)
+	}
+
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// send posts an Ollama chat request and returns the response once the
+// status has been checked. The caller must close the body.
+func (c *OllamaClient) send(ctx context.Context, httpClient *http.Client, ollamaReq *ollamaChatRequest) (*http.Response, error) {
+	reqBody, err := json.Marshal(ollamaReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, newStatusError("Ollama", resp, respBody)
+	}
+
+	return resp, nil
+}
+
+// prepareOllamaRequest converts our request format to Ollama format
+func (c *OllamaClient) prepareOllamaRequest(req *models.ChatCompletionRequest, stream bool) *ollamaChatRequest {
+	upstreamReq := applyPersonaPrompt(req)
+
+	ollamaReq := &ollamaChatRequest{
+		Model:    upstreamReq.Model,
+		Messages: ollamaMessages(upstreamReq.Messages),
+		Stream:   stream,
+	}
+
+	// Ollama cannot be told to call a tool, so tool_choice only matters
+	// when it rules tools out
+	if upstreamReq.ToolChoice == nil || upstreamReq.ToolChoice.Mode != models.ToolChoiceNone {
+		ollamaReq.Tools = upstreamReq.Tools
+	}
+
+	if upstreamReq.Temperature != nil || upstreamReq.MaxTokens != nil {
+		ollamaReq.Options = &ollamaOptions{
+			Temperature: upstreamReq.Temperature,
+			NumPredict:  upstreamReq.MaxTokens,
+		}
+	}
+
+	return ollamaReq
+}
+
+// ollamaMessages converts chat messages to Ollama's format. Tool results
+// are labelled with the name of the function whose call they answer.
+func ollamaMessages(messages []models.ChatMessage) []ollamaMessage {
+	names := make(map[string]string) // Tool call ID -> function name
+	result := make([]ollamaMessage, 0, len(messages))
+	for _, msg := range messages {
+		converted := ollamaMessage{Role: msg.Role, Content: msg.Content}
+		for _, call := range msg.ToolCalls {
+			names[call.ID] = call.Function.Name
+
+			var ollamaCall ollamaToolCall
+			ollamaCall.Function.Name = call.Function.Name
+			ollamaCall.Function.Arguments = json.RawMessage("{}")
+			if args := strings.TrimSpace(call.Function.Arguments); strings.HasPrefix(args, "{") && json.Valid([]byte(args)) {
+				ollamaCall.Function.Arguments = json.RawMessage(args)
+			}
+			converted.ToolCalls = append(converted.ToolCalls, ollamaCall)
+		}
+		if msg.Role == "tool" {
+			converted.ToolName = names[msg.ToolCallID]
+		}
+		result = append(result, converted)
+	}
+	return result
+}
+
+// ollamaToolCalls fills in the IDs and types Ollama leaves out of tool
+// calls. Calls are numbered from first across the response; streamed calls
+// also carry their index, as OpenAI deltas do.
+func ollamaToolCalls(responseID string, calls []models.ToolCall, first int, stream bool) []models.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	result := make([]models.ToolCall, len(calls))
+	for i, call := range calls {
+		index := first + i
+		call.ID = fmt.Sprintf("call_%s_%d", strings.TrimPrefix(responseID, "chatcmpl-"), index)
+		call.Type = "function"
+		call.Index = nil
+		if stream {
+			call.Index = &index
+		}
+		if call.Function.Arguments == "" {
+			call.Function.Arguments = "{}"
+		}
+		result[i] = call
+	}
+	return result
+}
+
+// ollamaResponseID generates a response ID, which Ollama does not provide
+func ollamaResponseID() string {
+	return fmt.Sprintf("chatcmpl-ollama-%d", time.Now().UnixNano())
+}
+
+// ollamaCreated converts Ollama's timestamp to a Unix creation time
+func ollamaCreated(createdAt time.Time) int64 {
+	if createdAt.IsZero() {
+		return time.Now().Unix()
+	}
+	return createdAt.Unix()
+}
+
+// ollamaFinishReason maps Ollama's done_reason to an OpenAI finish reason
+func ollamaFinishReason(doneReason string) string {
+	switch doneReason {
+	case "", "stop":
+		return "stop"
+	case "length":
+		return "length"
+	default:
+		return doneReason
+	}
+}
+
+// ollamaUsage builds usage statistics from Ollama's eval counters
+func ollamaUsage(resp *ollamaChatResponse) models.Usage {
+	return models.Usage{
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+		TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+	}
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestOllamaClient_ChatCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("expected path /api/chat, got %s", r.URL.Path)
+		}
+
+		var req ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		if req.Stream {
+			t.Errorf("expected non-streaming request")
+		}
+
+		if req.Options == nil || req.Options.Temperature == nil || *req.Options.Temperature != 0.2 {
+			t.Errorf("expected temperature to be passed as an option")
+		}
+
+		if len(req.Messages) != 2 || req.Messages[0].Role != "system" {
+			t.Errorf("expected persona prompt as system message, got %+v", req.Messages)
+		}
+
+		fmt.Fprint(w, `{"model":"llama3","created_at":"2024-06-01T12:00:00Z","message":{"role":"assistant","content":"Hi!"},"done":true,"done_reason":"stop","prompt_eval_count":12,"eval_count":3}`)
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "", 30*time.Second)
+
+	temp := 0.2
+	req := &models.ChatCompletionRequest{
+		Model: "llama3",
+		Messages: []models.ChatMessage{
+			{Role: "user", Content: "Hello"},
+		},
+		Temperature:   &temp,
+		PersonaPrompt: "You are terse",
+	}
+
+	resp, err := client.ChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content != "Hi!" {
+		t.Fatalf("unexpected choices: %+v", resp.Choices)
+	}
+
+	if resp.Choices[0].FinishReason != "stop" {
+		t.Errorf("expected finish reason stop, got %s", resp.Choices[0].FinishReason)
+	}
+
+	if resp.Usage.TotalTokens != 15 {
+		t.Errorf("expected total tokens 15, got %d", resp.Usage.TotalTokens)
+	}
+
+	if resp.Created != 1717243200 {
+		t.Errorf("expected created 1717243200, got %d", resp.Created)
+	}
+}
+
+func TestOllamaClient_ChatCompletionStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"model":"llama3","message":{"role":"assistant","content":"Hel"},"done":false}`)
+		fmt.Fprintln(w, `{"model":"llama3","message":{"role":"assistant","content":"lo"},"done":false}`)
+		fmt.Fprintln(w, `{"model":"llama3","message":{"role":"assistant","content":""},"done":true,"done_reason":"length","prompt_eval_count":4,"eval_count":2}`)
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "", 30*time.Second)
+
+	req := &models.ChatCompletionRequest{
+		Model: "llama3",
+		Messages: []models.ChatMessage{
+			{Role: "user", Content: "Hello"},
+		},
+	}
+
+	var chunks []*models.ChatCompletionChunk
+	err := client.ChatCompletionStream(context.Background(), req, func(chunk *models.ChatCompletionChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+
+	if chunks[0].Choices[0].Delta.Role != "assistant" || chunks[1].Choices[0].Delta.Role != "" {
+		t.Errorf("expected role only on the first delta")
+	}
+
+	last := chunks[2]
+	if last.Choices[0].FinishReason != "length" {
+		t.Errorf("expected finish reason length, got %s", last.Choices[0].FinishReason)
+	}
+
+	if last.Usage == nil || last.Usage.TotalTokens != 6 {
+		t.Errorf("expected usage on final chunk, got %v", last.Usage)
+	}
+}
+
+func TestOllamaClient_HealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("expected path /api/tags, got %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "", 30*time.Second)
+
+	if err := client.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck failed: %v", err)
+	}
+}
+
+func TestOllamaClient_ToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		if len(req.Tools) != 1 || req.Tools[0].Function.Name != "get_weather" {
+			t.Errorf("expected the tools to be forwarded, got %+v", req.Tools)
+		}
+		if len(req.Messages) != 3 {
+			t.Fatalf("expected 3 messages, got %+v", req.Messages)
+		}
+		call := req.Messages[1].ToolCalls
+		if len(call) != 1 || call[0].Function.Name != "get_weather" || string(call[0].Function.Arguments) != `{"city":"Paris"}` {
+			t.Errorf("expected arguments as an object, got %+v", call)
+		}
+		if req.Messages[2].ToolName != "get_weather" || req.Messages[2].Content != "18C" {
+			t.Errorf("expected the tool result to name its function, got %+v", req.Messages[2])
+		}
+
+		fmt.Fprint(w, `{"model":"llama3.1","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Lyon"}}}]},"done":true,"done_reason":"stop"}`)
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "", 30*time.Second)
+
+	req := &models.ChatCompletionRequest{
+		Model: "llama3.1",
+		Messages: []models.ChatMessage{
+			{Role: "user", Content: "Weather in Paris and Lyon?"},
+			{Role: "assistant", ToolCalls: []models.ToolCall{
+				{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: "tool", ToolCallID: "call_1", Content: "18C"},
+		},
+		Tools: []models.Tool{{Type: "function", Function: models.ToolFunction{Name: "get_weather"}}},
+	}
+
+	resp, err := client.ChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	if resp.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("expected finish reason tool_calls, got %s", resp.Choices[0].FinishReason)
+	}
+	calls := resp.Choices[0].Message.ToolCalls
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 tool call, got %+v", calls)
+	}
+	if calls[0].ID == "" || calls[0].Type != "function" || calls[0].Index != nil {
+		t.Errorf("expected an OpenAI-style call, got %+v", calls[0])
+	}
+	if calls[0].Function.Name != "get_weather" || calls[0].Function.Arguments != `{"city":"Lyon"}` {
+		t.Errorf("expected JSON-encoded arguments, got %+v", calls[0].Function)
+	}
+}
+
+func TestOllamaClient_ToolCallsStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if len(req.Tools) != 0 {
+			t.Errorf(`expected no tools with tool_choice "none", got %+v`, req.Tools)
+		}
+
+		fmt.Fprintln(w, `{"model":"llama3.1","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"a","arguments":{}}}]},"done":false}`)
+		fmt.Fprintln(w, `{"model":"llama3.1","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"b","arguments":{"x":1}}}]},"done":false}`)
+		fmt.Fprintln(w, `{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}`)
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "", 30*time.Second)
+
+	req := &models.ChatCompletionRequest{
+		Model:      "llama3.1",
+		Messages:   []models.ChatMessage{{Role: "user", Content: "Go"}},
+		Tools:      []models.Tool{{Type: "function", Function: models.ToolFunction{Name: "a"}}},
+		ToolChoice: &models.ToolChoice{Mode: models.ToolChoiceNone},
+	}
+
+	var chunks []*models.ChatCompletionChunk
+	err := client.ChatCompletionStream(context.Background(), req, func(chunk *models.ChatCompletionChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	for i, name := range []string{"a", "b"} {
+		calls := chunks[i].Choices[0].Delta.ToolCalls
+		if len(calls) != 1 || calls[0].Index == nil || *calls[0].Index != i || calls[0].Function.Name != name {
+			t.Errorf("chunk %d: expected call %d to %s, got %+v", i, i, name, calls)
+		}
+	}
+	if chunks[0].Choices[0].Delta.ToolCalls[0].ID == chunks[1].Choices[0].Delta.ToolCalls[0].ID {
+		t.Error("expected distinct call IDs")
+	}
+	if reason := chunks[2].Choices[0].FinishReason; reason != "tool_calls" {
+		t.Errorf("expected finish reason tool_calls, got %s", reason)
+	}
+}
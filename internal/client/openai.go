@@ -0,0 +1,24 @@
+package client
+
+import (
+	"time"
+)
+
+// OpenAIClient handles communication with plain OpenAI-compatible APIs such
+// as vLLM, llama.cpp server and LM Studio. These speak the same wire format
+// as OpenWebUI but serve it under the /v1 prefix.
+type OpenAIClient struct {
+	*OpenWebUIClient
+}
+
+// NewOpenAIClient creates a new OpenAI-compatible client. baseURL must not
+// include the /v1 suffix.
+func NewOpenAIClient(baseURL, apiKey string, timeout time.Duration) *OpenAIClient {
+	c := NewOpenWebUIClient(baseURL, apiKey, timeout)
+	c.name = "OpenAI-compatible"
+	c.chatPath = "/v1/chat/completions"
+	c.modelsPath = "/v1/models"
+	c.embedPath = "/v1/embeddings"
+
+	return &OpenAIClient{OpenWebUIClient: c}
+}
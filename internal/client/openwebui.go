@@ -1,12 +1,14 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
@@ -14,19 +16,31 @@ import (
 
 // OpenWebUIClient handles communication with OpenWebUI API
 type OpenWebUIClient struct {
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	name         string
+	baseURL      string
+	chatPath     string
+	modelsPath   string
+	embedPath    string
+	apiKey       string
+	httpClient   *http.Client
+	streamClient *http.Client
 }
 
 // NewOpenWebUIClient creates a new OpenWebUI client
 func NewOpenWebUIClient(baseURL, apiKey string, timeout time.Duration) *OpenWebUIClient {
 	return &OpenWebUIClient{
-		baseURL: baseURL,
-		apiKey:  apiKey,
+		name:       "OpenWebUI",
+		baseURL:    baseURL,
+		chatPath:   "/api/chat/completions",
+		modelsPath: "/api/models",
+		embedPath:  "/api/embeddings",
+		apiKey:     apiKey,
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		// Streams can legitimately outlive the request timeout, so they are
+		// bounded by the caller's context instead
+		streamClient: &http.Client{},
 	}
 }
 
@@ -35,6 +49,9 @@ func (c *OpenWebUIClient) ChatCompletion(ctx context.Context, req *models.ChatCo
 	// Prepare the request for OpenWebUI
 	openWebUIReq := c.prepareOpenWebUIRequest(req)
 
+	// The unary call always expects a single JSON body
+	openWebUIReq.Stream = nil
+
 	// Marshal the request
 	reqBody, err := json.Marshal(openWebUIReq)
 	if err != nil {
@@ -42,7 +59,7 @@ func (c *OpenWebUIClient) ChatCompletion(ctx context.Context, req *models.ChatCo
 	}
 
 	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat/completions", bytes.NewBuffer(reqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+c.chatPath, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
@@ -68,7 +85,7 @@ func (c *OpenWebUIClient) ChatCompletion(ctx context.Context, req *models.ChatCo
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenWebUI API returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newStatusError(c.name, resp, respBody)
 	}
 
 	// Parse response
@@ -80,44 +97,98 @@ func (c *OpenWebUIClient) ChatCompletion(ctx context.Context, req *models.ChatCo
 	return &chatResp, nil
 }
 
-// prepareOpenWebUIRequest converts our request format to OpenWebUI format
-func (c *OpenWebUIClient) prepareOpenWebUIRequest(req *models.ChatCompletionRequest) *models.ChatCompletionRequest {
-	// Create a copy of the request
-	openWebUIReq := *req
-
-	// If persona prompt is provided, prepend it as a system message
-	if req.PersonaPrompt != "" {
-		systemMessage := models.ChatMessage{
-			Role:    "system",
-			Content: req.PersonaPrompt,
+// ChatCompletionStream sends a streaming chat completion request to OpenWebUI
+// and invokes onChunk for every delta as it arrives. It returns when the
+// upstream sends [DONE], closes the stream, or ctx is cancelled.
+func (c *OpenWebUIClient) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	// Prepare the request for OpenWebUI
+	openWebUIReq := c.prepareOpenWebUIRequest(req)
+	stream := true
+	openWebUIReq.Stream = &stream
+	// Always ask for usage so streams can be accounted
+	openWebUIReq.StreamOptions = &models.StreamOptions{IncludeUsage: true}
+
+	// Marshal the request
+	reqBody, err := json.Marshal(openWebUIReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+c.chatPath, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	// Set headers
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	// Send request
+	resp, err := c.streamClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return newStatusError(c.name, resp, respBody)
+	}
+
+	return readSSE(resp.Body, onChunk)
+}
+
+// readSSE parses an OpenAI-style server-sent event stream of chat completion
+// chunks, stopping at the [DONE] sentinel or end of stream
+func readSSE(body io.Reader, onChunk func(*models.ChatCompletionChunk) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			// Blank separators, comments and other SSE fields are ignored
+			continue
 		}
 
-		// Check if there's already a system message
-		hasSystemMessage := false
-		for i, msg := range openWebUIReq.Messages {
-			if msg.Role == "system" {
-				// Prepend persona prompt to existing system message
-				openWebUIReq.Messages[i].Content = req.PersonaPrompt + "\n\n" + msg.Content
-				hasSystemMessage = true
-				break
-			}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+		if data == "[DONE]" {
+			return nil
 		}
 
-		// If no system message exists, add one at the beginning
-		if !hasSystemMessage {
-			openWebUIReq.Messages = append([]models.ChatMessage{systemMessage}, openWebUIReq.Messages...)
+		var chunk models.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to unmarshal stream chunk: %w", err)
 		}
+
+		if err := onChunk(&chunk); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
 	}
 
-	// Clear persona prompt as it's not part of OpenWebUI API
-	openWebUIReq.PersonaPrompt = ""
+	return nil
+}
 
-	return &openWebUIReq
+// prepareOpenWebUIRequest converts our request format to OpenWebUI format
+func (c *OpenWebUIClient) prepareOpenWebUIRequest(req *models.ChatCompletionRequest) *models.ChatCompletionRequest {
+	return applyPersonaPrompt(req)
 }
 
 // HealthCheck performs a health check against OpenWebUI
 func (c *OpenWebUIClient) HealthCheck(ctx context.Context) error {
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/models", nil)
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+c.modelsPath, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create health check request: %w", err)
 	}
@@ -3,6 +3,8 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -83,6 +85,98 @@ func TestOpenWebUIClient_ChatCompletion(t *testing.T) {
 	}
 }
 
+func TestOpenWebUIClient_ChatCompletionStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		if req.Stream == nil || !*req.Stream {
+			t.Errorf("expected stream to be requested upstream")
+		}
+		if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("expected usage to be requested upstream")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"test-id\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "data: {\"id\":\"test-id\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenWebUIClient(server.URL, "", 30*time.Second)
+
+	req := &models.ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []models.ChatMessage{
+			{Role: "user", Content: "Hello"},
+		},
+	}
+
+	var content string
+	var finishReason string
+	err := client.ChatCompletionStream(context.Background(), req, func(chunk *models.ChatCompletionChunk) error {
+		for _, choice := range chunk.Choices {
+			content += choice.Delta.Content
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if content != "Hello" {
+		t.Errorf("expected content Hello, got %s", content)
+	}
+
+	if finishReason != "stop" {
+		t.Errorf("expected finish reason stop, got %s", finishReason)
+	}
+}
+
+func TestOpenWebUIClient_ChatCompletionStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, "slow down")
+	}))
+	defer server.Close()
+
+	client := NewOpenWebUIClient(server.URL, "", 30*time.Second)
+
+	req := &models.ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []models.ChatMessage{
+			{Role: "user", Content: "Hello"},
+		},
+	}
+
+	_, err := client.ChatCompletion(context.Background(), req)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected StatusError, got %v", err)
+	}
+
+	if statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status 429, got %d", statusErr.StatusCode)
+	}
+
+	if statusErr.RetryAfter != 2*time.Second {
+		t.Errorf("expected Retry-After 2s, got %v", statusErr.RetryAfter)
+	}
+
+	if statusErr.Body != "slow down" {
+		t.Errorf("expected body to be kept, got %s", statusErr.Body)
+	}
+}
+
 func TestOpenWebUIClient_HealthCheck(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -193,3 +287,90 @@ func TestOpenWebUIClient_PrepareOpenWebUIRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenWebUIClient_ToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		choice, _ := body["tool_choice"].(map[string]interface{})
+		if choice["type"] != "function" {
+			t.Errorf("expected tool_choice as a function object, got %v", body["tool_choice"])
+		}
+		if tools, _ := body["tools"].([]interface{}); len(tools) != 1 {
+			t.Errorf("expected the tools to be forwarded, got %v", body["tools"])
+		}
+		messages, _ := body["messages"].([]interface{})
+		if len(messages) != 3 {
+			t.Fatalf("expected 3 messages, got %v", body["messages"])
+		}
+		if result := messages[2].(map[string]interface{}); result["tool_call_id"] != "call_1" {
+			t.Errorf("expected the tool result to keep its call ID, got %v", result)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"test-id","choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_2","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Lyon\"}"}}]},"finish_reason":"tool_calls"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewOpenWebUIClient(server.URL, "", 30*time.Second)
+
+	req := &models.ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []models.ChatMessage{
+			{Role: "user", Content: "Weather in Paris?"},
+			{Role: "assistant", ToolCalls: []models.ToolCall{
+				{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: "tool", ToolCallID: "call_1", Content: "18C"},
+		},
+		Tools:      []models.Tool{{Type: "function", Function: models.ToolFunction{Name: "get_weather", Parameters: json.RawMessage(`{"type":"object"}`)}}},
+		ToolChoice: &models.ToolChoice{Function: "get_weather"},
+	}
+
+	resp, err := client.ChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	calls := resp.Choices[0].Message.ToolCalls
+	if len(calls) != 1 || calls[0].ID != "call_2" || calls[0].Function.Arguments != `{"city":"Lyon"}` {
+		t.Errorf("unexpected tool calls %+v", calls)
+	}
+}
+
+func TestOpenWebUIClient_ToolCallsStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"test-id\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"get_weather\",\"arguments\":\"\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"id\":\"test-id\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"{\\\"city\\\":\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"id\":\"test-id\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenWebUIClient(server.URL, "", 30*time.Second)
+
+	req := &models.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []models.ChatMessage{{Role: "user", Content: "Weather?"}},
+	}
+
+	var fragments []models.ToolCall
+	err := client.ChatCompletionStream(context.Background(), req, func(chunk *models.ChatCompletionChunk) error {
+		fragments = append(fragments, chunk.Choices[0].Delta.ToolCalls...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if len(fragments) != 2 || fragments[1].Index == nil || *fragments[1].Index != 0 {
+		t.Fatalf("expected 2 indexed fragments, got %+v", fragments)
+	}
+	if fragments[0].Function.Name != "get_weather" || fragments[1].Function.Arguments != `{"city":` {
+		t.Errorf("unexpected fragments %+v", fragments)
+	}
+}
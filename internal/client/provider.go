@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Supported provider types
+const (
+	ProviderOpenWebUI = "openwebui"
+	ProviderOpenAI    = "openai"
+	ProviderOllama    = "ollama"
+)
+
+// Provider is implemented by every upstream backend the bridge can forward
+// chat completions and embeddings to
+type Provider interface {
+	HealthCheck(ctx context.Context) error
+	ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error)
+	ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error
+	Embedder
+	ModelLister
+}
+
+// NewProvider creates a provider of the given type. An empty type selects
+// OpenWebUI for backwards compatibility.
+func NewProvider(providerType, baseURL, apiKey string, timeout time.Duration) (Provider, error) {
+	switch providerType {
+	case "", ProviderOpenWebUI:
+		return NewOpenWebUIClient(baseURL, apiKey, timeout), nil
+	case ProviderOpenAI:
+		return NewOpenAIClient(baseURL, apiKey, timeout), nil
+	case ProviderOllama:
+		return NewOllamaClient(baseURL, apiKey, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown provider type %q", providerType)
+	}
+}
+
+// applyPersonaPrompt returns a copy of req with the persona prompt merged
+// into its system messages according to req.PersonaMerge, ready to be sent
+// upstream. Without caller system messages every strategy adds the persona
+// as the first message.
+func applyPersonaPrompt(req *models.ChatCompletionRequest) *models.ChatCompletionRequest {
+	// Create a copy of the request so the caller's messages are not modified
+	upstreamReq := *req
+	upstreamReq.Messages = append([]models.ChatMessage(nil), req.Messages...)
+
+	if req.PersonaPrompt != "" {
+		upstreamReq.Messages = mergePersonaPrompt(upstreamReq.Messages, req.PersonaPrompt, req.PersonaMerge)
+	}
+
+	// Clear bridge fields as they're not part of the upstream APIs
+	upstreamReq.PersonaPrompt = ""
+	upstreamReq.PersonaID = ""
+	upstreamReq.PersonaVars = nil
+	upstreamReq.PersonaMerge = ""
+	upstreamReq.ConversationID = ""
+	upstreamReq.Cache = nil
+
+	return &upstreamReq
+}
+
+// mergePersonaPrompt combines prompt with the system messages in messages,
+// which it may modify in place. Summaries of trimmed history are not caller
+// system messages: they are kept as they are by every strategy.
+func mergePersonaPrompt(messages []models.ChatMessage, prompt, strategy string) []models.ChatMessage {
+	systemMessage := models.ChatMessage{Role: "system", Content: prompt}
+
+	first, last := -1, -1
+	for i, msg := range messages {
+		if msg.Role == "system" && !msg.Summary {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	switch {
+	case first < 0 || strategy == models.MergeSeparate:
+		// Add the persona as its own system message at the beginning
+		return append([]models.ChatMessage{systemMessage}, messages...)
+
+	case strategy == models.MergeReplace, strategy == models.MergeReject:
+		// Caller system messages are dropped; reject requests are refused
+		// before they get here, so this only guards the persona
+		merged := []models.ChatMessage{systemMessage}
+		for _, msg := range messages {
+			if msg.Role != "system" || msg.Summary {
+				merged = append(merged, msg)
+			}
+		}
+		return merged
+
+	case strategy == models.MergeAppend:
+		messages[last].Content = messages[last].Content + "\n\n" + prompt
+		return messages
+
+	default:
+		messages[first].Content = prompt + "\n\n" + messages[first].Content
+		return messages
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name         string
+		providerType string
+		wantErr      bool
+	}{
+		{name: "default", providerType: ""},
+		{name: "openwebui", providerType: ProviderOpenWebUI},
+		{name: "openai", providerType: ProviderOpenAI},
+		{name: "ollama", providerType: ProviderOllama},
+		{name: "unknown", providerType: "bogus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(tt.providerType, "http://localhost", "", 30*time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && provider == nil {
+				t.Error("expected provider, got nil")
+			}
+		})
+	}
+}
+
+func TestOpenAIClient_Paths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			w.WriteHeader(http.StatusOK)
+		case "/v1/chat/completions":
+			json.NewEncoder(w).Encode(models.ChatCompletionResponse{ID: "test-id"})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "", 30*time.Second)
+
+	if err := client.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck failed: %v", err)
+	}
+
+	req := &models.ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []models.ChatMessage{
+			{Role: "user", Content: "Hello"},
+		},
+	}
+
+	resp, err := client.ChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	if resp.ID != "test-id" {
+		t.Errorf("expected ID test-id, got %s", resp.ID)
+	}
+}
+
+func TestApplyPersonaPrompt_DoesNotModifyCaller(t *testing.T) {
+	req := &models.ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []models.ChatMessage{
+			{Role: "system", Content: "You are an AI"},
+			{Role: "user", Content: "Hello"},
+		},
+		PersonaPrompt: "You are a pirate",
+	}
+
+	result := applyPersonaPrompt(req)
+
+	if result.Messages[0].Content != "You are a pirate\n\nYou are an AI" {
+		t.Errorf("unexpected system message: %s", result.Messages[0].Content)
+	}
+
+	if req.Messages[0].Content != "You are an AI" {
+		t.Errorf("expected caller messages to be left untouched, got %s", req.Messages[0].Content)
+	}
+}
+
+func TestApplyPersonaPrompt_MergeStrategies(t *testing.T) {
+	messages := []models.ChatMessage{
+		{Role: "system", Content: "First"},
+		{Role: "user", Content: "Hello"},
+		{Role: "system", Content: "Second"},
+	}
+
+	tests := []struct {
+		strategy string
+		messages []models.ChatMessage
+		expected []models.ChatMessage
+	}{
+		{
+			strategy: "",
+			messages: messages,
+			expected: []models.ChatMessage{{Role: "system", Content: "Persona\n\nFirst"}, {Role: "user", Content: "Hello"}, {Role: "system", Content: "Second"}},
+		},
+		{
+			strategy: models.MergePrepend,
+			messages: messages,
+			expected: []models.ChatMessage{{Role: "system", Content: "Persona\n\nFirst"}, {Role: "user", Content: "Hello"}, {Role: "system", Content: "Second"}},
+		},
+		{
+			strategy: models.MergeAppend,
+			messages: messages,
+			expected: []models.ChatMessage{{Role: "system", Content: "First"}, {Role: "user", Content: "Hello"}, {Role: "system", Content: "Second\n\nPersona"}},
+		},
+		{
+			strategy: models.MergeReplace,
+			messages: messages,
+			expected: []models.ChatMessage{{Role: "system", Content: "Persona"}, {Role: "user", Content: "Hello"}},
+		},
+		{
+			strategy: models.MergeSeparate,
+			messages: messages,
+			expected: []models.ChatMessage{{Role: "system", Content: "Persona"}, {Role: "system", Content: "First"}, {Role: "user", Content: "Hello"}, {Role: "system", Content: "Second"}},
+		},
+		{
+			strategy: models.MergeReject,
+			messages: []models.ChatMessage{{Role: "user", Content: "Hello"}},
+			expected: []models.ChatMessage{{Role: "system", Content: "Persona"}, {Role: "user", Content: "Hello"}},
+		},
+		{
+			strategy: models.MergeAppend,
+			messages: []models.ChatMessage{{Role: "user", Content: "Hello"}},
+			expected: []models.ChatMessage{{Role: "system", Content: "Persona"}, {Role: "user", Content: "Hello"}},
+		},
+		{
+			strategy: models.MergeReplace,
+			messages: []models.ChatMessage{{Role: "system", Content: "First"}, {Role: "system", Content: "Summary", Summary: true}, {Role: "user", Content: "Hello"}},
+			expected: []models.ChatMessage{{Role: "system", Content: "Persona"}, {Role: "system", Content: "Summary", Summary: true}, {Role: "user", Content: "Hello"}},
+		},
+		{
+			strategy: models.MergePrepend,
+			messages: []models.ChatMessage{{Role: "system", Content: "Summary", Summary: true}, {Role: "user", Content: "Hello"}},
+			expected: []models.ChatMessage{{Role: "system", Content: "Persona"}, {Role: "system", Content: "Summary", Summary: true}, {Role: "user", Content: "Hello"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			req := &models.ChatCompletionRequest{
+				Messages:      tt.messages,
+				PersonaPrompt: "Persona",
+				PersonaMerge:  tt.strategy,
+			}
+
+			result := applyPersonaPrompt(req)
+
+			if len(result.Messages) != len(tt.expected) {
+				t.Fatalf("expected %d messages, got %+v", len(tt.expected), result.Messages)
+			}
+			for i := range tt.expected {
+				if !reflect.DeepEqual(result.Messages[i], tt.expected[i]) {
+					t.Errorf("message %d: expected %+v, got %+v", i, tt.expected[i], result.Messages[i])
+				}
+			}
+			if result.PersonaMerge != "" {
+				t.Errorf("expected persona_merge to be cleared, got %q", result.PersonaMerge)
+			}
+		})
+	}
+
+	if messages[0].Content != "First" || messages[2].Content != "Second" {
+		t.Errorf("expected caller messages to be left untouched, got %+v", messages)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"strconv"
 
 	"gopkg.in/yaml.v3"
@@ -12,6 +13,19 @@ import (
 type Config struct {
 	Server    ServerConfig    `yaml:"server"`
 	OpenWebUI OpenWebUIConfig `yaml:"openwebui"`
+	Backends  []BackendConfig `yaml:"backends"`
+	Routing   RoutingConfig   `yaml:"routing"`
+	Retry     RetryConfig     `yaml:"retry"`
+	Breaker   BreakerConfig   `yaml:"circuit_breaker"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Usage     UsageConfig     `yaml:"usage"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Sessions  SessionsConfig  `yaml:"sessions"`
+	Context   ContextConfig   `yaml:"context_window"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Semantic  SemanticConfig  `yaml:"semantic_cache"`
+	Models    ModelsConfig    `yaml:"models"`
 	Logging   LoggingConfig   `yaml:"logging"`
 }
 
@@ -22,11 +36,184 @@ type ServerConfig struct {
 	Host     string `yaml:"host"`
 }
 
-// OpenWebUIConfig holds OpenWebUI API configuration
+// OpenWebUIConfig holds upstream API configuration
 type OpenWebUIConfig struct {
-	BaseURL string `yaml:"base_url"`
-	APIKey  string `yaml:"api_key"`
-	Timeout int    `yaml:"timeout"` // timeout in seconds
+	Provider string `yaml:"provider"` // openwebui, openai or ollama
+	BaseURL  string `yaml:"base_url"`
+	APIKey   string `yaml:"api_key"`
+	Timeout  int    `yaml:"timeout"` // timeout in seconds
+}
+
+// BackendConfig describes one named upstream and the models it serves
+type BackendConfig struct {
+	Name      string   `yaml:"name"`
+	Provider  string   `yaml:"provider"` // openwebui, openai or ollama
+	BaseURL   string   `yaml:"base_url"`
+	APIKey    string   `yaml:"api_key"`
+	Timeout   int      `yaml:"timeout"`   // timeout in seconds
+	Models    []string `yaml:"models"`    // exact names or glob patterns such as "llama3*"
+	Fallbacks []string `yaml:"fallbacks"` // backends tried in order when this one is exhausted
+}
+
+// RoutingConfig holds model routing rules shared by all backends
+type RoutingConfig struct {
+	Aliases        map[string]string   `yaml:"aliases"`         // alias -> real model name
+	DefaultBackend string              `yaml:"default_backend"` // backend for models no rule matches
+	FallbackModels map[string][]string `yaml:"fallback_models"` // model -> models tried in order when it fails
+}
+
+// RetryConfig holds retry settings for transient upstream failures
+type RetryConfig struct {
+	MaxAttempts      int     `yaml:"max_attempts"`       // attempts per backend, including the first
+	InitialBackoffMs int     `yaml:"initial_backoff_ms"` // delay before the first retry
+	MaxBackoffMs     int     `yaml:"max_backoff_ms"`     // upper bound for any single delay
+	Multiplier       float64 `yaml:"multiplier"`         // backoff growth factor
+	Jitter           float64 `yaml:"jitter"`             // random +/- fraction applied to each delay
+}
+
+// BreakerConfig holds per-backend circuit breaker settings
+type BreakerConfig struct {
+	Enabled              bool    `yaml:"enabled"`
+	FailureRatio         float64 `yaml:"failure_ratio"`          // failure fraction that opens the circuit
+	MinRequests          int     `yaml:"min_requests"`           // requests in the window before the ratio applies
+	WindowSeconds        int     `yaml:"window_seconds"`         // failure counting window
+	ProbeIntervalSeconds int     `yaml:"probe_interval_seconds"` // time open before a probe request is allowed
+}
+
+// AuthConfig holds caller authentication settings
+type AuthConfig struct {
+	Enabled   bool           `yaml:"enabled"`
+	Keys      []APIKeyConfig `yaml:"keys"`
+	KeyFile   string         `yaml:"key_file"` // YAML file with a top-level keys list
+	JWT       JWTConfig      `yaml:"jwt"`
+	AdminKeys []string       `yaml:"admin_keys"` // key IDs allowed to change personas and read /admin/usage
+}
+
+// JWTConfig holds bearer token validation settings for tokens issued by an
+// external identity provider
+type JWTConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	Issuer           string `yaml:"issuer"`             // required iss claim, if set
+	Audience         string `yaml:"audience"`           // required aud entry, if set
+	JWKSURL          string `yaml:"jwks_url"`           // key set fetched over HTTP
+	JWKSFile         string `yaml:"jwks_file"`          // key set read from disk
+	JWKSCacheSeconds int    `yaml:"jwks_cache_seconds"` // key set cache lifetime
+	LeewaySeconds    int    `yaml:"leeway_seconds"`     // allowed clock skew for exp and nbf
+	TenantClaim      string `yaml:"tenant_claim"`       // claim mapped to the tenant, dots for nesting
+	OwnerClaim       string `yaml:"owner_claim"`        // claim mapped to the owner
+	ModelsClaim      string `yaml:"models_claim"`       // optional claim listing allowed models
+}
+
+// APIKeyConfig describes one bridge-issued API key. Only the hash of the key
+// is stored.
+type APIKeyConfig struct {
+	ID            string   `yaml:"id"`
+	Hash          string   `yaml:"hash"` // "sha256:<hex>", see fr0g-ai-bridge -hash-key
+	Owner         string   `yaml:"owner"`
+	Tenant        string   `yaml:"tenant"`
+	AllowedModels []string `yaml:"allowed_models"` // names or glob patterns; empty allows all
+	Enabled       *bool    `yaml:"enabled"`        // defaults to true
+}
+
+// RateLimitConfig holds per-caller throttling settings
+type RateLimitConfig struct {
+	Enabled           bool                   `yaml:"enabled"`
+	By                string                 `yaml:"by"`                  // "key" or "tenant"
+	RequestsPerMinute int                    `yaml:"requests_per_minute"` // 0 disables the request limit
+	TokensPerMinute   int                    `yaml:"tokens_per_minute"`   // 0 disables the token limit
+	Overrides         map[string]LimitConfig `yaml:"overrides"`           // keyed by "key:<id>" or "tenant:<name>"
+}
+
+// LimitConfig overrides the default limits for one caller
+type LimitConfig struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	TokensPerMinute   int `yaml:"tokens_per_minute"`
+}
+
+// UsageConfig holds token accounting and quota settings
+type UsageConfig struct {
+	Enabled       bool                   `yaml:"enabled"`
+	LedgerFile    string                 `yaml:"ledger_file"`    // append-only JSON lines file
+	QuotaBy       string                 `yaml:"quota_by"`       // "key" or "tenant"
+	DailyTokens   int                    `yaml:"daily_tokens"`   // 0 disables the daily quota
+	MonthlyTokens int                    `yaml:"monthly_tokens"` // 0 disables the monthly quota
+	Overrides     map[string]QuotaConfig `yaml:"overrides"`      // keyed by "key:<id>" or "tenant:<name>"
+	AdminKeys     []string               `yaml:"admin_keys"`     // additional key IDs allowed to read /admin/usage
+}
+
+// QuotaConfig overrides the default quotas for one caller
+type QuotaConfig struct {
+	DailyTokens   int `yaml:"daily_tokens"`
+	MonthlyTokens int `yaml:"monthly_tokens"`
+}
+
+// StorageConfig selects where personas are persisted
+type StorageConfig struct {
+	Type                 string `yaml:"type"`                   // "memory", "directory" or "sqlite"
+	Path                 string `yaml:"path"`                   // directory or database file
+	WatchIntervalSeconds int    `yaml:"watch_interval_seconds"` // directory polling interval, 0 disables
+}
+
+// SessionsConfig enables server-side conversation history
+type SessionsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Type    string `yaml:"type"` // "memory" or "sqlite"
+	Path    string `yaml:"path"` // database file for sqlite
+}
+
+// CacheConfig enables the response cache for identical chat completions
+type CacheConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	Type              string `yaml:"type"`               // "memory" or "disk"
+	Path              string `yaml:"path"`               // directory for disk
+	MaxEntries        int    `yaml:"max_entries"`        // memory only, least recently used evicted first
+	TTLSeconds        int    `yaml:"ttl_seconds"`        // how long a response is served, 0 for no expiry
+	DeterministicOnly bool   `yaml:"deterministic_only"` // cache only requests with temperature 0
+}
+
+// SemanticConfig enables the cache of answers to similar prompts, matched by
+// the embedding of the final user message
+type SemanticConfig struct {
+	Enabled           bool    `yaml:"enabled"`
+	Model             string  `yaml:"model"`              // embedding model
+	Threshold         float64 `yaml:"threshold"`          // minimum cosine similarity of a hit
+	MaxEntries        int     `yaml:"max_entries"`        // oldest evicted first
+	TTLSeconds        int     `yaml:"ttl_seconds"`        // how long an answer is served, 0 for no expiry
+	DeterministicOnly bool    `yaml:"deterministic_only"` // cache only requests with temperature 0
+}
+
+// ContextConfig holds context window limits and the strategy applied when
+// a request does not fit
+type ContextConfig struct {
+	Enabled        bool           `yaml:"enabled"`
+	Strategy       string         `yaml:"strategy"`        // "drop-oldest", "sliding-window", "summarize" or "fail"
+	DefaultTokens  int            `yaml:"default_tokens"`  // limit for models not listed, 0 for none
+	ReserveTokens  int            `yaml:"reserve_tokens"`  // room kept for the reply when max_tokens is unset
+	WindowMessages int            `yaml:"window_messages"` // non-system messages kept by sliding-window
+	CharsPerToken  float64        `yaml:"chars_per_token"` // token estimator ratio
+	Models         map[string]int `yaml:"models"`          // limits by model name or glob pattern
+
+	SummaryModel     string `yaml:"summary_model"`      // model that writes summaries, the request's model if empty
+	SummaryTokens    int    `yaml:"summary_tokens"`     // maximum length of a summary
+	SummaryCacheSize int    `yaml:"summary_cache_size"` // conversations whose summary is kept
+}
+
+// ModelsConfig holds the model catalog served on /api/models
+type ModelsConfig struct {
+	CacheTTLSeconds int           `yaml:"cache_ttl_seconds"` // how long backend model lists are reused, 0 to always refresh
+	Metadata        []ModelConfig `yaml:"metadata"`          // capabilities by model name or glob pattern
+}
+
+// ModelConfig declares the capabilities and cost of a model, or of every
+// model matching a glob pattern
+type ModelConfig struct {
+	Name          string  `yaml:"name"`           // exact name or glob pattern such as "gpt-4o*"
+	ContextLength int     `yaml:"context_length"` // tokens, the context_window limit if 0
+	Tools         bool    `yaml:"tools"`          // supports tool calling
+	Vision        bool    `yaml:"vision"`         // accepts images
+	Streaming     *bool   `yaml:"streaming"`      // defaults to true
+	InputCost     float64 `yaml:"input_cost"`     // per prompt token
+	OutputCost    float64 `yaml:"output_cost"`    // per completion token
 }
 
 // LoggingConfig holds logging configuration
@@ -44,8 +231,72 @@ func LoadConfig(configPath string) (*Config, error) {
 			Host:     "0.0.0.0",
 		},
 		OpenWebUI: OpenWebUIConfig{
-			BaseURL: "http://localhost:3000",
-			Timeout: 30,
+			Provider: "openwebui",
+			BaseURL:  "http://localhost:3000",
+			Timeout:  30,
+		},
+		Retry: RetryConfig{
+			MaxAttempts:      3,
+			InitialBackoffMs: 200,
+			MaxBackoffMs:     5000,
+			Multiplier:       2,
+			Jitter:           0.2,
+		},
+		Breaker: BreakerConfig{
+			Enabled:              true,
+			FailureRatio:         0.5,
+			MinRequests:          5,
+			WindowSeconds:        60,
+			ProbeIntervalSeconds: 30,
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				JWKSCacheSeconds: 300,
+				LeewaySeconds:    60,
+				TenantClaim:      "tenant",
+				OwnerClaim:       "email",
+			},
+		},
+		RateLimit: RateLimitConfig{
+			By:                "key",
+			RequestsPerMinute: 60,
+			TokensPerMinute:   100000,
+		},
+		Usage: UsageConfig{
+			LedgerFile: "usage.jsonl",
+			QuotaBy:    "key",
+		},
+		Storage: StorageConfig{
+			Type:                 "memory",
+			WatchIntervalSeconds: 5,
+		},
+		Sessions: SessionsConfig{
+			Type: "memory",
+		},
+		Context: ContextConfig{
+			Strategy:       "drop-oldest",
+			DefaultTokens:  8192,
+			ReserveTokens:  1024,
+			WindowMessages: 20,
+			CharsPerToken:  4,
+
+			SummaryTokens:    512,
+			SummaryCacheSize: 1000,
+		},
+		Cache: CacheConfig{
+			Type:              "memory",
+			MaxEntries:        1000,
+			TTLSeconds:        3600,
+			DeterministicOnly: true,
+		},
+		Semantic: SemanticConfig{
+			Threshold:         0.95,
+			MaxEntries:        1000,
+			TTLSeconds:        3600,
+			DeterministicOnly: true,
+		},
+		Models: ModelsConfig{
+			CacheTTLSeconds: 300,
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
@@ -84,6 +335,10 @@ func LoadConfig(configPath string) (*Config, error) {
 		config.Server.Host = host
 	}
 
+	if provider := os.Getenv("OPENWEBUI_PROVIDER"); provider != "" {
+		config.OpenWebUI.Provider = provider
+	}
+
 	if baseURL := os.Getenv("OPENWEBUI_BASE_URL"); baseURL != "" {
 		config.OpenWebUI.BaseURL = baseURL
 	}
@@ -98,9 +353,204 @@ func LoadConfig(configPath string) (*Config, error) {
 		}
 	}
 
+	if attempts := os.Getenv("RETRY_MAX_ATTEMPTS"); attempts != "" {
+		if a, err := strconv.Atoi(attempts); err == nil {
+			config.Retry.MaxAttempts = a
+		}
+	}
+
+	if keyFile := os.Getenv("AUTH_KEY_FILE"); keyFile != "" {
+		config.Auth.KeyFile = keyFile
+	}
+
+	if ledgerFile := os.Getenv("USAGE_LEDGER_FILE"); ledgerFile != "" {
+		config.Usage.LedgerFile = ledgerFile
+	}
+
+	if storageType := os.Getenv("STORAGE_TYPE"); storageType != "" {
+		config.Storage.Type = storageType
+	}
+
+	if storagePath := os.Getenv("STORAGE_PATH"); storagePath != "" {
+		config.Storage.Path = storagePath
+	}
+
+	if sessionsPath := os.Getenv("SESSIONS_PATH"); sessionsPath != "" {
+		config.Sessions.Path = sessionsPath
+	}
+
+	if cachePath := os.Getenv("CACHE_PATH"); cachePath != "" {
+		config.Cache.Path = cachePath
+	}
+
 	if level := os.Getenv("LOG_LEVEL"); level != "" {
 		config.Logging.Level = level
 	}
 
+	if err := config.validateBackends(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Breaker.validate(); err != nil {
+		return nil, err
+	}
+
+	if by := config.RateLimit.By; by != "key" && by != "tenant" {
+		return nil, fmt.Errorf("rate_limit.by must be \"key\" or \"tenant\", got %q", by)
+	}
+
+	if by := config.Usage.QuotaBy; by != "key" && by != "tenant" {
+		return nil, fmt.Errorf("usage.quota_by must be \"key\" or \"tenant\", got %q", by)
+	}
+
+	if err := config.Context.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.Cache.TTLSeconds < 0 {
+		return nil, fmt.Errorf("cache.ttl_seconds must not be negative")
+	}
+
+	if err := config.validateSemantic(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Models.validate(); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
+
+// BackendList returns the configured backends. When no backends list is
+// given, the openwebui section becomes a single catch-all backend.
+func (c *Config) BackendList() []BackendConfig {
+	if len(c.Backends) > 0 {
+		return c.Backends
+	}
+
+	return []BackendConfig{
+		{
+			Name:     "default",
+			Provider: c.OpenWebUI.Provider,
+			BaseURL:  c.OpenWebUI.BaseURL,
+			APIKey:   c.OpenWebUI.APIKey,
+			Timeout:  c.OpenWebUI.Timeout,
+			Models:   []string{"*"},
+		},
+	}
+}
+
+// validateBackends checks the backends list and fills in defaults
+func (c *Config) validateBackends() error {
+	names := make(map[string]bool)
+	for i := range c.Backends {
+		b := &c.Backends[i]
+		if b.Name == "" {
+			return fmt.Errorf("backend %d: name is required", i)
+		}
+		if names[b.Name] {
+			return fmt.Errorf("backend %q: duplicate name", b.Name)
+		}
+		names[b.Name] = true
+
+		if b.BaseURL == "" {
+			return fmt.Errorf("backend %q: base_url is required", b.Name)
+		}
+		if b.Timeout == 0 {
+			b.Timeout = c.OpenWebUI.Timeout
+		}
+	}
+
+	for _, b := range c.Backends {
+		for _, fallback := range b.Fallbacks {
+			if !names[fallback] {
+				return fmt.Errorf("backend %q: unknown fallback backend %q", b.Name, fallback)
+			}
+		}
+	}
+
+	if c.Routing.DefaultBackend != "" && len(c.Backends) > 0 && !names[c.Routing.DefaultBackend] {
+		return fmt.Errorf("routing: unknown default_backend %q", c.Routing.DefaultBackend)
+	}
+
+	return nil
+}
+
+// validateSemantic checks the semantic cache settings when it is enabled
+func (c *Config) validateSemantic() error {
+	s := c.Semantic
+	if !s.Enabled {
+		return nil
+	}
+	if s.Model == "" {
+		return fmt.Errorf("semantic_cache.model is required")
+	}
+	if s.Threshold <= 0 || s.Threshold > 1 {
+		return fmt.Errorf("semantic_cache.threshold must be in (0, 1], got %v", s.Threshold)
+	}
+	if s.TTLSeconds < 0 {
+		return fmt.Errorf("semantic_cache.ttl_seconds must not be negative")
+	}
+	return nil
+}
+
+// validate checks the circuit breaker settings when it is enabled
+func (c *BreakerConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.FailureRatio <= 0 || c.FailureRatio > 1 {
+		return fmt.Errorf("circuit_breaker.failure_ratio must be in (0, 1], got %v", c.FailureRatio)
+	}
+	if c.MinRequests < 1 {
+		return fmt.Errorf("circuit_breaker.min_requests must be at least 1, got %d", c.MinRequests)
+	}
+	if c.WindowSeconds < 1 {
+		return fmt.Errorf("circuit_breaker.window_seconds must be at least 1, got %d", c.WindowSeconds)
+	}
+	if c.ProbeIntervalSeconds < 1 {
+		return fmt.Errorf("circuit_breaker.probe_interval_seconds must be at least 1, got %d", c.ProbeIntervalSeconds)
+	}
+	return nil
+}
+
+// validate checks the model catalog settings
+func (c *ModelsConfig) validate() error {
+	if c.CacheTTLSeconds < 0 {
+		return fmt.Errorf("models.cache_ttl_seconds must not be negative")
+	}
+	for i, m := range c.Metadata {
+		if m.Name == "" {
+			return fmt.Errorf("models.metadata %d: name is required", i)
+		}
+		if _, err := path.Match(m.Name, ""); err != nil {
+			return fmt.Errorf("models.metadata %q: invalid pattern: %w", m.Name, err)
+		}
+		if m.ContextLength < 0 || m.InputCost < 0 || m.OutputCost < 0 {
+			return fmt.Errorf("models.metadata %q: context_length and costs must not be negative", m.Name)
+		}
+	}
+	return nil
+}
+
+// validate checks the context window settings
+func (c *ContextConfig) validate() error {
+	switch c.Strategy {
+	case "drop-oldest", "sliding-window", "summarize", "fail":
+	default:
+		return fmt.Errorf("context_window.strategy must be \"drop-oldest\", \"sliding-window\", \"summarize\" or \"fail\", got %q", c.Strategy)
+	}
+	if c.CharsPerToken <= 0 {
+		return fmt.Errorf("context_window.chars_per_token must be positive")
+	}
+	if c.Strategy == "summarize" && c.SummaryTokens <= 0 {
+		return fmt.Errorf("context_window.summary_tokens must be positive")
+	}
+	for pattern := range c.Models {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("context_window.models: invalid model pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
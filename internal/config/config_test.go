@@ -33,6 +33,10 @@ func TestLoadConfig_DefaultValues(t *testing.T) {
 	if cfg.OpenWebUI.Timeout != 30 {
 		t.Errorf("expected default timeout 30, got %d", cfg.OpenWebUI.Timeout)
 	}
+
+	if cfg.OpenWebUI.Provider != "openwebui" {
+		t.Errorf("expected default provider openwebui, got %s", cfg.OpenWebUI.Provider)
+	}
 }
 
 func TestLoadConfig_FromFile(t *testing.T) {
@@ -47,6 +51,7 @@ server:
   host: "127.0.0.1"
 
 openwebui:
+  provider: "ollama"
   base_url: "http://test.example.com"
   api_key: "test-key"
   timeout: 60
@@ -84,6 +89,10 @@ logging:
 		t.Errorf("expected OpenWebUI URL http://test.example.com, got %s", cfg.OpenWebUI.BaseURL)
 	}
 
+	if cfg.OpenWebUI.Provider != "ollama" {
+		t.Errorf("expected provider ollama, got %s", cfg.OpenWebUI.Provider)
+	}
+
 	if cfg.OpenWebUI.APIKey != "test-key" {
 		t.Errorf("expected API key test-key, got %s", cfg.OpenWebUI.APIKey)
 	}
@@ -135,3 +144,402 @@ server:
 		t.Error("expected error for invalid YAML config file")
 	}
 }
+
+func TestLoadConfig_Backends(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "backends.yaml")
+
+	configContent := `
+openwebui:
+  timeout: 45
+
+backends:
+  - name: "cloud"
+    provider: "openai"
+    base_url: "https://api.example.com"
+    models: ["gpt-4o"]
+  - name: "local"
+    provider: "ollama"
+    base_url: "http://localhost:11434"
+    timeout: 120
+    models: ["llama3*"]
+
+routing:
+  aliases:
+    fast: "llama3.1:8b"
+  default_backend: "local"
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	backends := cfg.BackendList()
+	if len(backends) != 2 {
+		t.Fatalf("expected 2 backends, got %d", len(backends))
+	}
+
+	if backends[0].Timeout != 45 {
+		t.Errorf("expected timeout to default to openwebui timeout 45, got %d", backends[0].Timeout)
+	}
+
+	if backends[1].Timeout != 120 {
+		t.Errorf("expected timeout 120, got %d", backends[1].Timeout)
+	}
+
+	if cfg.Routing.Aliases["fast"] != "llama3.1:8b" {
+		t.Errorf("expected alias fast -> llama3.1:8b, got %s", cfg.Routing.Aliases["fast"])
+	}
+}
+
+func TestLoadConfig_LegacyBackend(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	backends := cfg.BackendList()
+	if len(backends) != 1 {
+		t.Fatalf("expected 1 backend, got %d", len(backends))
+	}
+
+	if backends[0].BaseURL != cfg.OpenWebUI.BaseURL || backends[0].Models[0] != "*" {
+		t.Errorf("expected openwebui section as catch-all backend, got %+v", backends[0])
+	}
+}
+
+func TestLoadConfig_InvalidBackends(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "missing name",
+			content: `
+backends:
+  - base_url: "http://localhost"
+`,
+		},
+		{
+			name: "duplicate name",
+			content: `
+backends:
+  - name: "a"
+    base_url: "http://localhost"
+  - name: "a"
+    base_url: "http://localhost"
+`,
+		},
+		{
+			name: "unknown default backend",
+			content: `
+backends:
+  - name: "a"
+    base_url: "http://localhost"
+routing:
+  default_backend: "b"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			if _, err := LoadConfig(configPath); err == nil {
+				t.Error("expected error for invalid backends")
+			}
+		})
+	}
+}
+
+func TestLoadConfig_CircuitBreaker(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			content: `
+circuit_breaker:
+  failure_ratio: 1
+  min_requests: 1
+`,
+		},
+		{
+			name: "disabled with zero values",
+			content: `
+circuit_breaker:
+  enabled: false
+  failure_ratio: 0
+  window_seconds: 0
+`,
+		},
+		{
+			name: "zero failure ratio",
+			content: `
+circuit_breaker:
+  failure_ratio: 0
+`,
+			wantErr: true,
+		},
+		{
+			name: "failure ratio above one",
+			content: `
+circuit_breaker:
+  failure_ratio: 1.5
+`,
+			wantErr: true,
+		},
+		{
+			name: "zero min requests",
+			content: `
+circuit_breaker:
+  min_requests: 0
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative window",
+			content: `
+circuit_breaker:
+  window_seconds: -60
+`,
+			wantErr: true,
+		},
+		{
+			name: "zero probe interval",
+			content: `
+circuit_breaker:
+  probe_interval_seconds: 0
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			if _, err := LoadConfig(configPath); (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_ContextWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			content: `
+context_window:
+  enabled: true
+  strategy: "sliding-window"
+  models:
+    "llama3*": 8192
+`,
+		},
+		{
+			name: "summarize",
+			content: `
+context_window:
+  strategy: "summarize"
+  summary_model: "llama3.2:1b"
+`,
+		},
+		{
+			name: "unknown strategy",
+			content: `
+context_window:
+  strategy: "summarize-everything"
+`,
+			wantErr: true,
+		},
+		{
+			name: "summarize without summary tokens",
+			content: `
+context_window:
+  strategy: "summarize"
+  summary_tokens: 0
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid pattern",
+			content: `
+context_window:
+  models:
+    "llama[": 8192
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			cfg, err := LoadConfig(configPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.Context.CharsPerToken != 4 || cfg.Context.ReserveTokens != 1024 || cfg.Context.SummaryTokens != 512) {
+				t.Errorf("expected defaults to be kept, got %+v", cfg.Context)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_SemanticCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "disabled without model",
+			content: `
+semantic_cache:
+  enabled: false
+`,
+		},
+		{
+			name: "valid",
+			content: `
+semantic_cache:
+  enabled: true
+  model: nomic-embed-text
+`,
+		},
+		{
+			name: "missing model",
+			content: `
+semantic_cache:
+  enabled: true
+`,
+			wantErr: true,
+		},
+		{
+			name: "threshold out of range",
+			content: `
+semantic_cache:
+  enabled: true
+  model: nomic-embed-text
+  threshold: 1.5
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			cfg, err := LoadConfig(configPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.Semantic.Threshold != 0.95 || !cfg.Semantic.DeterministicOnly) {
+				t.Errorf("expected defaults to be kept, got %+v", cfg.Semantic)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Models(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			content: `
+models:
+  metadata:
+    - name: "gpt-4o*"
+      context_length: 128000
+      tools: true
+      vision: true
+      input_cost: 0.0000025
+      output_cost: 0.00001
+`,
+		},
+		{
+			name: "missing name",
+			content: `
+models:
+  metadata:
+    - tools: true
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid pattern",
+			content: `
+models:
+  metadata:
+    - name: "gpt-[4o"
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative cost",
+			content: `
+models:
+  metadata:
+    - name: "gpt-4o"
+      input_cost: -1
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative ttl",
+			content: `
+models:
+  cache_ttl_seconds: -1
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			cfg, err := LoadConfig(configPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Models.CacheTTLSeconds != 300 {
+				t.Errorf("expected the default cache TTL of 300, got %d", cfg.Models.CacheTTLSeconds)
+			}
+		})
+	}
+}
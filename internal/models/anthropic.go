@@ -0,0 +1,136 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AnthropicMessagesRequest represents a request to the Anthropic-compatible
+// messages endpoint
+type AnthropicMessagesRequest struct {
+	Model       string               `json:"model"`                 // Model name to use
+	Messages    []AnthropicMessage   `json:"messages"`              // Alternating user and assistant turns
+	System      AnthropicContent     `json:"system,omitempty"`      // System prompt
+	MaxTokens   int                  `json:"max_tokens"`            // Maximum tokens to generate, required
+	Temperature *float64             `json:"temperature,omitempty"` // Sampling temperature
+	Stream      bool                 `json:"stream,omitempty"`      // Whether to stream the response
+	Tools       []AnthropicTool      `json:"tools,omitempty"`       // Tools the model may use
+	ToolChoice  *AnthropicToolChoice `json:"tool_choice,omitempty"` // Whether and which tool to use
+}
+
+// AnthropicTool describes a tool the model may use
+type AnthropicTool struct {
+	Name        string          `json:"name"`                  // Tool name
+	Description string          `json:"description,omitempty"` // What the tool does
+	InputSchema json.RawMessage `json:"input_schema"`          // JSON Schema of the input
+}
+
+// AnthropicToolChoice controls whether and which tool the model uses
+type AnthropicToolChoice struct {
+	Type string `json:"type"`           // "auto", "any", "tool" or "none"
+	Name string `json:"name,omitempty"` // Tool to use with type "tool"
+}
+
+// AnthropicMessage represents a single turn of an Anthropic conversation
+type AnthropicMessage struct {
+	Role    string           `json:"role"`    // "user" or "assistant"
+	Content AnthropicContent `json:"content"` // The message content
+}
+
+// AnthropicContent is a list of content blocks. In JSON it may also be given
+// as a single string, which is a single text block.
+type AnthropicContent []AnthropicContentBlock
+
+// UnmarshalJSON accepts a string or an array of content blocks
+func (c *AnthropicContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = AnthropicContent{{Type: "text", Text: text}}
+		return nil
+	}
+
+	var blocks []AnthropicContentBlock
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return fmt.Errorf("content must be a string or an array of content blocks")
+	}
+	*c = blocks
+	return nil
+}
+
+// AnthropicContentBlock is a single block of message content
+type AnthropicContentBlock struct {
+	Type      string           `json:"type"`                  // "text", "tool_use" or "tool_result"
+	Text      string           `json:"text,omitempty"`        // Text of a text block
+	ID        string           `json:"id,omitempty"`          // ID of a tool_use block
+	Name      string           `json:"name,omitempty"`        // Tool a tool_use block calls
+	Input     json.RawMessage  `json:"input,omitempty"`       // Input of a tool_use block
+	ToolUseID string           `json:"tool_use_id,omitempty"` // tool_use block a tool_result answers
+	Content   AnthropicContent `json:"content,omitempty"`     // Result of a tool_result block
+	IsError   bool             `json:"is_error,omitempty"`    // Whether the tool failed
+}
+
+// MarshalJSON always includes the text of text blocks, even when empty
+func (b AnthropicContentBlock) MarshalJSON() ([]byte, error) {
+	if b.Type == "text" {
+		return json.Marshal(struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		}{b.Type, b.Text})
+	}
+
+	type block AnthropicContentBlock // Without this method
+	return json.Marshal(block(b))
+}
+
+// AnthropicMessagesResponse represents the reply of the messages endpoint
+type AnthropicMessagesResponse struct {
+	ID           string           `json:"id"`            // Unique response ID
+	Type         string           `json:"type"`          // Object type ("message")
+	Role         string           `json:"role"`          // Always "assistant"
+	Content      AnthropicContent `json:"content"`       // Generated content blocks
+	Model        string           `json:"model"`         // Model used
+	StopReason   *string          `json:"stop_reason"`   // Why generation stopped, null while streaming
+	StopSequence *string          `json:"stop_sequence"` // Stop sequence hit, if any
+	Usage        AnthropicUsage   `json:"usage"`         // Token usage information
+}
+
+// AnthropicUsage represents token usage statistics
+type AnthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`  // Tokens in the prompt
+	OutputTokens int `json:"output_tokens"` // Tokens in the completion
+}
+
+// AnthropicStreamEvent is a server-sent event of a streamed message. Type
+// decides which of the other fields are set.
+type AnthropicStreamEvent struct {
+	Type         string                     `json:"type"`                    // Event type, also sent as the SSE event name
+	Message      *AnthropicMessagesResponse `json:"message,omitempty"`       // message_start
+	Index        *int                       `json:"index,omitempty"`         // content_block_* events
+	ContentBlock *AnthropicContentBlock     `json:"content_block,omitempty"` // content_block_start
+	Delta        *AnthropicDelta            `json:"delta,omitempty"`         // content_block_delta and message_delta
+	Usage        *AnthropicUsage            `json:"usage,omitempty"`         // message_delta
+	Error        *AnthropicError            `json:"error,omitempty"`         // error
+}
+
+// AnthropicDelta is the incremental part of a content_block_delta or
+// message_delta event
+type AnthropicDelta struct {
+	Type         string  `json:"type,omitempty"`         // "text_delta" or "input_json_delta" for content blocks
+	Text         string  `json:"text,omitempty"`         // Text fragment
+	PartialJSON  string  `json:"partial_json,omitempty"` // Fragment of a tool_use block's input
+	StopReason   string  `json:"stop_reason,omitempty"`  // Set on message_delta
+	StopSequence *string `json:"stop_sequence,omitempty"`
+}
+
+// AnthropicErrorResponse is the error envelope of the Anthropic-compatible
+// API
+type AnthropicErrorResponse struct {
+	Type  string         `json:"type"` // Always "error"
+	Error AnthropicError `json:"error"`
+}
+
+// AnthropicError describes a failed Anthropic-compatible request
+type AnthropicError struct {
+	Type    string `json:"type"`    // Error category, e.g. "invalid_request_error"
+	Message string `json:"message"` // Human-readable description
+}
@@ -1,31 +1,198 @@
 package models
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // ChatMessage represents a single message in a conversation
 type ChatMessage struct {
-	Role    string `json:"role"`    // "user", "assistant", "system"
-	Content string `json:"content"` // The message content
+	Role       string     `json:"role"`                   // "user", "assistant", "system" or "tool"
+	Content    string     `json:"content"`                // The message content, may be empty for tool calls and results
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Tools the assistant calls
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Call a tool message answers
+
+	// Summary marks the system message the bridge writes in place of
+	// trimmed history. It is never decoded from callers, so persona merge
+	// strategies can keep it while dropping caller system messages.
+	Summary bool `json:"-"`
+}
+
+// Validate checks the rules every chat message must follow. Tool results and
+// assistant messages that only call tools may have empty content.
+func (m ChatMessage) Validate() error {
+	if m.Role == "" {
+		return fmt.Errorf("role is required")
+	}
+	if len(m.ToolCalls) > 0 && m.Role != "assistant" {
+		return fmt.Errorf("only assistant messages may call tools")
+	}
+	for i, call := range m.ToolCalls {
+		if call.Function.Name == "" {
+			return fmt.Errorf("tool call %d: function name is required", i)
+		}
+	}
+	if m.Content == "" && m.Role != "tool" && len(m.ToolCalls) == 0 {
+		return fmt.Errorf("content is required")
+	}
+	return nil
+}
+
+// Tool describes a function the model may call
+type Tool struct {
+	Type     string       `json:"type"`     // Always "function"
+	Function ToolFunction `json:"function"` // The callable function
+}
+
+// ToolFunction is the definition of a callable function
+type ToolFunction struct {
+	Name        string          `json:"name"`                  // Function name
+	Description string          `json:"description,omitempty"` // What the function does
+	Parameters  json.RawMessage `json:"parameters,omitempty"`  // JSON Schema of the arguments
+}
+
+// ToolCall is a function call requested by the assistant. In streamed
+// deltas the call is split over several chunks sharing its Index; only the
+// first carries the ID, type and name.
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"` // Position of the call, set in streamed deltas only
+	ID       string       `json:"id,omitempty"`    // Call ID echoed in the tool message answering it
+	Type     string       `json:"type,omitempty"`  // Always "function"
+	Function FunctionCall `json:"function"`        // The function to call
+}
+
+// FunctionCall names a function and the arguments to call it with
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`      // Function name
+	Arguments string `json:"arguments,omitempty"` // JSON-encoded arguments, or a fragment of them in deltas
+}
+
+// UnmarshalJSON accepts the arguments as a JSON-encoded string, as OpenAI
+// sends them, or as a JSON object, as Ollama does
+func (f *FunctionCall) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	f.Name = raw.Name
+	f.Arguments = ""
+	switch {
+	case len(raw.Arguments) == 0 || string(raw.Arguments) == "null":
+	case raw.Arguments[0] == '"':
+		if err := json.Unmarshal(raw.Arguments, &f.Arguments); err != nil {
+			return err
+		}
+	default:
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw.Arguments); err != nil {
+			return err
+		}
+		f.Arguments = compact.String()
+	}
+	return nil
+}
+
+// Tool choice modes
+const (
+	ToolChoiceNone     = "none"     // Never call a tool
+	ToolChoiceAuto     = "auto"     // Let the model decide (default)
+	ToolChoiceRequired = "required" // Call at least one tool
+)
+
+// ToolChoice controls whether and which tool the model calls. In JSON it is
+// one of the modes or {"type": "function", "function": {"name": ...}} to
+// force a particular function.
+type ToolChoice struct {
+	Mode     string // none, auto or required; empty when Function is set
+	Function string // Function the model must call
+}
+
+// toolChoiceFunction is the JSON form of a ToolChoice naming a function
+type toolChoiceFunction struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+// MarshalJSON encodes the choice as a mode string or a function object
+func (c ToolChoice) MarshalJSON() ([]byte, error) {
+	if c.Function == "" {
+		return json.Marshal(c.Mode)
+	}
+	v := toolChoiceFunction{Type: "function"}
+	v.Function.Name = c.Function
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON accepts a mode string or a function object
+func (c *ToolChoice) UnmarshalJSON(data []byte) error {
+	var mode string
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*c = ToolChoice{Mode: mode}
+		return nil
+	}
+
+	var v toolChoiceFunction
+	if err := json.Unmarshal(data, &v); err != nil || v.Type != "function" || v.Function.Name == "" {
+		return fmt.Errorf(`tool_choice must be "none", "auto", "required" or a function`)
+	}
+	*c = ToolChoice{Function: v.Function.Name}
+	return nil
 }
 
 // ChatCompletionRequest represents a request to the chat completion endpoint
 type ChatCompletionRequest struct {
-	Model         string        `json:"model"`                   // Model name to use
-	Messages      []ChatMessage `json:"messages"`                // Conversation messages
-	Temperature   *float64      `json:"temperature,omitempty"`   // Sampling temperature
-	MaxTokens     *int          `json:"max_tokens,omitempty"`    // Maximum tokens to generate
-	Stream        *bool         `json:"stream,omitempty"`        // Whether to stream the response
-	PersonaPrompt string        `json:"persona_prompt,omitempty"` // Additional persona context
+	Model         string            `json:"model"`                    // Model name to use
+	Messages      []ChatMessage     `json:"messages"`                 // Conversation messages
+	Temperature   *float64          `json:"temperature,omitempty"`    // Sampling temperature
+	MaxTokens     *int              `json:"max_tokens,omitempty"`     // Maximum tokens to generate
+	Stream        *bool             `json:"stream,omitempty"`         // Whether to stream the response
+	StreamOptions *StreamOptions    `json:"stream_options,omitempty"` // Options of a streamed response
+	Tools         []Tool            `json:"tools,omitempty"`          // Functions the model may call
+	ToolChoice    *ToolChoice       `json:"tool_choice,omitempty"`    // Whether and which tool to call
+	PersonaPrompt string            `json:"persona_prompt,omitempty"` // Additional persona context
+	PersonaID     string            `json:"persona_id,omitempty"`     // Registered persona to apply
+	PersonaVars   map[string]string `json:"persona_vars,omitempty"`   // Variables for the persona prompt template
+	PersonaMerge  string            `json:"persona_merge,omitempty"`  // How the persona prompt joins caller system messages
+
+	ConversationID string `json:"conversation_id,omitempty"` // Server-side conversation to continue
+	Cache          *bool  `json:"cache,omitempty"`           // false bypasses the response cache
+}
+
+// StreamOptions configures a streamed chat completion
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` // Send a final chunk carrying only usage
 }
 
+// Persona merge strategies, deciding how a persona prompt is combined with
+// system messages supplied by the caller
+const (
+	MergePrepend  = "prepend"          // Before the first caller system message (default)
+	MergeAppend   = "append"           // After the last caller system message
+	MergeReplace  = "replace"          // Drop caller system messages
+	MergeSeparate = "separate-message" // As its own system message ahead of the caller's
+	MergeReject   = "reject"           // Refuse requests that carry a system message
+)
+
 // ChatCompletionResponse represents the response from chat completion
 type ChatCompletionResponse struct {
-	ID      string    `json:"id"`      // Unique response ID
-	Object  string    `json:"object"`  // Object type
-	Created int64     `json:"created"` // Creation timestamp
-	Model   string    `json:"model"`   // Model used
-	Choices []Choice  `json:"choices"` // Response choices
-	Usage   Usage     `json:"usage"`   // Token usage information
+	ID      string   `json:"id"`      // Unique response ID
+	Object  string   `json:"object"`  // Object type
+	Created int64    `json:"created"` // Creation timestamp
+	Model   string   `json:"model"`   // Model used
+	Choices []Choice `json:"choices"` // Response choices
+	Usage   Usage    `json:"usage"`   // Token usage information
+
+	PersonaID      string `json:"persona_id,omitempty"`      // Registered persona applied to the request
+	PersonaVersion int    `json:"persona_version,omitempty"` // Version of the applied persona
+	ConversationID string `json:"conversation_id,omitempty"` // Conversation the reply was recorded in
 }
 
 // Choice represents a single response choice
@@ -35,6 +202,34 @@ type Choice struct {
 	FinishReason string      `json:"finish_reason"` // Reason for completion
 }
 
+// ChatCompletionChunk represents a single streamed chat completion delta
+type ChatCompletionChunk struct {
+	ID      string        `json:"id"`              // Unique response ID shared by all chunks
+	Object  string        `json:"object"`          // Object type ("chat.completion.chunk")
+	Created int64         `json:"created"`         // Creation timestamp
+	Model   string        `json:"model"`           // Model used
+	Choices []ChunkChoice `json:"choices"`         // Streamed choice deltas
+	Usage   *Usage        `json:"usage,omitempty"` // Token usage, usually only on the final chunk
+
+	PersonaID      string `json:"persona_id,omitempty"`      // Registered persona applied to the request
+	PersonaVersion int    `json:"persona_version,omitempty"` // Version of the applied persona
+	ConversationID string `json:"conversation_id,omitempty"` // Conversation the reply is recorded in
+}
+
+// ChunkChoice represents a single choice delta within a streamed chunk
+type ChunkChoice struct {
+	Index        int       `json:"index"`                   // Choice index
+	Delta        ChatDelta `json:"delta"`                   // Incremental message content
+	FinishReason string    `json:"finish_reason,omitempty"` // Reason for completion, set on the last delta
+}
+
+// ChatDelta represents the incremental part of a streamed message
+type ChatDelta struct {
+	Role      string     `json:"role,omitempty"`       // Set on the first delta only
+	Content   string     `json:"content,omitempty"`    // Content fragment
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"` // Tool call fragments, identified by their Index
+}
+
 // Usage represents token usage statistics
 type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`     // Tokens in the prompt
@@ -44,9 +239,153 @@ type Usage struct {
 
 // HealthResponse represents a health check response
 type HealthResponse struct {
-	Status  string    `json:"status"`
-	Version string    `json:"version"`
-	Time    time.Time `json:"time"`
+	Status   string          `json:"status"`
+	Version  string          `json:"version"`
+	Time     time.Time       `json:"time"`
+	Backends []BackendHealth `json:"backends,omitempty"`
+}
+
+// BackendHealth represents the health of a single upstream backend
+type BackendHealth struct {
+	Name    string `json:"name"`              // Backend name from config
+	Status  string `json:"status"`            // "healthy" or "unhealthy"
+	Circuit string `json:"circuit,omitempty"` // Circuit breaker state: closed, open or half-open
+	Error   string `json:"error,omitempty"`   // Health check failure, if any
+}
+
+// ModelList represents the models the bridge can serve
+type ModelList struct {
+	Object string  `json:"object"` // Object type ("list")
+	Data   []Model `json:"data"`   // The models
+}
+
+// Model describes a model reported by a backend, merged with the metadata
+// declared in config
+type Model struct {
+	ID            string            `json:"id"`                       // Model name to send in requests
+	Object        string            `json:"object"`                   // Object type ("model")
+	Created       int64             `json:"created"`                  // Unix time, 0 if the backend does not report it
+	OwnedBy       string            `json:"owned_by"`                 // Owner reported by the backend, else the backend
+	Backend       string            `json:"backend,omitempty"`        // Backend that serves the model
+	ContextLength int               `json:"context_length,omitempty"` // Context window in tokens, if known
+	Capabilities  ModelCapabilities `json:"capabilities"`             // What the model supports
+	Pricing       *ModelPricing     `json:"pricing,omitempty"`        // Cost per token, if declared
+	Aliases       []string          `json:"aliases,omitempty"`        // Alias names routed to the model
+}
+
+// ModelCapabilities lists the features a model supports
+type ModelCapabilities struct {
+	Tools     bool `json:"tools"`     // Tool calling
+	Vision    bool `json:"vision"`    // Image inputs
+	Streaming bool `json:"streaming"` // Streamed responses
+}
+
+// ModelPricing is the cost of a model per token
+type ModelPricing struct {
+	Input  float64 `json:"input"`  // Per prompt token
+	Output float64 `json:"output"` // Per completion token
+}
+
+// Persona is a named system prompt with request defaults, managed by the
+// bridge and applied to requests that set persona_id
+type Persona struct {
+	ID                 string    `json:"id" yaml:"id"`                                                       // Unique identifier used as persona_id
+	Version            int       `json:"version" yaml:"version,omitempty"`                                   // Immutable version, incremented on every change
+	Name               string    `json:"name" yaml:"name"`                                                   // Human readable name
+	SystemPrompt       string    `json:"system_prompt" yaml:"system_prompt"`                                 // Prompt merged into the system message
+	DefaultModel       string    `json:"default_model,omitempty" yaml:"default_model,omitempty"`             // Model used when the request names none
+	DefaultTemperature *float64  `json:"default_temperature,omitempty" yaml:"default_temperature,omitempty"` // Temperature used when the request sets none
+	Tags               []string  `json:"tags,omitempty" yaml:"tags,omitempty"`                               // Free-form labels for filtering
+	MergeStrategy      string    `json:"merge_strategy,omitempty" yaml:"merge_strategy,omitempty"`           // How the prompt joins caller system messages
+	CreatedAt          time.Time `json:"created_at" yaml:"created_at,omitempty"`
+	UpdatedAt          time.Time `json:"updated_at" yaml:"updated_at,omitempty"`
+}
+
+// PersonaList represents a list of personas
+type PersonaList struct {
+	Personas []Persona `json:"personas"`
+}
+
+// PersonaDiff describes the changes between two persona versions
+type PersonaDiff struct {
+	ID         string        `json:"id"`
+	From       int           `json:"from"`        // Older version
+	To         int           `json:"to"`          // Newer version
+	Fields     []FieldChange `json:"fields"`      // Changed fields other than the system prompt
+	PromptDiff string        `json:"prompt_diff"` // Line diff of the system prompt, empty if unchanged
+}
+
+// FieldChange is a single changed persona field
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// RollbackRequest selects the persona version to restore
+type RollbackRequest struct {
+	Version int `json:"version"`
+}
+
+// Conversation is a chat history kept by the bridge. Requests naming its ID
+// in conversation_id send only their new turns; the bridge prepends the
+// history and records the assistant's reply.
+type Conversation struct {
+	ID        string        `json:"id"`
+	Owner     string        `json:"owner,omitempty"`      // Principal that created it, when authentication is enabled
+	Model     string        `json:"model,omitempty"`      // Model used when a turn names none
+	PersonaID string        `json:"persona_id,omitempty"` // Persona used when a turn names none
+	Messages  []ChatMessage `json:"messages,omitempty"`   // History, oldest first; omitted in lists
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ConversationList represents a list of conversations
+type ConversationList struct {
+	Conversations []Conversation `json:"conversations"`
+}
+
+// EmbeddingRequest represents a request for embeddings of one or more inputs
+type EmbeddingRequest struct {
+	Model          string         `json:"model"`                     // Embedding model to use
+	Input          EmbeddingInput `json:"input"`                     // Texts to embed
+	EncodingFormat string         `json:"encoding_format,omitempty"` // "float" (default) or "base64"
+}
+
+// EmbeddingInput is a list of texts to embed. In JSON it may also be given
+// as a single string.
+type EmbeddingInput []string
+
+// UnmarshalJSON accepts a string or an array of strings
+func (in *EmbeddingInput) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*in = EmbeddingInput{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("input must be a string or an array of strings")
+	}
+	*in = list
+	return nil
+}
+
+// EmbeddingResponse represents the embeddings of a request's inputs, in the
+// same order
+type EmbeddingResponse struct {
+	Object string      `json:"object"` // Object type ("list")
+	Data   []Embedding `json:"data"`   // One embedding per input
+	Model  string      `json:"model"`  // Model used
+	Usage  Usage       `json:"usage"`  // Token usage information
+}
+
+// Embedding is the vector of a single input
+type Embedding struct {
+	Object    string    `json:"object"`    // Object type ("embedding")
+	Index     int       `json:"index"`     // Position of the input
+	Embedding []float64 `json:"embedding"` // The vector
 }
 
 // ErrorResponse represents an error response
@@ -55,3 +394,16 @@ type ErrorResponse struct {
 	Message string `json:"message,omitempty"`
 	Code    int    `json:"code,omitempty"`
 }
+
+// OpenAIErrorResponse is the error envelope of the OpenAI-compatible API
+type OpenAIErrorResponse struct {
+	Error OpenAIError `json:"error"`
+}
+
+// OpenAIError describes a failed OpenAI-compatible request
+type OpenAIError struct {
+	Message string  `json:"message"` // Human-readable description
+	Type    string  `json:"type"`    // Error category, e.g. "invalid_request_error"
+	Param   *string `json:"param"`   // Offending request parameter, if known
+	Code    *string `json:"code"`    // Machine-readable code, e.g. "model_not_found"
+}
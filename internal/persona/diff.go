@@ -0,0 +1,95 @@
+package persona
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// diff describes the changes from older to newer
+func diff(older, newer models.Persona) models.PersonaDiff {
+	d := models.PersonaDiff{
+		ID:     newer.ID,
+		From:   older.Version,
+		To:     newer.Version,
+		Fields: []models.FieldChange{},
+	}
+
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", older.Name, newer.Name},
+		{"default_model", older.DefaultModel, newer.DefaultModel},
+		{"default_temperature", formatTemperature(older.DefaultTemperature), formatTemperature(newer.DefaultTemperature)},
+		{"tags", strings.Join(older.Tags, ","), strings.Join(newer.Tags, ",")},
+		{"merge_strategy", older.MergeStrategy, newer.MergeStrategy},
+	}
+	for _, f := range fields {
+		if f.from != f.to {
+			d.Fields = append(d.Fields, models.FieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+
+	if older.SystemPrompt != newer.SystemPrompt {
+		d.PromptDiff = lineDiff(
+			fmt.Sprintf("%s@%d", older.ID, older.Version), fmt.Sprintf("%s@%d", newer.ID, newer.Version),
+			older.SystemPrompt, newer.SystemPrompt,
+		)
+	}
+	return d
+}
+
+// formatTemperature renders an optional temperature for a field change
+func formatTemperature(t *float64) string {
+	if t == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*t, 'f', -1, 64)
+}
+
+// lineDiff returns a unified-style diff of two texts without hunk headers:
+// unchanged lines are prefixed with a space, removed lines with '-' and
+// added lines with '+'. Prompts are short, so the quadratic longest common
+// subsequence is fine.
+func lineDiff(fromName, toName, from, to string) string {
+	a := strings.Split(from, "\n")
+	b := strings.Split(to, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString(" " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("-" + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+" + b[j] + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,66 @@
+package persona
+
+import (
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected string
+	}{
+		{
+			name:     "changed line",
+			from:     "You are helpful.\nBe brief.",
+			to:       "You are helpful.\nBe thorough.",
+			expected: "--- a\n+++ b\n You are helpful.\n-Be brief.\n+Be thorough.\n",
+		},
+		{
+			name:     "added line",
+			from:     "You are helpful.",
+			to:       "You are helpful.\nCite sources.",
+			expected: "--- a\n+++ b\n You are helpful.\n+Cite sources.\n",
+		},
+		{
+			name:     "removed line",
+			from:     "Be brief.\nYou are helpful.",
+			to:       "You are helpful.",
+			expected: "--- a\n+++ b\n-Be brief.\n You are helpful.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineDiff("a", "b", tt.from, tt.to); got != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiff_Fields(t *testing.T) {
+	older := models.Persona{ID: "p", Version: 1, Name: "A", SystemPrompt: "x", DefaultTemperature: float64Ptr(0.5)}
+	newer := models.Persona{ID: "p", Version: 2, Name: "B", SystemPrompt: "x", Tags: []string{"new"}}
+
+	d := diff(older, newer)
+	if d.PromptDiff != "" {
+		t.Errorf("expected no prompt diff, got %q", d.PromptDiff)
+	}
+
+	expected := map[string][2]string{
+		"name":                {"A", "B"},
+		"default_temperature": {"0.5", ""},
+		"tags":                {"", "new"},
+	}
+	if len(d.Fields) != len(expected) {
+		t.Fatalf("expected %d field changes, got %+v", len(expected), d.Fields)
+	}
+	for _, f := range d.Fields {
+		if want, ok := expected[f.Field]; !ok || want[0] != f.From || want[1] != f.To {
+			t.Errorf("unexpected change %+v", f)
+		}
+	}
+}
@@ -0,0 +1,463 @@
+package persona
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// historyDir is the directory below the persona directory that holds the
+// immutable versions of each persona
+const historyDir = ".history"
+
+// fileState identifies a version of a persona file on disk
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// personaFile is the on-disk format of a persona file. Versions and
+// timestamps live in the history, keeping hand-edited files short.
+type personaFile struct {
+	ID                 string   `json:"id,omitempty" yaml:"id,omitempty"`
+	Name               string   `json:"name" yaml:"name"`
+	SystemPrompt       string   `json:"system_prompt" yaml:"system_prompt"`
+	DefaultModel       string   `json:"default_model,omitempty" yaml:"default_model,omitempty"`
+	DefaultTemperature *float64 `json:"default_temperature,omitempty" yaml:"default_temperature,omitempty"`
+	Tags               []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	MergeStrategy      string   `json:"merge_strategy,omitempty" yaml:"merge_strategy,omitempty"`
+}
+
+// DirStore keeps one persona per JSON or YAML file in a directory, so
+// personas can be version-controlled and edited by hand. The directory is
+// loaded into memory and, with a watch interval, polled for edits. Every
+// change, through the API or on disk, is recorded as a new version in the
+// .history subdirectory.
+type DirStore struct {
+	dir string
+
+	mu       sync.RWMutex
+	personas map[string]models.Persona
+	files    map[string]string // persona ID to file name
+	state    map[string]fileState
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// OpenDirStore loads the personas in dir, creating it if needed. A positive
+// watchInterval reloads the directory whenever its files change.
+func OpenDirStore(dir string, watchInterval time.Duration) (*DirStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("directory storage requires a path")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create persona directory: %w", err)
+	}
+
+	d := &DirStore{dir: dir}
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+
+	if watchInterval > 0 {
+		d.stop = make(chan struct{})
+		d.done = make(chan struct{})
+		go d.watch(watchInterval)
+	}
+	return d, nil
+}
+
+// watch polls the directory and reloads it when any file has changed
+func (d *DirStore) watch(interval time.Duration) {
+	defer close(d.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			state, err := d.scan()
+			if err != nil {
+				log.Printf("Failed to scan persona directory: %v", err)
+				continue
+			}
+
+			d.mu.RLock()
+			changed := !sameState(state, d.state)
+			d.mu.RUnlock()
+
+			if changed {
+				if err := d.reload(); err != nil {
+					log.Printf("Failed to reload persona directory: %v", err)
+					continue
+				}
+				log.Printf("Reloaded personas from %s", d.dir)
+			}
+		}
+	}
+}
+
+// scan returns the state of every persona file in the directory
+func (d *DirStore) scan() (map[string]fileState, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]fileState)
+	for _, entry := range entries {
+		if entry.IsDir() || !isPersonaFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		state[entry.Name()] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return state, nil
+}
+
+// reload replaces the in-memory personas with the directory contents,
+// recording a new version for every file that differs from its latest
+// version. Invalid files are logged and skipped so one bad edit does not
+// take every persona down.
+func (d *DirStore) reload() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, err := d.scan()
+	if err != nil {
+		return fmt.Errorf("failed to read persona directory: %w", err)
+	}
+
+	names := make([]string, 0, len(state))
+	for name := range state {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	personas := make(map[string]models.Persona, len(names))
+	files := make(map[string]string, len(names))
+	for _, name := range names {
+		p, err := d.readFile(name)
+		if err != nil {
+			log.Printf("Skipping persona file %s: %v", name, err)
+			continue
+		}
+		if other, ok := files[p.ID]; ok {
+			log.Printf("Skipping persona file %s: id %q already defined in %s", name, p.ID, other)
+			continue
+		}
+
+		p, err = d.syncHistory(p, state[name].modTime)
+		if err != nil {
+			log.Printf("Skipping persona file %s: %v", name, err)
+			continue
+		}
+		personas[p.ID] = p
+		files[p.ID] = name
+	}
+
+	d.personas = personas
+	d.files = files
+	d.state = state
+	return nil
+}
+
+// syncHistory returns p with its version and timestamps taken from the
+// history, recording p as a new version if its content has changed.
+// Callers must hold d.mu.
+func (d *DirStore) syncHistory(p models.Persona, modTime time.Time) (models.Persona, error) {
+	versions, err := d.readHistory(p.ID)
+	if err != nil {
+		return models.Persona{}, err
+	}
+
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		if sameContent(latest, p) {
+			return latest, nil
+		}
+		p.Version = latest.Version + 1
+		p.CreatedAt = versions[0].CreatedAt
+	} else {
+		p.Version = 1
+		p.CreatedAt = modTime.UTC()
+	}
+	p.UpdatedAt = modTime.UTC()
+
+	if err := d.writeHistory(p); err != nil {
+		return models.Persona{}, err
+	}
+	return p, nil
+}
+
+// readFile parses and validates a persona file. The ID defaults to the
+// file name without its extension.
+func (d *DirStore) readFile(name string) (models.Persona, error) {
+	data, err := os.ReadFile(filepath.Join(d.dir, name))
+	if err != nil {
+		return models.Persona{}, err
+	}
+
+	var f personaFile
+	if filepath.Ext(name) == ".json" {
+		err = json.Unmarshal(data, &f)
+	} else {
+		err = yaml.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return models.Persona{}, err
+	}
+
+	p := models.Persona{
+		ID:                 f.ID,
+		Name:               f.Name,
+		SystemPrompt:       f.SystemPrompt,
+		DefaultModel:       f.DefaultModel,
+		DefaultTemperature: f.DefaultTemperature,
+		Tags:               f.Tags,
+		MergeStrategy:      f.MergeStrategy,
+	}
+	if p.ID == "" {
+		p.ID = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if err := Validate(p); err != nil {
+		return models.Persona{}, err
+	}
+	return p, nil
+}
+
+// readHistory returns the recorded versions of a persona, oldest first
+func (d *DirStore) readHistory(id string) ([]models.Persona, error) {
+	entries, err := os.ReadDir(filepath.Join(d.dir, historyDir, id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []models.Persona
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".json")); err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(d.dir, historyDir, id, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var p models.Persona
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("history %s/%s: %w", id, entry.Name(), err)
+		}
+		versions = append(versions, p)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+// writeHistory records p as an immutable version
+func (d *DirStore) writeHistory(p models.Persona) error {
+	dir := filepath.Join(d.dir, historyDir, p.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, strconv.Itoa(p.Version)+".json"), data)
+}
+
+// List returns the latest version of every persona in no particular order
+func (d *DirStore) List() ([]models.Persona, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	personas := make([]models.Persona, 0, len(d.personas))
+	for _, p := range d.personas {
+		personas = append(personas, clone(p))
+	}
+	return personas, nil
+}
+
+// Get returns the latest version of the persona with the given ID
+func (d *DirStore) Get(id string) (models.Persona, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	p, ok := d.personas[id]
+	if !ok {
+		return models.Persona{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return clone(p), nil
+}
+
+// GetVersion returns a specific version of a persona
+func (d *DirStore) GetVersion(id string, version int) (models.Persona, error) {
+	versions, err := d.Versions(id)
+	if err != nil {
+		return models.Persona{}, err
+	}
+	for _, p := range versions {
+		if p.Version == version {
+			return p, nil
+		}
+	}
+	return models.Persona{}, fmt.Errorf("%w: %s@%d", ErrNotFound, id, version)
+}
+
+// Versions returns every version of a persona, oldest first
+func (d *DirStore) Versions(id string) ([]models.Persona, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if _, ok := d.personas[id]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return d.readHistory(id)
+}
+
+// Put records p as the latest version and writes it to its existing file,
+// keeping the file's format, or to a new <id>.yaml file. The ID is always
+// written, as the file name need not match it.
+func (d *DirStore) Put(p models.Persona) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	name, ok := d.files[p.ID]
+	if !ok {
+		name = p.ID + ".yaml"
+	}
+
+	f := personaFile{
+		ID:                 p.ID,
+		Name:               p.Name,
+		SystemPrompt:       p.SystemPrompt,
+		DefaultModel:       p.DefaultModel,
+		DefaultTemperature: p.DefaultTemperature,
+		Tags:               p.Tags,
+		MergeStrategy:      p.MergeStrategy,
+	}
+	var data []byte
+	var err error
+	if filepath.Ext(name) == ".json" {
+		data, err = json.MarshalIndent(f, "", "  ")
+	} else {
+		data, err = yaml.Marshal(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Write the history first so a concurrent reload sees a file that
+	// matches its latest version
+	if err := d.writeHistory(p); err != nil {
+		return fmt.Errorf("failed to write persona history: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(d.dir, name), data); err != nil {
+		return fmt.Errorf("failed to write persona file: %w", err)
+	}
+
+	d.personas[p.ID] = clone(p)
+	d.files[p.ID] = name
+	if info, err := os.Stat(filepath.Join(d.dir, name)); err == nil {
+		d.state[name] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return nil
+}
+
+// Delete removes the persona's file and history
+func (d *DirStore) Delete(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	name, ok := d.files[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err := os.Remove(filepath.Join(d.dir, name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete persona file: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(d.dir, historyDir, id)); err != nil {
+		return fmt.Errorf("failed to delete persona history: %w", err)
+	}
+
+	delete(d.personas, id)
+	delete(d.files, id)
+	delete(d.state, name)
+	return nil
+}
+
+// Close stops watching the directory
+func (d *DirStore) Close() error {
+	if d.stop != nil {
+		close(d.stop)
+		<-d.done
+		d.stop = nil
+	}
+	return nil
+}
+
+// isPersonaFile reports whether name has a persona file extension
+func isPersonaFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".json", ".yaml", ".yml":
+		return !strings.HasPrefix(name, ".")
+	default:
+		return false
+	}
+}
+
+// sameState reports whether two directory scans are identical
+func sameState(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, sa := range a {
+		sb, ok := b[name]
+		if !ok || !sa.modTime.Equal(sb.modTime) || sa.size != sb.size {
+			return false
+		}
+	}
+	return true
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path,
+// so the watcher and editors never observe a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".persona-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
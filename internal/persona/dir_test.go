@@ -0,0 +1,180 @@
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirStore(t *testing.T) {
+	store, err := OpenDirStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("OpenDirStore failed: %v", err)
+	}
+	defer store.Close()
+
+	testStore(t, store)
+}
+
+func TestDirStore_LoadsFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"reviewer.yaml": "name: Reviewer\nsystem_prompt: Review code.\ntags: [engineering]\n",
+		"writer.json":   `{"id": "writer", "name": "Writer", "system_prompt": "Write docs."}`,
+		"broken.yaml":   "name: [unterminated\n",
+		"invalid.yml":   "name: Missing prompt\n",
+		"notes.txt":     "not a persona",
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	store, err := OpenDirStore(dir, 0)
+	if err != nil {
+		t.Fatalf("OpenDirStore failed: %v", err)
+	}
+	defer store.Close()
+
+	list, _ := store.List()
+	if len(list) != 2 {
+		t.Fatalf("expected 2 valid personas, got %+v", list)
+	}
+
+	reviewer, err := store.Get("reviewer")
+	if err != nil {
+		t.Fatalf("expected ID from the file name, got %v", err)
+	}
+	if reviewer.SystemPrompt != "Review code." || reviewer.UpdatedAt.IsZero() {
+		t.Errorf("unexpected persona %+v", reviewer)
+	}
+
+	// Updates keep the file's format
+	writer, _ := store.Get("writer")
+	writer.SystemPrompt = "Write better docs."
+	if err := store.Put(writer); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "writer.json"))
+	if !strings.Contains(string(data), `"system_prompt": "Write better docs."`) {
+		t.Errorf("expected writer.json to be rewritten as JSON, got %s", data)
+	}
+}
+
+func TestDirStore_Watch(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenDirStore(dir, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("OpenDirStore failed: %v", err)
+	}
+	defer store.Close()
+
+	os.WriteFile(filepath.Join(dir, "reviewer.yaml"), []byte("name: Reviewer\nsystem_prompt: Review code.\n"), 0644)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := store.Get("reviewer"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected new persona file to be picked up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	os.Remove(filepath.Join(dir, "reviewer.yaml"))
+	for {
+		if _, err := store.Get("reviewer"); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected removed persona file to be dropped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDirStore_HandEditsCreateVersions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reviewer.yaml")
+	os.WriteFile(path, []byte("name: Reviewer\nsystem_prompt: Review code.\n"), 0644)
+
+	store, err := OpenDirStore(dir, 0)
+	if err != nil {
+		t.Fatalf("OpenDirStore failed: %v", err)
+	}
+	defer store.Close()
+
+	p, _ := store.Get("reviewer")
+	if p.Version != 1 {
+		t.Fatalf("expected version 1, got %d", p.Version)
+	}
+
+	// Reloading unchanged files keeps the version
+	store.reload()
+	if p, _ := store.Get("reviewer"); p.Version != 1 {
+		t.Errorf("expected unchanged file to stay at version 1, got %d", p.Version)
+	}
+
+	os.WriteFile(path, []byte("name: Reviewer\nsystem_prompt: Review code carefully.\n"), 0644)
+	store.reload()
+
+	p, _ = store.Get("reviewer")
+	if p.Version != 2 || p.SystemPrompt != "Review code carefully." {
+		t.Errorf("expected the edit to become version 2, got %+v", p)
+	}
+
+	v1, err := store.GetVersion("reviewer", 1)
+	if err != nil || v1.SystemPrompt != "Review code." {
+		t.Errorf("expected version 1 to keep the original prompt, got %+v (%v)", v1, err)
+	}
+
+	// History survives a restart
+	store.Close()
+	store, err = OpenDirStore(dir, 0)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if p, _ := store.Get("reviewer"); p.Version != 2 {
+		t.Errorf("expected version 2 after reopening, got %d", p.Version)
+	}
+}
+
+func TestDirStore_PutKeepsID(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "helpdesk-v2.yaml"), []byte("id: helpdesk\nname: Helpdesk\nsystem_prompt: Help users.\n"), 0644)
+
+	store, err := OpenDirStore(dir, 0)
+	if err != nil {
+		t.Fatalf("OpenDirStore failed: %v", err)
+	}
+
+	p, err := store.Get("helpdesk")
+	if err != nil {
+		t.Fatalf("expected ID from the file, got %v", err)
+	}
+	p.Version++
+	p.SystemPrompt = "Help users politely."
+	if err := store.Put(p); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	store.Close()
+
+	store, err = OpenDirStore(dir, 0)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+
+	p, err = store.Get("helpdesk")
+	if err != nil {
+		t.Fatalf("expected helpdesk after reopening, got %v", err)
+	}
+	if p.Version != 2 || p.SystemPrompt != "Help users politely." {
+		t.Errorf("expected the update as version 2, got %+v", p)
+	}
+	if _, err := store.Get("helpdesk-v2"); err == nil {
+		t.Errorf("expected no persona named after the file")
+	}
+}
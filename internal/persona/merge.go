@@ -0,0 +1,37 @@
+package persona
+
+import (
+	"fmt"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// validMergeStrategy reports whether s names a known merge strategy
+func validMergeStrategy(s string) bool {
+	switch s {
+	case models.MergePrepend, models.MergeAppend, models.MergeReplace, models.MergeSeparate, models.MergeReject:
+		return true
+	}
+	return false
+}
+
+// CheckMerge validates req.PersonaMerge before the request is forwarded.
+// Requests using the reject strategy may not carry system messages of
+// their own.
+func CheckMerge(req *models.ChatCompletionRequest) error {
+	if req.PersonaMerge == "" {
+		return nil
+	}
+	if !validMergeStrategy(req.PersonaMerge) {
+		return fmt.Errorf("%w: unknown persona_merge %q", ErrInvalid, req.PersonaMerge)
+	}
+
+	if req.PersonaMerge == models.MergeReject && req.PersonaPrompt != "" {
+		for _, msg := range req.Messages {
+			if msg.Role == "system" {
+				return ErrSystemRejected
+			}
+		}
+	}
+	return nil
+}
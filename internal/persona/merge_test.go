@@ -0,0 +1,41 @@
+package persona
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestCheckMerge(t *testing.T) {
+	withSystem := []models.ChatMessage{{Role: "system", Content: "Be terse."}, {Role: "user", Content: "Hello"}}
+	withoutSystem := []models.ChatMessage{{Role: "user", Content: "Hello"}}
+
+	tests := []struct {
+		name     string
+		merge    string
+		prompt   string
+		messages []models.ChatMessage
+		err      error
+	}{
+		{name: "default", prompt: "Persona", messages: withSystem},
+		{name: "append", merge: models.MergeAppend, prompt: "Persona", messages: withSystem},
+		{name: "unknown", merge: "interleave", prompt: "Persona", messages: withSystem, err: ErrInvalid},
+		{name: "reject with system message", merge: models.MergeReject, prompt: "Persona", messages: withSystem, err: ErrSystemRejected},
+		{name: "reject without system message", merge: models.MergeReject, prompt: "Persona", messages: withoutSystem},
+		{name: "reject without persona", merge: models.MergeReject, messages: withSystem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.ChatCompletionRequest{PersonaPrompt: tt.prompt, PersonaMerge: tt.merge, Messages: tt.messages}
+			err := CheckMerge(req)
+			if tt.err == nil && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package persona
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Registry errors
+var (
+	ErrNotFound = errors.New("persona not found")
+	ErrExists   = errors.New("persona already exists")
+	ErrInvalid  = errors.New("invalid persona")
+	ErrConflict = errors.New("persona_id and persona_prompt are mutually exclusive")
+
+	ErrVersionMismatch = errors.New("persona version mismatch")
+	ErrSystemRejected  = errors.New("persona does not allow caller system messages")
+)
+
+// idPattern restricts persona IDs to URL and file name safe characters
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// Registry manages personas on top of a Store
+type Registry struct {
+	mu    sync.Mutex // serialises writes so existence checks hold
+	store Store
+	now   func() time.Time
+}
+
+// NewRegistry creates an empty registry backed by memory
+func NewRegistry() *Registry {
+	return NewRegistryWithStore(NewMemoryStore())
+}
+
+// NewRegistryWithStore creates a registry backed by store
+func NewRegistryWithStore(store Store) *Registry {
+	return &Registry{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// List returns the personas sorted by ID. A non-empty tag limits the list
+// to personas carrying it.
+func (r *Registry) List(tag string) ([]models.Persona, error) {
+	all, err := r.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	personas := make([]models.Persona, 0, len(all))
+	for _, p := range all {
+		if tag == "" || hasTag(p, tag) {
+			personas = append(personas, p)
+		}
+	}
+	sort.Slice(personas, func(i, j int) bool { return personas[i].ID < personas[j].ID })
+	return personas, nil
+}
+
+// Get returns the persona referenced by ref, either "<id>" for the latest
+// version or "<id>@<version>" for a pinned one
+func (r *Registry) Get(ref string) (models.Persona, error) {
+	id, version, err := ParseRef(ref)
+	if err != nil {
+		return models.Persona{}, err
+	}
+	if version == 0 {
+		return r.store.Get(id)
+	}
+	return r.store.GetVersion(id, version)
+}
+
+// Versions returns every version of a persona, oldest first
+func (r *Registry) Versions(id string) ([]models.Persona, error) {
+	return r.store.Versions(id)
+}
+
+// Diff compares two versions of a persona. A zero to compares against the
+// latest version and a zero from against the version before to.
+func (r *Registry) Diff(id string, from, to int) (models.PersonaDiff, error) {
+	newer, err := r.version(id, to)
+	if err != nil {
+		return models.PersonaDiff{}, err
+	}
+	if from == 0 {
+		if newer.Version <= 1 {
+			return models.PersonaDiff{}, fmt.Errorf("%w: %s has no version before %d", ErrInvalid, id, newer.Version)
+		}
+		from = newer.Version - 1
+	}
+	older, err := r.version(id, from)
+	if err != nil {
+		return models.PersonaDiff{}, err
+	}
+
+	return diff(older, newer), nil
+}
+
+// Rollback makes the content of version the latest version again. The
+// history is kept: the restored content is recorded as a new version.
+func (r *Registry) Rollback(id string, version int) (models.Persona, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, err := r.store.GetVersion(id, version)
+	if err != nil {
+		return models.Persona{}, err
+	}
+	latest, err := r.store.Get(id)
+	if err != nil {
+		return models.Persona{}, err
+	}
+
+	return r.putVersion(target, latest)
+}
+
+// version returns a specific version of a persona, or the latest for zero
+func (r *Registry) version(id string, version int) (models.Persona, error) {
+	if version == 0 {
+		return r.store.Get(id)
+	}
+	return r.store.GetVersion(id, version)
+}
+
+// Create adds a new persona and returns it with its timestamps set
+func (r *Registry) Create(p models.Persona) (models.Persona, error) {
+	if err := Validate(p); err != nil {
+		return models.Persona{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.store.Get(p.ID); err == nil {
+		return models.Persona{}, fmt.Errorf("%w: %s", ErrExists, p.ID)
+	} else if !errors.Is(err, ErrNotFound) {
+		return models.Persona{}, err
+	}
+
+	p = clone(p)
+	p.Version = 1
+	p.CreatedAt = r.now().UTC()
+	p.UpdatedAt = p.CreatedAt
+	if err := r.store.Put(p); err != nil {
+		return models.Persona{}, err
+	}
+	return clone(p), nil
+}
+
+// Update records p as a new version of an existing persona. A non-zero
+// p.Version must match the current version, guarding against lost updates.
+// Updates that change nothing return the current version.
+func (r *Registry) Update(p models.Persona) (models.Persona, error) {
+	if err := Validate(p); err != nil {
+		return models.Persona{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest, err := r.store.Get(p.ID)
+	if err != nil {
+		return models.Persona{}, err
+	}
+	if p.Version != 0 && p.Version != latest.Version {
+		return models.Persona{}, fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionMismatch, p.ID, latest.Version, p.Version)
+	}
+
+	return r.putVersion(p, latest)
+}
+
+// putVersion stores the content of p as the version after latest, unless
+// the content is unchanged. Callers must hold r.mu.
+func (r *Registry) putVersion(p, latest models.Persona) (models.Persona, error) {
+	if sameContent(p, latest) {
+		return latest, nil
+	}
+
+	p = clone(p)
+	p.Version = latest.Version + 1
+	p.CreatedAt = latest.CreatedAt
+	p.UpdatedAt = r.now().UTC()
+	if err := r.store.Put(p); err != nil {
+		return models.Persona{}, err
+	}
+	return clone(p), nil
+}
+
+// Delete removes a persona
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store.Delete(id)
+}
+
+// Close closes the underlying store
+func (r *Registry) Close() error {
+	return r.store.Close()
+}
+
+// Apply resolves req.PersonaID, which may pin a version as
+// "<id>@<version>": the persona's system prompt becomes the request's
+// persona prompt and its defaults fill in an empty model and temperature.
+// The prompt is rendered with req.PersonaVars, and a persona's merge
+// strategy cannot be overridden by the request. It returns the applied
+// persona; requests without a persona ID are left untouched and return a
+// zero persona.
+func (r *Registry) Apply(req *models.ChatCompletionRequest) (models.Persona, error) {
+	if req.PersonaID == "" {
+		return models.Persona{}, nil
+	}
+	if req.PersonaPrompt != "" {
+		return models.Persona{}, ErrConflict
+	}
+
+	p, err := r.Get(req.PersonaID)
+	if err != nil {
+		return models.Persona{}, err
+	}
+
+	if p.MergeStrategy != "" {
+		if req.PersonaMerge != "" && req.PersonaMerge != p.MergeStrategy {
+			return models.Persona{}, fmt.Errorf("%w: persona %s requires persona_merge %q", ErrConflict, p.ID, p.MergeStrategy)
+		}
+		req.PersonaMerge = p.MergeStrategy
+	}
+
+	req.PersonaPrompt = p.SystemPrompt
+	if err := RenderPrompt(req, r.now()); err != nil {
+		return models.Persona{}, err
+	}
+	if req.Model == "" {
+		req.Model = p.DefaultModel
+	}
+	if req.Temperature == nil && p.DefaultTemperature != nil {
+		temperature := *p.DefaultTemperature
+		req.Temperature = &temperature
+	}
+	return p, nil
+}
+
+// ParseRef splits a persona reference into its ID and pinned version. The
+// version is zero when the reference names the latest version.
+func ParseRef(ref string) (string, int, error) {
+	id, v, pinned := strings.Cut(ref, "@")
+	if !pinned {
+		return id, 0, nil
+	}
+
+	version, err := strconv.Atoi(v)
+	if err != nil || version < 1 {
+		return "", 0, fmt.Errorf("%w: invalid version in %q", ErrInvalid, ref)
+	}
+	return id, version, nil
+}
+
+// Validate checks the fields of a persona
+func Validate(p models.Persona) error {
+	if !idPattern.MatchString(p.ID) {
+		return fmt.Errorf("%w: id must be 1-64 lowercase letters, digits, '.', '_' or '-'", ErrInvalid)
+	}
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if p.SystemPrompt == "" {
+		return fmt.Errorf("%w: system_prompt is required", ErrInvalid)
+	}
+	if err := checkTemplate(p.SystemPrompt); err != nil {
+		return err
+	}
+	if t := p.DefaultTemperature; t != nil && (*t < 0 || *t > 2) {
+		return fmt.Errorf("%w: default_temperature must be between 0 and 2", ErrInvalid)
+	}
+	if p.MergeStrategy != "" && !validMergeStrategy(p.MergeStrategy) {
+		return fmt.Errorf("%w: unknown merge_strategy %q", ErrInvalid, p.MergeStrategy)
+	}
+	return nil
+}
+
+// sameContent reports whether two personas differ only in their version
+// metadata
+func sameContent(a, b models.Persona) bool {
+	if a.Name != b.Name || a.SystemPrompt != b.SystemPrompt || a.DefaultModel != b.DefaultModel || a.MergeStrategy != b.MergeStrategy {
+		return false
+	}
+	if (a.DefaultTemperature == nil) != (b.DefaultTemperature == nil) {
+		return false
+	}
+	if a.DefaultTemperature != nil && *a.DefaultTemperature != *b.DefaultTemperature {
+		return false
+	}
+	if len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for i := range a.Tags {
+		if a.Tags[i] != b.Tags[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTag reports whether p carries tag
+func hasTag(p models.Persona, tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// clone returns a deep copy of p so callers cannot modify stored personas
+func clone(p models.Persona) models.Persona {
+	p.Tags = append([]string(nil), p.Tags...)
+	if p.DefaultTemperature != nil {
+		t := *p.DefaultTemperature
+		p.DefaultTemperature = &t
+	}
+	return p
+}
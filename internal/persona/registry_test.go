@@ -0,0 +1,334 @@
+package persona
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func testPersona(id string) models.Persona {
+	return models.Persona{
+		ID:           id,
+		Name:         "Reviewer",
+		SystemPrompt: "You are a meticulous code reviewer.",
+		DefaultModel: "gpt-4o",
+		Tags:         []string{"engineering"},
+	}
+}
+
+func TestRegistry_CRUD(t *testing.T) {
+	r := NewRegistry()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	created, err := r.Create(testPersona("reviewer"))
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !created.CreatedAt.Equal(now) || !created.UpdatedAt.Equal(now) {
+		t.Errorf("expected timestamps to be set, got %+v", created)
+	}
+
+	if _, err := r.Create(testPersona("reviewer")); !errors.Is(err, ErrExists) {
+		t.Errorf("expected ErrExists, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	update := testPersona("reviewer")
+	update.SystemPrompt = "You are a kind code reviewer."
+	updated, err := r.Update(update)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) || !updated.UpdatedAt.Equal(now) {
+		t.Errorf("expected creation time kept and update time bumped, got %+v", updated)
+	}
+	if created.Version != 1 || updated.Version != 2 {
+		t.Errorf("expected versions 1 and 2, got %d and %d", created.Version, updated.Version)
+	}
+
+	got, err := r.Get("reviewer")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.SystemPrompt != "You are a kind code reviewer." {
+		t.Errorf("expected updated prompt, got %q", got.SystemPrompt)
+	}
+
+	// Returned personas are copies
+	got.Tags[0] = "changed"
+	if again, _ := r.Get("reviewer"); again.Tags[0] != "engineering" {
+		t.Error("expected stored persona to be unaffected by caller changes")
+	}
+
+	if err := r.Delete("reviewer"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := r.Get("reviewer"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := r.Delete("reviewer"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+	if _, err := r.Update(update); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound updating a missing persona, got %v", err)
+	}
+}
+
+func TestRegistry_List(t *testing.T) {
+	r := NewRegistry()
+	r.Create(testPersona("writer"))
+	r.Create(testPersona("reviewer"))
+	support := testPersona("support")
+	support.Tags = []string{"customer"}
+	r.Create(support)
+
+	all, _ := r.List("")
+	if len(all) != 3 || all[0].ID != "reviewer" || all[2].ID != "writer" {
+		t.Errorf("expected 3 personas sorted by ID, got %+v", all)
+	}
+
+	tagged, _ := r.List("customer")
+	if len(tagged) != 1 || tagged[0].ID != "support" {
+		t.Errorf("expected only the support persona, got %+v", tagged)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *models.Persona)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(p *models.Persona) {}},
+		{name: "missing id", modify: func(p *models.Persona) { p.ID = "" }, wantErr: true},
+		{name: "uppercase id", modify: func(p *models.Persona) { p.ID = "Reviewer" }, wantErr: true},
+		{name: "id with version", modify: func(p *models.Persona) { p.ID = "reviewer@2" }, wantErr: true},
+		{name: "missing name", modify: func(p *models.Persona) { p.Name = "" }, wantErr: true},
+		{name: "missing prompt", modify: func(p *models.Persona) { p.SystemPrompt = "" }, wantErr: true},
+		{name: "temperature out of range", modify: func(p *models.Persona) { p.DefaultTemperature = float64Ptr(3) }, wantErr: true},
+		{name: "template", modify: func(p *models.Persona) { p.SystemPrompt = "Hello {{.user_name}}, today is {{date}}." }},
+		{name: "broken template", modify: func(p *models.Persona) { p.SystemPrompt = "Hello {{.user_name" }, wantErr: true},
+		{name: "template with range", modify: func(p *models.Persona) { p.SystemPrompt = "{{range .x}}x{{end}}" }, wantErr: true},
+		{name: "merge strategy", modify: func(p *models.Persona) { p.MergeStrategy = models.MergeReject }},
+		{name: "unknown merge strategy", modify: func(p *models.Persona) { p.MergeStrategy = "interleave" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPersona("reviewer")
+			tt.modify(&p)
+
+			err := Validate(p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("expected ErrInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRegistry_Apply(t *testing.T) {
+	r := NewRegistry()
+	p := testPersona("reviewer")
+	p.DefaultTemperature = float64Ptr(0.2)
+	r.Create(p)
+
+	req := &models.ChatCompletionRequest{PersonaID: "reviewer"}
+	applied, err := r.Apply(req)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if applied.ID != "reviewer" || applied.Version != 1 {
+		t.Errorf("expected reviewer@1 to be applied, got %s@%d", applied.ID, applied.Version)
+	}
+	if req.PersonaPrompt != p.SystemPrompt {
+		t.Errorf("expected persona prompt %q, got %q", p.SystemPrompt, req.PersonaPrompt)
+	}
+	if req.Model != "gpt-4o" {
+		t.Errorf("expected default model gpt-4o, got %q", req.Model)
+	}
+	if req.Temperature == nil || *req.Temperature != 0.2 {
+		t.Errorf("expected default temperature 0.2, got %v", req.Temperature)
+	}
+
+	// Request values win over persona defaults
+	req = &models.ChatCompletionRequest{PersonaID: "reviewer", Model: "llama3", Temperature: float64Ptr(0.9)}
+	r.Apply(req)
+	if req.Model != "llama3" || *req.Temperature != 0.9 {
+		t.Errorf("expected request values to be kept, got %q and %v", req.Model, *req.Temperature)
+	}
+
+	if _, err := r.Apply(&models.ChatCompletionRequest{PersonaID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	_, err = r.Apply(&models.ChatCompletionRequest{PersonaID: "reviewer", PersonaPrompt: "Be brief."})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestRegistry_Versioning(t *testing.T) {
+	r := NewRegistry()
+	r.Create(testPersona("reviewer"))
+
+	v2 := testPersona("reviewer")
+	v2.SystemPrompt = "You are a meticulous code reviewer.\nFocus on security."
+	v2.DefaultModel = "llama3"
+	if _, err := r.Update(v2); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// Unchanged content does not create a version
+	same, err := r.Update(v2)
+	if err != nil || same.Version != 2 {
+		t.Errorf("expected no-op update to stay at version 2, got %d (%v)", same.Version, err)
+	}
+
+	// A stale version is rejected
+	stale := v2
+	stale.Version = 1
+	stale.Name = "Stale"
+	if _, err := r.Update(stale); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch, got %v", err)
+	}
+
+	pinned, err := r.Get("reviewer@1")
+	if err != nil || pinned.DefaultModel != "gpt-4o" {
+		t.Errorf("expected pinned version 1, got %+v (%v)", pinned, err)
+	}
+	if _, err := r.Get("reviewer@x"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for a bad version, got %v", err)
+	}
+
+	req := &models.ChatCompletionRequest{PersonaID: "reviewer@1"}
+	applied, err := r.Apply(req)
+	if err != nil || applied.Version != 1 || req.Model != "gpt-4o" {
+		t.Errorf("expected version 1 to be applied, got %+v (%v)", applied, err)
+	}
+
+	d, err := r.Diff("reviewer", 0, 0)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if d.From != 1 || d.To != 2 {
+		t.Errorf("expected diff from 1 to 2, got %d to %d", d.From, d.To)
+	}
+	if len(d.Fields) != 1 || d.Fields[0].Field != "default_model" || d.Fields[0].To != "llama3" {
+		t.Errorf("unexpected field changes %+v", d.Fields)
+	}
+	if d.PromptDiff == "" {
+		t.Error("expected a prompt diff")
+	}
+
+	rolledBack, err := r.Rollback("reviewer", 1)
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if rolledBack.Version != 3 || rolledBack.DefaultModel != "gpt-4o" {
+		t.Errorf("expected version 3 with the content of version 1, got %+v", rolledBack)
+	}
+
+	versions, _ := r.Versions("reviewer")
+	if len(versions) != 3 {
+		t.Errorf("expected 3 versions after rollback, got %d", len(versions))
+	}
+
+	if _, err := r.Rollback("reviewer", 7); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound rolling back to a missing version, got %v", err)
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		id      string
+		version int
+		wantErr bool
+	}{
+		{ref: "reviewer", id: "reviewer"},
+		{ref: "reviewer@3", id: "reviewer", version: 3},
+		{ref: "reviewer@0", wantErr: true},
+		{ref: "reviewer@latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			id, version, err := ParseRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if id != tt.id || version != tt.version {
+				t.Errorf("expected %s@%d, got %s@%d", tt.id, tt.version, id, version)
+			}
+		})
+	}
+}
+
+func TestRegistry_ApplyTemplate(t *testing.T) {
+	r := NewRegistry()
+	r.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	p := testPersona("assistant")
+	p.SystemPrompt = "Help {{.user_name}}. Reply in {{var \"locale\" \"en-US\"}}. Today is {{date}}."
+	if _, err := r.Create(p); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	req := &models.ChatCompletionRequest{PersonaID: "assistant", PersonaVars: map[string]string{"user_name": "Ada"}}
+	if _, err := r.Apply(req); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if expected := "Help Ada. Reply in en-US. Today is 2024-03-01."; req.PersonaPrompt != expected {
+		t.Errorf("expected %q, got %q", expected, req.PersonaPrompt)
+	}
+
+	_, err := r.Apply(&models.ChatCompletionRequest{PersonaID: "assistant"})
+	if !errors.Is(err, ErrMissingVars) {
+		t.Errorf("expected ErrMissingVars, got %v", err)
+	}
+}
+
+func TestRegistry_ApplyMergeStrategy(t *testing.T) {
+	r := NewRegistry()
+	locked := testPersona("locked")
+	locked.MergeStrategy = models.MergeReject
+	r.Create(locked)
+	r.Create(testPersona("open"))
+
+	req := &models.ChatCompletionRequest{PersonaID: "locked"}
+	if _, err := r.Apply(req); err != nil || req.PersonaMerge != models.MergeReject {
+		t.Errorf("expected the persona's strategy to be applied, got %q (%v)", req.PersonaMerge, err)
+	}
+
+	req = &models.ChatCompletionRequest{PersonaID: "locked", PersonaMerge: models.MergePrepend}
+	if _, err := r.Apply(req); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict overriding the persona's strategy, got %v", err)
+	}
+
+	req = &models.ChatCompletionRequest{PersonaID: "open", PersonaMerge: models.MergeAppend}
+	if _, err := r.Apply(req); err != nil || req.PersonaMerge != models.MergeAppend {
+		t.Errorf("expected the request's strategy to be kept, got %q (%v)", req.PersonaMerge, err)
+	}
+}
+
+func TestRegistry_DiffFirstVersion(t *testing.T) {
+	r := NewRegistry()
+	r.Create(testPersona("reviewer"))
+
+	if _, err := r.Diff("reviewer", 0, 0); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid diffing the only version, got %v", err)
+	}
+	if _, err := r.Diff("reviewer", 0, 1); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid diffing version 1 against its predecessor, got %v", err)
+	}
+}
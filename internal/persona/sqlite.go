@@ -0,0 +1,173 @@
+package persona
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// SQLiteStore keeps personas in an embedded SQLite database. The personas
+// table holds the latest version of each persona, persona_versions the full
+// history.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens or creates the database at path
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite storage requires a path")
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open persona database: %w", err)
+	}
+	// SQLite allows a single writer; serialising access avoids SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS personas (
+			id   TEXT PRIMARY KEY,
+			data TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS persona_versions (
+			id      TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			data    TEXT NOT NULL,
+			PRIMARY KEY (id, version)
+		)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create persona tables: %w", err)
+		}
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// List returns the latest version of every persona ordered by ID
+func (s *SQLiteStore) List() ([]models.Persona, error) {
+	return s.query(`SELECT data FROM personas ORDER BY id`)
+}
+
+// Versions returns every version of a persona, oldest first
+func (s *SQLiteStore) Versions(id string) ([]models.Persona, error) {
+	versions, err := s.query(`SELECT data FROM persona_versions WHERE id = ? ORDER BY version`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return versions, nil
+}
+
+// query decodes the personas stored in the data column of a result set
+func (s *SQLiteStore) query(query string, args ...interface{}) ([]models.Persona, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var personas []models.Persona
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var p models.Persona
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			return nil, err
+		}
+		personas = append(personas, p)
+	}
+	return personas, rows.Err()
+}
+
+// Get returns the latest version of the persona with the given ID
+func (s *SQLiteStore) Get(id string) (models.Persona, error) {
+	return s.queryOne(id, `SELECT data FROM personas WHERE id = ?`, id)
+}
+
+// GetVersion returns a specific version of a persona
+func (s *SQLiteStore) GetVersion(id string, version int) (models.Persona, error) {
+	return s.queryOne(fmt.Sprintf("%s@%d", id, version),
+		`SELECT data FROM persona_versions WHERE id = ? AND version = ?`, id, version)
+}
+
+// queryOne decodes the single persona selected by query, reporting ref in
+// the not found error
+func (s *SQLiteStore) queryOne(ref, query string, args ...interface{}) (models.Persona, error) {
+	var data string
+	err := s.db.QueryRow(query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Persona{}, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	if err != nil {
+		return models.Persona{}, err
+	}
+
+	var p models.Persona
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		return models.Persona{}, err
+	}
+	return p, nil
+}
+
+// Put adds p as the latest version of its persona
+func (s *SQLiteStore) Put(p models.Persona) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO persona_versions (id, version, data) VALUES (?, ?, ?)`,
+		p.ID, p.Version, string(data)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO personas (id, data) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data`, p.ID, string(data)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete removes every version of the persona with the given ID
+func (s *SQLiteStore) Delete(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM personas WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if _, err := tx.Exec(`DELETE FROM persona_versions WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,44 @@
+package persona
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "personas.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore failed: %v", err)
+	}
+	defer store.Close()
+
+	testStore(t, store)
+}
+
+func TestSQLiteStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "personas.db")
+
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore failed: %v", err)
+	}
+	registry := NewRegistryWithStore(store)
+	if _, err := registry.Create(testPersona("reviewer")); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	registry.Close()
+
+	store, err = OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+
+	p, err := store.Get("reviewer")
+	if err != nil {
+		t.Fatalf("expected persona to survive reopening, got %v", err)
+	}
+	if p.CreatedAt.IsZero() || len(p.Tags) != 1 {
+		t.Errorf("unexpected persona %+v", p)
+	}
+}
@@ -0,0 +1,143 @@
+package persona
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Storage types accepted in the storage config
+const (
+	StorageMemory    = "memory"
+	StorageDirectory = "directory"
+	StorageSQLite    = "sqlite"
+)
+
+// Store persists personas and their version history. Implementations must
+// be safe for concurrent use and return ErrNotFound for unknown IDs or
+// versions.
+type Store interface {
+	List() ([]models.Persona, error)       // latest version of every persona
+	Get(id string) (models.Persona, error) // latest version
+	GetVersion(id string, version int) (models.Persona, error)
+	Versions(id string) ([]models.Persona, error) // all versions, oldest first
+	Put(p models.Persona) error                   // adds p.Version as the latest version
+	Delete(id string) error                       // removes every version
+	Close() error
+}
+
+// OpenStore opens the persona store selected by the storage config
+func OpenStore(cfg config.StorageConfig) (Store, error) {
+	switch cfg.Type {
+	case "", StorageMemory:
+		return NewMemoryStore(), nil
+	case StorageDirectory:
+		store, err := OpenDirStore(cfg.Path, time.Duration(cfg.WatchIntervalSeconds)*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case StorageSQLite:
+		store, err := OpenSQLiteStore(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", cfg.Type)
+	}
+}
+
+// MemoryStore keeps personas in memory; they are lost on restart
+type MemoryStore struct {
+	mu       sync.RWMutex
+	versions map[string][]models.Persona // oldest first
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{versions: make(map[string][]models.Persona)}
+}
+
+// List returns the latest version of every persona in no particular order
+func (m *MemoryStore) List() ([]models.Persona, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	personas := make([]models.Persona, 0, len(m.versions))
+	for _, versions := range m.versions {
+		personas = append(personas, clone(versions[len(versions)-1]))
+	}
+	return personas, nil
+}
+
+// Get returns the latest version of the persona with the given ID
+func (m *MemoryStore) Get(id string) (models.Persona, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	versions, ok := m.versions[id]
+	if !ok {
+		return models.Persona{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return clone(versions[len(versions)-1]), nil
+}
+
+// GetVersion returns a specific version of a persona
+func (m *MemoryStore) GetVersion(id string, version int) (models.Persona, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, p := range m.versions[id] {
+		if p.Version == version {
+			return clone(p), nil
+		}
+	}
+	return models.Persona{}, fmt.Errorf("%w: %s@%d", ErrNotFound, id, version)
+}
+
+// Versions returns every version of a persona, oldest first
+func (m *MemoryStore) Versions(id string) ([]models.Persona, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	versions, ok := m.versions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	result := make([]models.Persona, len(versions))
+	for i, p := range versions {
+		result[i] = clone(p)
+	}
+	return result, nil
+}
+
+// Put adds p as the latest version of its persona
+func (m *MemoryStore) Put(p models.Persona) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.versions[p.ID] = append(m.versions[p.ID], clone(p))
+	return nil
+}
+
+// Delete removes every version of the persona with the given ID
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.versions[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	delete(m.versions, id)
+	return nil
+}
+
+// Close is a no-op for the memory store
+func (m *MemoryStore) Close() error {
+	return nil
+}
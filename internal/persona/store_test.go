@@ -0,0 +1,108 @@
+package persona
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	if _, err := store.Get("reviewer"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an empty store, got %v", err)
+	}
+
+	p := testPersona("reviewer")
+	p.Version = 1
+	p.DefaultTemperature = float64Ptr(0.2)
+	if err := store.Put(p); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	writer := testPersona("writer")
+	writer.Version = 1
+	if err := store.Put(writer); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	got, err := store.Get("reviewer")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.SystemPrompt != p.SystemPrompt || got.DefaultTemperature == nil || *got.DefaultTemperature != 0.2 {
+		t.Errorf("expected stored persona, got %+v", got)
+	}
+
+	p.Version = 2
+	p.SystemPrompt = "Replaced."
+	if err := store.Put(p); err != nil {
+		t.Fatalf("Put of a new version failed: %v", err)
+	}
+	if got, _ := store.Get("reviewer"); got.SystemPrompt != "Replaced." || got.Version != 2 {
+		t.Errorf("expected version 2 to be the latest, got %+v", got)
+	}
+
+	versions, err := store.Versions("reviewer")
+	if err != nil || len(versions) != 2 || versions[0].Version != 1 || versions[1].Version != 2 {
+		t.Errorf("expected versions 1 and 2, got %+v (%v)", versions, err)
+	}
+
+	v1, err := store.GetVersion("reviewer", 1)
+	if err != nil || v1.SystemPrompt != testPersona("reviewer").SystemPrompt {
+		t.Errorf("expected the original prompt in version 1, got %+v (%v)", v1, err)
+	}
+	if _, err := store.GetVersion("reviewer", 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing version, got %v", err)
+	}
+
+	list, err := store.List()
+	if err != nil || len(list) != 2 {
+		t.Errorf("expected 2 personas, got %d (%v)", len(list), err)
+	}
+
+	if err := store.Delete("reviewer"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := store.Delete("reviewer"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+	if _, err := store.Versions("reviewer"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected history to be deleted, got %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestOpenStore(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		cfg     config.StorageConfig
+		wantErr bool
+	}{
+		{name: "default", cfg: config.StorageConfig{}},
+		{name: "memory", cfg: config.StorageConfig{Type: "memory"}},
+		{name: "directory", cfg: config.StorageConfig{Type: "directory", Path: filepath.Join(dir, "personas")}},
+		{name: "sqlite", cfg: config.StorageConfig{Type: "sqlite", Path: filepath.Join(dir, "personas.db")}},
+		{name: "directory without path", cfg: config.StorageConfig{Type: "directory"}, wantErr: true},
+		{name: "unknown", cfg: config.StorageConfig{Type: "redis"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := OpenStore(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if store != nil {
+				store.Close()
+			}
+		})
+	}
+}
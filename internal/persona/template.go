@@ -0,0 +1,239 @@
+package persona
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// ErrMissingVars is returned when a persona prompt references variables the
+// request did not supply in persona_vars
+var ErrMissingVars = errors.New("missing persona_vars")
+
+// maxPromptSize caps the rendered size of a persona prompt
+const maxPromptSize = 64 * 1024
+
+// RenderPrompt renders req.PersonaPrompt as a template with req.PersonaVars
+// as its data, replacing the prompt with the result. Prompts without template
+// actions are left as they are.
+func RenderPrompt(req *models.ChatCompletionRequest, now time.Time) error {
+	if !strings.Contains(req.PersonaPrompt, "{{") {
+		return nil
+	}
+
+	rendered, err := Render(req.PersonaPrompt, req.PersonaVars, now)
+	if err != nil {
+		return err
+	}
+	req.PersonaPrompt = rendered
+	return nil
+}
+
+// Render executes a prompt template. Templates are sandboxed: they see only
+// vars and the helpers below, cannot loop, call other templates or declare
+// variables, and their output and every intermediate string are capped at
+// maxPromptSize. Every variable referenced as .name is
+// required; use {{var "name" "fallback"}} for optional ones.
+func Render(text string, vars map[string]string, now time.Time) (string, error) {
+	tmpl, err := parseTemplate(text, vars)
+	if err != nil {
+		return "", err
+	}
+
+	if missing := missingVars(tmpl, vars); len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingVars, strings.Join(missing, ", "))
+	}
+
+	data := make(map[string]string, len(vars))
+	for k, v := range vars {
+		data[k] = v
+	}
+
+	var out limitedBuilder
+	tmpl.Funcs(helpers(vars, now))
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: system_prompt: %v", ErrInvalid, err)
+	}
+	return out.String(), nil
+}
+
+// checkTemplate reports whether text is a valid prompt template
+func checkTemplate(text string) error {
+	_, err := parseTemplate(text, nil)
+	return err
+}
+
+// parseTemplate parses text and rejects the actions the sandbox disallows
+func parseTemplate(text string, vars map[string]string) (*template.Template, error) {
+	tmpl, err := template.New("system_prompt").
+		Option("missingkey=error").
+		Funcs(helpers(vars, time.Time{})).
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	if len(tmpl.Templates()) > 1 {
+		return nil, fmt.Errorf("%w: system_prompt: define and block are not allowed", ErrInvalid)
+	}
+	if err := walk(tmpl.Tree.Root, checkNode); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// helpers returns the functions available to prompt templates
+func helpers(vars map[string]string, now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"date": func() string { return now.UTC().Format("2006-01-02") },
+		"now":  func(layout string) string { return now.UTC().Format(layout) },
+		"var": func(name, fallback string) string {
+			if v, ok := vars[name]; ok && v != "" {
+				return v
+			}
+			return fallback
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"trim":  strings.TrimSpace,
+
+		// Size-capped replacements for the builtins that can grow a string
+		"printf": func(format string, args ...interface{}) (string, error) {
+			return capped(fmt.Sprintf(format, args...))
+		},
+		"print":    func(args ...interface{}) (string, error) { return capped(fmt.Sprint(args...)) },
+		"println":  func(args ...interface{}) (string, error) { return capped(fmt.Sprintln(args...)) },
+		"html":     func(args ...interface{}) (string, error) { return capped(template.HTMLEscaper(args...)) },
+		"js":       func(args ...interface{}) (string, error) { return capped(template.JSEscaper(args...)) },
+		"urlquery": func(args ...interface{}) (string, error) { return capped(template.URLQueryEscaper(args...)) },
+	}
+}
+
+// capped rejects helper results larger than maxPromptSize, so nested calls
+// cannot build up oversized intermediate values
+func capped(s string) (string, error) {
+	if len(s) > maxPromptSize {
+		return "", fmt.Errorf("intermediate value exceeds %d bytes", maxPromptSize)
+	}
+	return s, nil
+}
+
+// checkNode rejects loops, template calls and variable declarations. The
+// first two can do unbounded work; variables let a template reuse a large
+// value many times over.
+func checkNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.PipeNode:
+		if n != nil && len(n.Decl) > 0 {
+			return fmt.Errorf("%w: system_prompt: variable declarations are not allowed", ErrInvalid)
+		}
+	case *parse.RangeNode:
+		return fmt.Errorf("%w: system_prompt: range is not allowed", ErrInvalid)
+	case *parse.TemplateNode:
+		return fmt.Errorf("%w: system_prompt: template is not allowed", ErrInvalid)
+	}
+	return nil
+}
+
+// missingVars returns the sorted names of variables referenced as .name or
+// $.name that vars does not define
+func missingVars(tmpl *template.Template, vars map[string]string) []string {
+	seen := make(map[string]bool)
+	walk(tmpl.Tree.Root, func(node parse.Node) error {
+		var name string
+		switch n := node.(type) {
+		case *parse.FieldNode:
+			name = n.Ident[0]
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				name = n.Ident[1]
+			}
+		}
+		if _, ok := vars[name]; name != "" && !ok {
+			seen[name] = true
+		}
+		return nil
+	})
+
+	missing := make([]string, 0, len(seen))
+	for name := range seen {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// walk calls fn for node and everything below it, stopping at the first error
+func walk(node parse.Node, fn func(parse.Node) error) error {
+	if node == nil {
+		return nil
+	}
+	if err := fn(node); err != nil {
+		return err
+	}
+
+	var children []parse.Node
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			children = n.Nodes
+		}
+	case *parse.ActionNode:
+		children = []parse.Node{n.Pipe}
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			children = append(children, cmd)
+		}
+	case *parse.CommandNode:
+		children = n.Args
+	case *parse.ChainNode:
+		children = []parse.Node{n.Node}
+	case *parse.IfNode:
+		children = branch(&n.BranchNode)
+	case *parse.WithNode:
+		children = branch(&n.BranchNode)
+	case *parse.RangeNode:
+		children = branch(&n.BranchNode)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			children = []parse.Node{n.Pipe}
+		}
+	}
+
+	for _, child := range children {
+		if err := walk(child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// branch returns the children of an if, with or range node
+func branch(n *parse.BranchNode) []parse.Node {
+	nodes := []parse.Node{n.Pipe, n.List}
+	if n.ElseList != nil {
+		nodes = append(nodes, n.ElseList)
+	}
+	return nodes
+}
+
+// limitedBuilder collects template output up to maxPromptSize
+type limitedBuilder struct {
+	strings.Builder
+}
+
+func (b *limitedBuilder) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > maxPromptSize {
+		return 0, fmt.Errorf("rendered prompt exceeds %d bytes", maxPromptSize)
+	}
+	return b.Builder.Write(p)
+}
@@ -0,0 +1,114 @@
+package persona
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)
+	vars := map[string]string{"user_name": "Ada", "locale": "fr-FR"}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		err      error
+	}{
+		{name: "plain text", text: "You are helpful.", expected: "You are helpful."},
+		{name: "variables", text: "Help {{.user_name}} in {{.locale}}.", expected: "Help Ada in fr-FR."},
+		{name: "root variable", text: "{{$.user_name}}", expected: "Ada"},
+		{name: "date helper", text: "Today is {{date}}.", expected: "Today is 2024-03-01."},
+		{name: "now helper", text: "{{now \"15:04\"}}", expected: "23:30"},
+		{name: "optional variable", text: "{{var \"tone\" \"neutral\"}}", expected: "neutral"},
+		{name: "optional variable set", text: "{{var \"locale\" \"en-US\"}}", expected: "fr-FR"},
+		{name: "string helpers", text: "{{upper .user_name}} {{lower .locale}}", expected: "ADA fr-fr"},
+		{name: "conditional", text: "{{if eq .locale \"fr-FR\"}}Bonjour{{else}}Hello{{end}}", expected: "Bonjour"},
+		{name: "missing variables", text: "{{.team}} {{.user_name}} {{.role}}", err: ErrMissingVars},
+		{name: "missing in branch", text: "{{if .admin}}admin{{end}}", err: ErrMissingVars},
+		{name: "syntax error", text: "{{.user_name", err: ErrInvalid},
+		{name: "unknown function", text: "{{exec \"ls\"}}", err: ErrInvalid},
+		{name: "range", text: "{{range .user_name}}x{{end}}", err: ErrInvalid},
+		{name: "define", text: "{{define \"x\"}}x{{end}}", err: ErrInvalid},
+		{name: "template", text: "{{template \"system_prompt\"}}", err: ErrInvalid},
+		{name: "variable declaration", text: "{{$x := .user_name}}{{$x}}", err: ErrInvalid},
+		{name: "declaration in with", text: "{{with $x := .user_name}}{{$x}}{{end}}", err: ErrInvalid},
+		{name: "printf", text: "{{printf \"%s!\" .user_name}}", expected: "Ada!"},
+		{name: "html", text: "{{html \"<b>\"}}", expected: "&lt;b&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.text, vars, now)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRender_MissingVarsListed(t *testing.T) {
+	_, err := Render("{{.team}} {{.role}} {{.team}}", nil, time.Now())
+	if err == nil || !strings.HasSuffix(err.Error(), "role, team") {
+		t.Errorf("expected the missing variables to be listed once, got %v", err)
+	}
+}
+
+func TestRender_OutputLimit(t *testing.T) {
+	vars := map[string]string{"big": strings.Repeat("x", maxPromptSize/2+1)}
+	if _, err := Render("{{.big}}{{.big}}", vars, time.Now()); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected oversized output to be rejected, got %v", err)
+	}
+}
+
+func TestRender_Amplification(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{
+			name: "nested variables",
+			text: `{{$a := printf "%0999999d" 0}}` +
+				`{{$b := printf "%s%s%s%s%s%s%s%s%s%s" $a $a $a $a $a $a $a $a $a $a}}` +
+				`{{$c := printf "%s%s%s%s%s%s%s%s%s%s" $b $b $b $b $b $b $b $b $b $b}}` +
+				`{{$d := printf "%s%s%s%s%s%s%s%s%s%s" $c $c $c $c $c $c $c $c $c $c}}{{len $d}}`,
+		},
+		{name: "wide printf", text: `{{len (printf "%0999999d" 0)}}`},
+		{name: "nested print", text: `{{len (print (printf "%060000d" 0) (printf "%060000d" 0))}}`},
+		{name: "escaper", text: `{{len (html .angles)}}`},
+	}
+	vars := map[string]string{"angles": strings.Repeat("<", maxPromptSize/4+1)}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Render(tt.text, vars, time.Now()); !errors.Is(err, ErrInvalid) {
+				t.Errorf("expected the template to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	req := &models.ChatCompletionRequest{PersonaPrompt: "Literal {braces} stay."}
+	if err := RenderPrompt(req, time.Now()); err != nil || req.PersonaPrompt != "Literal {braces} stay." {
+		t.Errorf("expected prompt without actions to be untouched, got %q (%v)", req.PersonaPrompt, err)
+	}
+
+	req = &models.ChatCompletionRequest{PersonaPrompt: "Hi {{.name}}", PersonaVars: map[string]string{"name": "Ada"}}
+	if err := RenderPrompt(req, time.Now()); err != nil || req.PersonaPrompt != "Hi Ada" {
+		t.Errorf("expected rendered prompt, got %q (%v)", req.PersonaPrompt, err)
+	}
+}
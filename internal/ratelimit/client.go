@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/tokens"
+)
+
+// Client wraps a provider and charges the tokens of every completion to the
+// rate limit key found in the request context
+type Client struct {
+	client.Provider
+	limiter *Limiter
+}
+
+// NewClient wraps provider so token usage is charged to limiter
+func NewClient(provider client.Provider, limiter *Limiter) *Client {
+	return &Client{
+		Provider: provider,
+		limiter:  limiter,
+	}
+}
+
+// ChatCompletion forwards the request and charges the reported usage
+func (c *Client) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	resp, err := c.Provider.ChatCompletion(ctx, req)
+	if err == nil {
+		c.charge(ctx, resp.Usage.TotalTokens)
+	}
+	return resp, err
+}
+
+// ChatCompletionStream forwards the request and charges the usage reported
+// on the final chunk, or an estimate when the backend sends none
+func (c *Client) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	tally := tokens.NewStreamUsage(req)
+	err := c.Provider.ChatCompletionStream(ctx, req, func(chunk *models.ChatCompletionChunk) error {
+		tally.Add(chunk)
+		return onChunk(chunk)
+	})
+
+	if usage, ok := tally.Usage(); ok {
+		c.charge(ctx, usage.TotalTokens)
+	}
+	return err
+}
+
+// Embed forwards the request and charges the reported usage
+func (c *Client) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	resp, err := c.Provider.Embed(ctx, req)
+	if err == nil {
+		c.charge(ctx, resp.Usage.TotalTokens)
+	}
+	return resp, err
+}
+
+// charge adds tokens to the budget of the caller in ctx
+func (c *Client) charge(ctx context.Context, tokens int) {
+	if key, ok := FromContext(ctx); ok {
+		c.limiter.AddTokens(key, tokens)
+	}
+}
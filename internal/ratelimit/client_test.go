@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// mockProvider returns fixed usage for every completion
+type mockProvider struct {
+	usage models.Usage
+}
+
+func (m *mockProvider) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	return &models.ChatCompletionResponse{ID: "test-id", Usage: m.usage}, nil
+}
+
+func (m *mockProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	if err := onChunk(&models.ChatCompletionChunk{ID: "test-id"}); err != nil {
+		return err
+	}
+	usage := m.usage
+	return onChunk(&models.ChatCompletionChunk{ID: "test-id", Usage: &usage})
+}
+
+func (m *mockProvider) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	return &models.EmbeddingResponse{Model: req.Model, Usage: m.usage}, nil
+}
+
+func (m *mockProvider) ListModels(ctx context.Context) ([]models.Model, error) {
+	return nil, nil
+}
+
+func TestClient_ChargesTokens(t *testing.T) {
+	l, _ := newTestLimiter(Limits{TokensPerMinute: 100}, nil)
+	c := NewClient(&mockProvider{usage: models.Usage{TotalTokens: 40}}, l)
+	ctx := NewContext(context.Background(), "key:a")
+	req := &models.ChatCompletionRequest{Model: "test-model"}
+
+	if _, err := c.ChatCompletion(ctx, req); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if d := l.Allow("key:a"); d.TokensLeft != 60 {
+		t.Errorf("expected 60 tokens left, got %d", d.TokensLeft)
+	}
+
+	err := c.ChatCompletionStream(ctx, req, func(*models.ChatCompletionChunk) error { return nil })
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+	if d := l.Allow("key:a"); d.TokensLeft != 20 {
+		t.Errorf("expected 20 tokens left, got %d", d.TokensLeft)
+	}
+
+	// Requests without a key are not charged
+	if _, err := c.ChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if d := l.Allow("key:a"); d.TokensLeft != 20 {
+		t.Errorf("expected 20 tokens left, got %d", d.TokensLeft)
+	}
+
+	// Embeddings are charged like completions
+	if _, err := c.Embed(ctx, &models.EmbeddingRequest{Model: "test-model"}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if d := l.Allow("key:a"); d.TokensLeft != 0 {
+		t.Errorf("expected 0 tokens left, got %d", d.TokensLeft)
+	}
+}
+
+// silentProvider streams content without reporting usage
+type silentProvider struct {
+	mockProvider
+}
+
+func (m *silentProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	return onChunk(&models.ChatCompletionChunk{ID: "test-id", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Content: "Hello, world!"}}}})
+}
+
+func TestClient_ChargesEstimatedStreamTokens(t *testing.T) {
+	l, _ := newTestLimiter(Limits{TokensPerMinute: 100}, nil)
+	c := NewClient(&silentProvider{}, l)
+	ctx := NewContext(context.Background(), "key:a")
+	req := &models.ChatCompletionRequest{Model: "test-model", Messages: []models.ChatMessage{{Role: "user", Content: "Hi"}}}
+
+	if err := c.ChatCompletionStream(ctx, req, func(*models.ChatCompletionChunk) error { return nil }); err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+	if d := l.Allow("key:a"); d.TokensLeft != 91 {
+		t.Errorf("expected an estimate of 9 tokens to be charged, leaving 91, got %d", d.TokensLeft)
+	}
+}
@@ -0,0 +1,230 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+// maxIdleBuckets is the number of tracked callers above which idle, fully
+// refilled buckets are dropped
+const maxIdleBuckets = 10000
+
+// Limits are the per-minute budgets of a single caller. Zero means unlimited.
+type Limits struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// Decision is the outcome of a rate limit check
+type Decision struct {
+	Allowed      bool
+	RequestLimit int           // requests per minute, 0 when unlimited
+	RequestsLeft int           // requests left in the bucket
+	TokenLimit   int           // tokens per minute, 0 when unlimited
+	TokensLeft   int           // tokens left in the bucket
+	Reset        time.Duration // time until the buckets are full again
+	RetryAfter   time.Duration // time until the next request is allowed, when denied
+}
+
+// bucket is a token bucket refilled continuously at capacity per minute
+type bucket struct {
+	capacity float64
+	level    float64
+	last     time.Time
+}
+
+// refill tops up the bucket for the time elapsed since the last update
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Minutes()
+	b.level = math.Min(b.capacity, b.level+elapsed*b.capacity)
+	b.last = now
+}
+
+// untilLevel returns how long it takes the bucket to refill to level
+func (b *bucket) untilLevel(level float64) time.Duration {
+	if b.level >= level || b.capacity == 0 {
+		return 0
+	}
+	return time.Duration((level - b.level) / b.capacity * float64(time.Minute))
+}
+
+// callerBuckets holds the request and token buckets of one caller
+type callerBuckets struct {
+	limits   Limits
+	requests *bucket
+	tokens   *bucket
+}
+
+// Limiter enforces per-caller request and token budgets with token buckets
+type Limiter struct {
+	mu        sync.Mutex
+	defaults  Limits
+	overrides map[string]Limits
+	callers   map[string]*callerBuckets
+	now       func() time.Time
+}
+
+// New creates a limiter with default limits and per-caller overrides
+func New(defaults Limits, overrides map[string]Limits) *Limiter {
+	return &Limiter{
+		defaults:  defaults,
+		overrides: overrides,
+		callers:   make(map[string]*callerBuckets),
+		now:       time.Now,
+	}
+}
+
+// NewFromConfig creates a limiter from the rate limit config
+func NewFromConfig(cfg config.RateLimitConfig) *Limiter {
+	overrides := make(map[string]Limits, len(cfg.Overrides))
+	for key, o := range cfg.Overrides {
+		overrides[key] = Limits{RequestsPerMinute: o.RequestsPerMinute, TokensPerMinute: o.TokensPerMinute}
+	}
+
+	return New(Limits{
+		RequestsPerMinute: cfg.RequestsPerMinute,
+		TokensPerMinute:   cfg.TokensPerMinute,
+	}, overrides)
+}
+
+// Allow consumes one request from key's budget. Requests are denied when
+// either the request bucket is empty or earlier responses have used up the
+// token budget.
+func (l *Limiter) Allow(key string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	c := l.caller(key, now)
+
+	d := Decision{
+		RequestLimit: c.limits.RequestsPerMinute,
+		TokenLimit:   c.limits.TokensPerMinute,
+	}
+
+	switch {
+	case c.requests != nil && c.requests.level < 1:
+		d.RetryAfter = c.requests.untilLevel(1)
+	case c.tokens != nil && c.tokens.level < 1:
+		d.RetryAfter = c.tokens.untilLevel(1)
+	default:
+		d.Allowed = true
+		if c.requests != nil {
+			c.requests.level--
+		}
+	}
+
+	l.fill(&d, c)
+	return d
+}
+
+// AddTokens charges n used tokens to key's token budget. The bucket may go
+// negative, delaying further requests until it has refilled.
+func (l *Limiter) AddTokens(key string, n int) {
+	if n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c := l.caller(key, l.now())
+	if c.tokens != nil {
+		c.tokens.level -= float64(n)
+	}
+}
+
+// caller returns the refilled buckets for key, creating them on first use.
+// Callers must hold l.mu.
+func (l *Limiter) caller(key string, now time.Time) *callerBuckets {
+	c, ok := l.callers[key]
+	if !ok {
+		if len(l.callers) >= maxIdleBuckets {
+			l.prune(now)
+		}
+
+		limits, ok := l.overrides[key]
+		if !ok {
+			limits = l.defaults
+		}
+
+		c = &callerBuckets{limits: limits}
+		if limits.RequestsPerMinute > 0 {
+			c.requests = &bucket{capacity: float64(limits.RequestsPerMinute), level: float64(limits.RequestsPerMinute), last: now}
+		}
+		if limits.TokensPerMinute > 0 {
+			c.tokens = &bucket{capacity: float64(limits.TokensPerMinute), level: float64(limits.TokensPerMinute), last: now}
+		}
+		l.callers[key] = c
+	}
+
+	if c.requests != nil {
+		c.requests.refill(now)
+	}
+	if c.tokens != nil {
+		c.tokens.refill(now)
+	}
+	return c
+}
+
+// prune drops callers whose buckets have fully refilled. Callers must hold
+// l.mu.
+func (l *Limiter) prune(now time.Time) {
+	for key, c := range l.callers {
+		full := true
+		for _, b := range []*bucket{c.requests, c.tokens} {
+			if b != nil {
+				b.refill(now)
+				full = full && b.level >= b.capacity
+			}
+		}
+		if full {
+			delete(l.callers, key)
+		}
+	}
+}
+
+// fill copies the remaining budgets of c into d. Callers must hold l.mu.
+func (l *Limiter) fill(d *Decision, c *callerBuckets) {
+	if c.requests != nil {
+		d.RequestsLeft = int(math.Max(0, c.requests.level))
+		d.Reset = c.requests.untilLevel(c.requests.capacity)
+	}
+	if c.tokens != nil {
+		d.TokensLeft = int(math.Max(0, c.tokens.level))
+		if reset := c.tokens.untilLevel(c.tokens.capacity); reset > d.Reset {
+			d.Reset = reset
+		}
+	}
+}
+
+// KeyFor returns the rate limit key of a caller: the tenant when by is
+// "tenant" and the principal has one, otherwise the principal ID. fallback
+// identifies unauthenticated callers, typically by address.
+func KeyFor(principal *auth.Principal, by, fallback string) string {
+	if principal == nil {
+		return fallback
+	}
+	if by == "tenant" && principal.Tenant != "" {
+		return "tenant:" + principal.Tenant
+	}
+	return "key:" + principal.Key()
+}
+
+type keyContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the caller's rate limit key
+func NewContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, key)
+}
+
+// FromContext returns the rate limit key stored in ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(keyContextKey{}).(string)
+	return key, ok
+}
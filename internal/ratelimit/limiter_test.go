@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+// newTestLimiter returns a limiter whose clock is advanced by the caller
+func newTestLimiter(defaults Limits, overrides map[string]Limits) (*Limiter, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	l := New(defaults, overrides)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiter_Requests(t *testing.T) {
+	l, now := newTestLimiter(Limits{RequestsPerMinute: 3}, nil)
+
+	for i := 0; i < 3; i++ {
+		d := l.Allow("key:a")
+		if !d.Allowed {
+			t.Fatalf("request %d: expected allowed", i)
+		}
+		if d.RequestsLeft != 2-i {
+			t.Errorf("request %d: expected %d requests left, got %d", i, 2-i, d.RequestsLeft)
+		}
+	}
+
+	d := l.Allow("key:a")
+	if d.Allowed {
+		t.Fatal("expected fourth request to be denied")
+	}
+	if d.RetryAfter != 20*time.Second {
+		t.Errorf("expected retry after 20s, got %v", d.RetryAfter)
+	}
+
+	// Other callers have their own budget
+	if !l.Allow("key:b").Allowed {
+		t.Error("expected another caller to be allowed")
+	}
+
+	*now = now.Add(20 * time.Second)
+	if !l.Allow("key:a").Allowed {
+		t.Error("expected request to be allowed after refill")
+	}
+}
+
+func TestLimiter_Tokens(t *testing.T) {
+	l, now := newTestLimiter(Limits{TokensPerMinute: 600}, nil)
+
+	if !l.Allow("key:a").Allowed {
+		t.Fatal("expected first request to be allowed")
+	}
+
+	l.AddTokens("key:a", 900)
+	d := l.Allow("key:a")
+	if d.Allowed {
+		t.Fatal("expected request to be denied after exceeding the token budget")
+	}
+	if d.TokensLeft != 0 {
+		t.Errorf("expected 0 tokens left, got %d", d.TokensLeft)
+	}
+	if d.RetryAfter != 30100*time.Millisecond {
+		t.Errorf("expected retry after 30.1s, got %v", d.RetryAfter)
+	}
+
+	*now = now.Add(31 * time.Second)
+	if !l.Allow("key:a").Allowed {
+		t.Error("expected request to be allowed after refill")
+	}
+}
+
+func TestLimiter_Overrides(t *testing.T) {
+	l, _ := newTestLimiter(Limits{RequestsPerMinute: 1}, map[string]Limits{
+		"tenant:big": {RequestsPerMinute: 5},
+	})
+
+	d := l.Allow("tenant:big")
+	if d.RequestLimit != 5 || d.RequestsLeft != 4 {
+		t.Errorf("expected override limit 5 with 4 left, got %d with %d left", d.RequestLimit, d.RequestsLeft)
+	}
+
+	d = l.Allow("tenant:small")
+	if d.RequestLimit != 1 || d.RequestsLeft != 0 {
+		t.Errorf("expected default limit 1 with 0 left, got %d with %d left", d.RequestLimit, d.RequestsLeft)
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l, _ := newTestLimiter(Limits{}, nil)
+
+	for i := 0; i < 100; i++ {
+		if !l.Allow("key:a").Allowed {
+			t.Fatalf("request %d: expected unlimited caller to be allowed", i)
+		}
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	l := NewFromConfig(config.RateLimitConfig{
+		RequestsPerMinute: 10,
+		TokensPerMinute:   1000,
+		Overrides: map[string]config.LimitConfig{
+			"key:ci": {RequestsPerMinute: 100},
+		},
+	})
+
+	if d := l.Allow("key:a"); d.RequestLimit != 10 || d.TokenLimit != 1000 {
+		t.Errorf("expected default limits 10/1000, got %d/%d", d.RequestLimit, d.TokenLimit)
+	}
+	if d := l.Allow("key:ci"); d.RequestLimit != 100 || d.TokenLimit != 0 {
+		t.Errorf("expected override limits 100/0, got %d/%d", d.RequestLimit, d.TokenLimit)
+	}
+}
+
+func TestKeyFor(t *testing.T) {
+	principal := &auth.Principal{ID: "team-a", Tenant: "acme"}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		by        string
+		expected  string
+	}{
+		{name: "anonymous", principal: nil, by: "key", expected: "ip:10.0.0.1"},
+		{name: "by key", principal: principal, by: "key", expected: "key:team-a"},
+		{name: "by tenant", principal: principal, by: "tenant", expected: "tenant:acme"},
+		{name: "token subject", principal: &auth.Principal{ID: "team-a", Method: "jwt"}, by: "key", expected: "key:jwt:team-a"},
+		{name: "tenant missing", principal: &auth.Principal{ID: "team-b"}, by: "tenant", expected: "key:team-b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeyFor(tt.principal, tt.by, "ip:10.0.0.1"); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("expected no key in empty context")
+	}
+
+	key, ok := FromContext(NewContext(context.Background(), "key:a"))
+	if !ok || key != "key:a" {
+		t.Errorf("expected key:a, got %q", key)
+	}
+}
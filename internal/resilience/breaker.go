@@ -0,0 +1,239 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// ErrCircuitOpen is returned when a backend's circuit breaker rejects a call
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+// Circuit breaker states
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// String returns the lower-case state name used in health reports
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig controls when a circuit breaker trips and recovers
+type BreakerConfig struct {
+	FailureRatio  float64       // failure fraction within the window that opens the circuit
+	MinRequests   int           // requests required in the window before the ratio is considered
+	Window        time.Duration // length of the counting window
+	ProbeInterval time.Duration // time the circuit stays open before a probe is let through
+}
+
+// CircuitBreaker tracks failures of a single backend and rejects calls
+// while the backend is considered down
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	config      BreakerConfig
+	state       BreakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probing     bool
+	now         func() time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(config BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		config: config,
+		state:  StateClosed,
+		now:    time.Now,
+	}
+}
+
+// State returns the current state, moving an open circuit to half-open once
+// the probe interval has elapsed
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	return b.state
+}
+
+// Ready reports whether a call would currently be allowed through
+func (b *CircuitBreaker) Ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	return b.state == StateClosed || (b.state == StateHalfOpen && !b.probing)
+}
+
+// Allow reserves a call. It returns ErrCircuitOpen while the circuit is open
+// or a half-open probe is already in flight.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	switch b.state {
+	case StateOpen:
+		return ErrCircuitOpen
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+
+	return nil
+}
+
+// Record reports the outcome of a call reserved with Allow, made on ctx.
+// Only transient upstream failures count against the backend.
+func (b *CircuitBreaker) Record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := isBackendFailure(ctx, err)
+	ignored := err != nil && !failed
+
+	if b.state == StateHalfOpen {
+		b.probing = false
+		switch {
+		case failed:
+			b.trip()
+		case !ignored:
+			b.reset()
+		}
+		return
+	}
+
+	if ignored {
+		return
+	}
+
+	now := b.now()
+	if b.windowStart.IsZero() || now.Sub(b.windowStart) > b.config.Window {
+		b.windowStart = now
+		b.requests = 0
+		b.failures = 0
+	}
+
+	b.requests++
+	if failed {
+		b.failures++
+	}
+
+	if b.requests >= b.config.MinRequests && float64(b.failures)/float64(b.requests) >= b.config.FailureRatio {
+		b.trip()
+	}
+}
+
+// advance moves an open circuit to half-open after the probe interval.
+// Callers must hold b.mu.
+func (b *CircuitBreaker) advance() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.ProbeInterval {
+		b.state = StateHalfOpen
+		b.probing = false
+	}
+}
+
+// trip opens the circuit. Callers must hold b.mu.
+func (b *CircuitBreaker) trip() {
+	b.state = StateOpen
+	b.openedAt = b.now()
+}
+
+// reset closes the circuit and clears the window. Callers must hold b.mu.
+func (b *CircuitBreaker) reset() {
+	b.state = StateClosed
+	b.windowStart = time.Time{}
+	b.requests = 0
+	b.failures = 0
+}
+
+// isBackendFailure reports whether err indicates the backend itself is
+// failing. Rate limiting, client errors and calls the caller gave up on do
+// not count.
+func isBackendFailure(ctx context.Context, err error) bool {
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	return IsRetryable(ctx, err)
+}
+
+// BreakerProvider wraps a provider with a circuit breaker
+type BreakerProvider struct {
+	client.Provider
+	name    string
+	breaker *CircuitBreaker
+}
+
+// WithBreaker wraps provider with a new circuit breaker. name identifies the
+// backend in error messages.
+func WithBreaker(name string, provider client.Provider, config BreakerConfig) *BreakerProvider {
+	return &BreakerProvider{
+		Provider: provider,
+		name:     name,
+		breaker:  NewCircuitBreaker(config),
+	}
+}
+
+// Breaker returns the underlying circuit breaker
+func (p *BreakerProvider) Breaker() *CircuitBreaker {
+	return p.breaker
+}
+
+// ChatCompletion forwards the request unless the circuit is open
+func (p *BreakerProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	if err := p.breaker.Allow(); err != nil {
+		return nil, fmt.Errorf("backend %s: %w", p.name, err)
+	}
+
+	resp, err := p.Provider.ChatCompletion(ctx, req)
+	p.breaker.Record(ctx, err)
+	return resp, err
+}
+
+// ChatCompletionStream forwards the request unless the circuit is open
+func (p *BreakerProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	if err := p.breaker.Allow(); err != nil {
+		return fmt.Errorf("backend %s: %w", p.name, err)
+	}
+
+	err := p.Provider.ChatCompletionStream(ctx, req, onChunk)
+	p.breaker.Record(ctx, err)
+	return err
+}
+
+// Embed forwards the request unless the circuit is open
+func (p *BreakerProvider) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	if err := p.breaker.Allow(); err != nil {
+		return nil, fmt.Errorf("backend %s: %w", p.name, err)
+	}
+
+	resp, err := p.Provider.Embed(ctx, req)
+	p.breaker.Record(ctx, err)
+	return resp, err
+}
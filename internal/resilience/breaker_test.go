@@ -0,0 +1,170 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+var testBreakerConfig = BreakerConfig{
+	FailureRatio:  0.5,
+	MinRequests:   4,
+	Window:        time.Minute,
+	ProbeInterval: 30 * time.Second,
+}
+
+// newTestBreaker returns a breaker driven by a fake clock
+func newTestBreaker() (*CircuitBreaker, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	b := NewCircuitBreaker(testBreakerConfig)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreaker_Lifecycle(t *testing.T) {
+	b, now := newTestBreaker()
+	serverErr := &client.StatusError{StatusCode: 503}
+
+	// Below MinRequests the ratio is not considered
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow failed while closed: %v", err)
+		}
+		b.Record(context.Background(), serverErr)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed below min requests, got %s", b.State())
+	}
+
+	b.Allow()
+	b.Record(context.Background(), serverErr)
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after failures, got %s", b.State())
+	}
+
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen while open, got %v", err)
+	}
+
+	// After the probe interval a single probe is let through
+	*now = now.Add(testBreakerConfig.ProbeInterval)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected half-open after probe interval, got %s", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected second concurrent probe to be rejected, got %v", err)
+	}
+
+	// A failed probe reopens the circuit
+	b.Record(context.Background(), serverErr)
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after failed probe, got %s", b.State())
+	}
+
+	// A successful probe closes it
+	*now = now.Add(testBreakerConfig.ProbeInterval)
+	b.Allow()
+	b.Record(context.Background(), nil)
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed after successful probe, got %s", b.State())
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	b, _ := newTestBreaker()
+
+	for i := 0; i < 10; i++ {
+		b.Allow()
+		b.Record(context.Background(), &client.StatusError{StatusCode: 400})
+		b.Allow()
+		b.Record(context.Background(), &client.StatusError{StatusCode: 429})
+		b.Allow()
+		b.Record(context.Background(), context.Canceled)
+	}
+
+	if b.State() != StateClosed {
+		t.Errorf("expected client errors not to open the circuit, got %s", b.State())
+	}
+}
+
+func TestCircuitBreaker_WindowReset(t *testing.T) {
+	b, now := newTestBreaker()
+
+	for i := 0; i < 3; i++ {
+		b.Allow()
+		b.Record(context.Background(), &client.StatusError{StatusCode: 500})
+	}
+
+	// Failures from an expired window do not count
+	*now = now.Add(2 * testBreakerConfig.Window)
+	b.Allow()
+	b.Record(context.Background(), &client.StatusError{StatusCode: 500})
+
+	if b.State() != StateClosed {
+		t.Errorf("expected closed after window reset, got %s", b.State())
+	}
+}
+
+func TestBreakerProvider_RejectsWhenOpen(t *testing.T) {
+	flaky := &flakyProvider{errs: []error{
+		&client.StatusError{StatusCode: 500},
+		&client.StatusError{StatusCode: 500},
+		&client.StatusError{StatusCode: 500},
+		&client.StatusError{StatusCode: 500},
+	}}
+	provider := WithBreaker("test", flaky, testBreakerConfig)
+
+	for i := 0; i < 4; i++ {
+		provider.ChatCompletion(context.Background(), &models.ChatCompletionRequest{})
+	}
+
+	_, err := provider.ChatCompletion(context.Background(), &models.ChatCompletionRequest{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	if flaky.calls != 4 {
+		t.Errorf("expected open circuit to short-circuit the call, got %d calls", flaky.calls)
+	}
+}
+
+func TestBreakerProvider_CountsUpstreamTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	hanging := client.NewOpenWebUIClient(server.URL, "", 20*time.Millisecond)
+	provider := WithBreaker("test", hanging, testBreakerConfig)
+	req := &models.ChatCompletionRequest{Model: "test-model", Messages: []models.ChatMessage{{Role: "user", Content: "Hi"}}}
+
+	// A caller giving up is not the backend's fault
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < testBreakerConfig.MinRequests; i++ {
+		provider.ChatCompletion(ctx, req)
+	}
+	if state := provider.Breaker().State(); state != StateClosed {
+		t.Fatalf("expected cancelled calls to be ignored, got %s", state)
+	}
+
+	for i := 0; i < testBreakerConfig.MinRequests; i++ {
+		if _, err := provider.ChatCompletion(context.Background(), req); err == nil {
+			t.Fatal("expected the call to time out")
+		}
+	}
+	if state := provider.Breaker().State(); state != StateOpen {
+		t.Errorf("expected upstream timeouts to open the circuit, got %s", state)
+	}
+}
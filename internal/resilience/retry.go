@@ -0,0 +1,178 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// RetryPolicy controls how transient upstream failures are retried
+type RetryPolicy struct {
+	MaxAttempts    int           // attempts including the first; values below 1 mean 1
+	InitialBackoff time.Duration // delay before the first retry
+	MaxBackoff     time.Duration // upper bound for any single delay
+	Multiplier     float64       // backoff growth factor
+	Jitter         float64       // random +/- fraction applied to each delay
+}
+
+// backoff returns the delay before retry number attempt (starting at 1)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		delay *= p.Multiplier
+	}
+
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	if p.MaxBackoff > 0 && time.Duration(delay) > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return time.Duration(delay)
+}
+
+// IsRetryable reports whether err is a transient upstream failure: a
+// connection error, an upstream timeout, 429 Too Many Requests, any 5xx
+// status or an open circuit. Nothing is retryable once ctx, the caller's
+// context, is cancelled or expired.
+func IsRetryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// With the caller's context still live, a deadline can only come from
+	// the HTTP client timeout
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// RetryingProvider wraps a provider and retries transient failures with
+// exponential backoff and jitter
+type RetryingProvider struct {
+	client.Provider
+	name   string
+	policy RetryPolicy
+}
+
+// WithRetry wraps provider with the given retry policy. name identifies the
+// backend in log messages.
+func WithRetry(name string, provider client.Provider, policy RetryPolicy) *RetryingProvider {
+	return &RetryingProvider{
+		Provider: provider,
+		name:     name,
+		policy:   policy,
+	}
+}
+
+// ChatCompletion forwards the request, retrying transient failures
+func (p *RetryingProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	var resp *models.ChatCompletionResponse
+	err := p.do(ctx, func() error {
+		var err error
+		resp, err = p.Provider.ChatCompletion(ctx, req)
+		return err
+	})
+
+	return resp, err
+}
+
+// ChatCompletionStream forwards the request, retrying transient failures
+// only while nothing has been delivered to the caller yet
+func (p *RetryingProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	delivered := false
+	return p.do(ctx, func() error {
+		err := p.Provider.ChatCompletionStream(ctx, req, func(chunk *models.ChatCompletionChunk) error {
+			delivered = true
+			return onChunk(chunk)
+		})
+		if err != nil && delivered {
+			return &permanentError{err}
+		}
+		return err
+	})
+}
+
+// Embed forwards the request, retrying transient failures
+func (p *RetryingProvider) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	var resp *models.EmbeddingResponse
+	err := p.do(ctx, func() error {
+		var err error
+		resp, err = p.Provider.Embed(ctx, req)
+		return err
+	})
+
+	return resp, err
+}
+
+// do runs call until it succeeds, fails permanently or attempts run out
+func (p *RetryingProvider) do(ctx context.Context, call func() error) error {
+	attempts := p.policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = call()
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if !IsRetryable(ctx, err) || attempt >= attempts {
+			return err
+		}
+
+		delay := p.policy.backoff(attempt)
+
+		// Honour Retry-After, unless it asks for a longer wait than we are
+		// willing to spend, in which case it is better to fail over now
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+			if p.policy.MaxBackoff > 0 && statusErr.RetryAfter > p.policy.MaxBackoff {
+				return err
+			}
+			delay = statusErr.RetryAfter
+		}
+
+		log.Printf("Backend %s attempt %d/%d failed, retrying in %v: %v", p.name, attempt, attempts, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// permanentError marks a failure that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
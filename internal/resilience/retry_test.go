@@ -0,0 +1,179 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// flakyProvider fails with the queued errors before succeeding
+type flakyProvider struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyProvider) next() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *flakyProvider) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func (f *flakyProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	if err := f.next(); err != nil {
+		return nil, err
+	}
+	return &models.ChatCompletionResponse{ID: "ok"}, nil
+}
+
+func (f *flakyProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	if err := onChunk(&models.ChatCompletionChunk{ID: "partial"}); err != nil {
+		return err
+	}
+	return f.next()
+}
+
+func (f *flakyProvider) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	if err := f.next(); err != nil {
+		return nil, err
+	}
+	return &models.EmbeddingResponse{Model: req.Model}, nil
+}
+
+func (f *flakyProvider) ListModels(ctx context.Context) ([]models.Model, error) {
+	return nil, f.next()
+}
+
+var testPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		cancelled bool // the caller's context is done
+		want      bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "503", err: &client.StatusError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "429", err: &client.StatusError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "400", err: &client.StatusError{StatusCode: http.StatusBadRequest}, want: false},
+		{name: "connection refused", err: fmt.Errorf("failed to send request: %w", &url.Error{Op: "Post", Err: errors.New("connection refused")}), want: true},
+		{name: "cancelled", err: fmt.Errorf("failed to send request: %w", &url.Error{Op: "Post", Err: context.Canceled}), want: false},
+		{name: "upstream timeout", err: fmt.Errorf("failed to send request: %w", &url.Error{Op: "Post", Err: context.DeadlineExceeded}), want: true},
+		{name: "body read timeout", err: fmt.Errorf("failed to read response: %w", context.DeadlineExceeded), want: true},
+		{name: "caller deadline", err: fmt.Errorf("failed to send request: %w", &url.Error{Op: "Post", Err: context.DeadlineExceeded}), cancelled: true, want: false},
+		{name: "503 after caller left", err: &client.StatusError{StatusCode: http.StatusServiceUnavailable}, cancelled: true, want: false},
+		{name: "other", err: errors.New("failed to unmarshal response"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			if got := IsRetryable(ctx, tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryingProvider_ChatCompletion(t *testing.T) {
+	tests := []struct {
+		name        string
+		errs        []error
+		wantErr     bool
+		expectCalls int
+	}{
+		{
+			name:        "succeeds after transient failures",
+			errs:        []error{&client.StatusError{StatusCode: 502}, &client.StatusError{StatusCode: 429}},
+			expectCalls: 3,
+		},
+		{
+			name:        "gives up after max attempts",
+			errs:        []error{&client.StatusError{StatusCode: 500}, &client.StatusError{StatusCode: 500}, &client.StatusError{StatusCode: 500}},
+			wantErr:     true,
+			expectCalls: 3,
+		},
+		{
+			name:        "does not retry client errors",
+			errs:        []error{&client.StatusError{StatusCode: 400}},
+			wantErr:     true,
+			expectCalls: 1,
+		},
+		{
+			name:        "fails fast on long Retry-After",
+			errs:        []error{&client.StatusError{StatusCode: 429, RetryAfter: time.Minute}},
+			wantErr:     true,
+			expectCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyProvider{errs: tt.errs}
+			provider := WithRetry("test", flaky, testPolicy)
+
+			_, err := provider.ChatCompletion(context.Background(), &models.ChatCompletionRequest{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChatCompletion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if flaky.calls != tt.expectCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectCalls, flaky.calls)
+			}
+		})
+	}
+}
+
+func TestRetryingProvider_StreamNotRetriedAfterDelivery(t *testing.T) {
+	flaky := &flakyProvider{errs: []error{&client.StatusError{StatusCode: 502}}}
+	provider := WithRetry("test", flaky, testPolicy)
+
+	err := provider.ChatCompletionStream(context.Background(), &models.ChatCompletionRequest{}, func(chunk *models.ChatCompletionChunk) error {
+		return nil
+	})
+
+	var statusErr *client.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Errorf("expected upstream error to be returned, got %v", err)
+	}
+
+	if flaky.calls != 1 {
+		t.Errorf("expected 1 call, got %d", flaky.calls)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/resilience"
+)
+
+// ErrModelNotFound is returned when no backend serves the requested model
+var ErrModelNotFound = errors.New("model not found")
+
+// Backend is a named upstream together with the model patterns it serves
+type Backend struct {
+	Name      string
+	Provider  client.Provider
+	Models    []string
+	Fallbacks []string // backends tried in order when this one is exhausted
+
+	// Breaker is the backend's circuit breaker, nil when disabled
+	Breaker *resilience.CircuitBreaker
+}
+
+// available reports whether the backend's circuit currently admits calls
+func (b *Backend) available() bool {
+	return b.Breaker == nil || b.Breaker.Ready()
+}
+
+// Router dispatches requests to backends based on the requested model
+type Router struct {
+	backends       []*Backend
+	byName         map[string]*Backend
+	aliases        map[string]string
+	fallbackModels map[string][]string
+	defaultBackend *Backend
+}
+
+// target is a single backend and model pair a request can be sent to
+type target struct {
+	backend *Backend
+	model   string
+}
+
+// New creates a router over the given backends. Earlier backends win when
+// several patterns match the same model.
+func New(backends []*Backend, rules config.RoutingConfig) (*Router, error) {
+	r := &Router{
+		backends:       backends,
+		byName:         make(map[string]*Backend),
+		aliases:        rules.Aliases,
+		fallbackModels: rules.FallbackModels,
+	}
+	defaultBackend := rules.DefaultBackend
+
+	for _, b := range backends {
+		for _, pattern := range b.Models {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("backend %q: invalid model pattern %q: %w", b.Name, pattern, err)
+			}
+		}
+		r.byName[b.Name] = b
+	}
+
+	for _, b := range backends {
+		for _, fallback := range b.Fallbacks {
+			if _, ok := r.byName[fallback]; !ok {
+				return nil, fmt.Errorf("backend %q: unknown fallback backend %q", b.Name, fallback)
+			}
+		}
+	}
+
+	if defaultBackend != "" {
+		b, ok := r.byName[defaultBackend]
+		if !ok {
+			return nil, fmt.Errorf("unknown default backend %q", defaultBackend)
+		}
+		r.defaultBackend = b
+	}
+
+	return r, nil
+}
+
+// NewFromConfig creates the providers described by cfg, each wrapped with
+// the configured retry policy and circuit breaker, and a router over them
+func NewFromConfig(cfg *config.Config) (*Router, error) {
+	policy := resilience.RetryPolicy{
+		MaxAttempts:    cfg.Retry.MaxAttempts,
+		InitialBackoff: time.Duration(cfg.Retry.InitialBackoffMs) * time.Millisecond,
+		MaxBackoff:     time.Duration(cfg.Retry.MaxBackoffMs) * time.Millisecond,
+		Multiplier:     cfg.Retry.Multiplier,
+		Jitter:         cfg.Retry.Jitter,
+	}
+
+	var backends []*Backend
+	for _, bc := range cfg.BackendList() {
+		provider, err := client.NewProvider(bc.Provider, bc.BaseURL, bc.APIKey, time.Duration(bc.Timeout)*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("backend %q: %w", bc.Name, err)
+		}
+
+		backend := &Backend{
+			Name:      bc.Name,
+			Provider:  resilience.WithRetry(bc.Name, provider, policy),
+			Models:    bc.Models,
+			Fallbacks: bc.Fallbacks,
+		}
+
+		// The breaker sits outside the retries so one exhausted request
+		// counts as a single failure
+		if cfg.Breaker.Enabled {
+			breakerProvider := resilience.WithBreaker(bc.Name, backend.Provider, resilience.BreakerConfig{
+				FailureRatio:  cfg.Breaker.FailureRatio,
+				MinRequests:   cfg.Breaker.MinRequests,
+				Window:        time.Duration(cfg.Breaker.WindowSeconds) * time.Second,
+				ProbeInterval: time.Duration(cfg.Breaker.ProbeIntervalSeconds) * time.Second,
+			})
+			backend.Provider = breakerProvider
+			backend.Breaker = breakerProvider.Breaker()
+		}
+
+		backends = append(backends, backend)
+	}
+
+	return New(backends, cfg.Routing)
+}
+
+// Resolve returns the model an alias stands for, or model itself when it is
+// not an alias
+func (r *Router) Resolve(model string) string {
+	if target, ok := r.aliases[model]; ok {
+		return target
+	}
+	return model
+}
+
+// Route resolves aliases and returns the backend serving model together with
+// the model name that should be sent upstream. Exact names take precedence
+// over glob patterns.
+func (r *Router) Route(model string) (*Backend, string, error) {
+	model = r.Resolve(model)
+
+	for _, b := range r.backends {
+		for _, pattern := range b.Models {
+			if !isPattern(pattern) && pattern == model {
+				return b, model, nil
+			}
+		}
+	}
+
+	for _, b := range r.backends {
+		for _, pattern := range b.Models {
+			if isPattern(pattern) {
+				if ok, _ := path.Match(pattern, model); ok {
+					return b, model, nil
+				}
+			}
+		}
+	}
+
+	if r.defaultBackend != nil {
+		return r.defaultBackend, model, nil
+	}
+
+	return nil, "", fmt.Errorf("%w: %s", ErrModelNotFound, model)
+}
+
+// targets returns the ordered list of backend and model pairs to try for
+// model: the routed backend, its fallback backends, then any fallback models.
+// Backends with an open circuit are skipped.
+func (r *Router) targets(model string) ([]target, error) {
+	backend, resolved, err := r.Route(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []target
+	seen := make(map[target]bool)
+	skipped := false
+	add := func(t target) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		if !t.backend.available() {
+			skipped = true
+			return
+		}
+		targets = append(targets, t)
+	}
+
+	add(target{backend, resolved})
+	for _, name := range backend.Fallbacks {
+		add(target{r.byName[name], resolved})
+	}
+
+	fallbackModels := r.fallbackModels[resolved]
+	if len(fallbackModels) == 0 {
+		fallbackModels = r.fallbackModels[model]
+	}
+	for _, fallbackModel := range fallbackModels {
+		fallbackBackend, fallbackResolved, err := r.Route(fallbackModel)
+		if err != nil {
+			log.Printf("Skipping fallback model %s for %s: %v", fallbackModel, model, err)
+			continue
+		}
+		add(target{fallbackBackend, fallbackResolved})
+	}
+
+	if len(targets) == 0 && skipped {
+		return nil, fmt.Errorf("no backend available for model %s: %w", model, resilience.ErrCircuitOpen)
+	}
+
+	return targets, nil
+}
+
+// ChatCompletion routes a chat completion to the backend serving its model,
+// failing over to fallbacks when a backend is exhausted by transient errors
+func (r *Router) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	targets, err := r.targets(req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, t := range targets {
+		var resp *models.ChatCompletionResponse
+		resp, err = t.backend.Provider.ChatCompletion(ctx, withModel(req, t.model))
+		if err == nil {
+			return resp, nil
+		}
+		if !resilience.IsRetryable(ctx, err) {
+			return nil, err
+		}
+		if i < len(targets)-1 {
+			log.Printf("Backend %s failed for model %s, failing over: %v", t.backend.Name, t.model, err)
+		}
+	}
+
+	return nil, err
+}
+
+// ChatCompletionStream routes a streaming chat completion to the backend
+// serving its model. Failover only happens before the first chunk has been
+// delivered to the caller.
+func (r *Router) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	targets, err := r.targets(req.Model)
+	if err != nil {
+		return err
+	}
+
+	delivered := false
+	for i, t := range targets {
+		err = t.backend.Provider.ChatCompletionStream(ctx, withModel(req, t.model), func(chunk *models.ChatCompletionChunk) error {
+			delivered = true
+			return onChunk(chunk)
+		})
+		if err == nil || delivered || !resilience.IsRetryable(ctx, err) {
+			return err
+		}
+		if i < len(targets)-1 {
+			log.Printf("Backend %s failed for model %s, failing over: %v", t.backend.Name, t.model, err)
+		}
+	}
+
+	return err
+}
+
+// Embed routes an embeddings request to the backend serving its model,
+// failing over to fallback backends. Fallback models are never used: their
+// vectors would not be comparable with the requested model's.
+func (r *Router) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	_, resolved, err := r.Route(req.Model)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := r.targets(req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fmt.Errorf("no backend available for model %s: %w", req.Model, resilience.ErrCircuitOpen)
+	for i, t := range targets {
+		if t.model != resolved {
+			continue
+		}
+
+		routed := *req
+		routed.Model = t.model
+		var resp *models.EmbeddingResponse
+		resp, err = t.backend.Provider.Embed(ctx, &routed)
+		if err == nil {
+			return resp, nil
+		}
+		if !resilience.IsRetryable(ctx, err) {
+			return nil, err
+		}
+		if i < len(targets)-1 {
+			log.Printf("Backend %s failed for model %s, failing over: %v", t.backend.Name, t.model, err)
+		}
+	}
+
+	return nil, err
+}
+
+// ListModels lists the models of every backend, tagged with the backend's
+// name. A model is only listed by the backend its requests are routed to.
+// Backends that fail are logged and skipped; an error is returned only
+// when none of them answer.
+func (r *Router) ListModels(ctx context.Context) ([]models.Model, error) {
+	var result []models.Model
+	var errs []error
+	seen := make(map[string]bool)
+	for _, b := range r.backends {
+		list, err := b.Provider.ListModels(ctx)
+		if err != nil {
+			log.Printf("Failed to list models of backend %s: %v", b.Name, err)
+			errs = append(errs, fmt.Errorf("backend %q: %w", b.Name, err))
+			continue
+		}
+
+		for _, m := range list {
+			routed, resolved, err := r.Route(m.ID)
+			if err != nil || routed != b || resolved != m.ID || seen[m.ID] {
+				continue
+			}
+			seen[m.ID] = true
+			m.Backend = b.Name
+			result = append(result, m)
+		}
+	}
+
+	if len(errs) > 0 && len(errs) == len(r.backends) {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// HealthCheck checks every backend and reports all failures
+func (r *Router) HealthCheck(ctx context.Context) error {
+	var errs []error
+	for _, b := range r.backends {
+		if err := b.Provider.HealthCheck(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("backend %q: %w", b.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// BackendHealth checks every backend and reports its status and circuit
+// breaker state
+func (r *Router) BackendHealth(ctx context.Context) []models.BackendHealth {
+	report := make([]models.BackendHealth, 0, len(r.backends))
+	for _, b := range r.backends {
+		health := models.BackendHealth{
+			Name:   b.Name,
+			Status: "healthy",
+		}
+
+		if err := b.Provider.HealthCheck(ctx); err != nil {
+			health.Status = "unhealthy"
+			health.Error = err.Error()
+		}
+
+		if b.Breaker != nil {
+			state := b.Breaker.State()
+			health.Circuit = state.String()
+			if state == resilience.StateOpen {
+				health.Status = "unhealthy"
+			}
+		}
+
+		report = append(report, health)
+	}
+
+	return report
+}
+
+// isPattern reports whether a model entry contains glob metacharacters
+func isPattern(model string) bool {
+	return strings.ContainsAny(model, "*?[")
+}
+
+// withModel returns a shallow copy of req targeting model
+func withModel(req *models.ChatCompletionRequest, model string) *models.ChatCompletionRequest {
+	if req.Model == model {
+		return req
+	}
+
+	routed := *req
+	routed.Model = model
+	return &routed
+}
@@ -0,0 +1,387 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/resilience"
+)
+
+// mockProvider records the model of the last request it received
+type mockProvider struct {
+	name        string
+	lastModel   string
+	healthError error
+	chatError   error
+	calls       int
+	models      []string
+}
+
+func (m *mockProvider) HealthCheck(ctx context.Context) error {
+	return m.healthError
+}
+
+func (m *mockProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	m.lastModel = req.Model
+	m.calls++
+	if m.chatError != nil {
+		return nil, m.chatError
+	}
+	return &models.ChatCompletionResponse{ID: m.name, Model: req.Model}, nil
+}
+
+func (m *mockProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	m.lastModel = req.Model
+	m.calls++
+	if m.chatError != nil {
+		return m.chatError
+	}
+	return onChunk(&models.ChatCompletionChunk{ID: m.name, Model: req.Model})
+}
+
+func (m *mockProvider) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	m.lastModel = req.Model
+	m.calls++
+	if m.chatError != nil {
+		return nil, m.chatError
+	}
+	return &models.EmbeddingResponse{Object: m.name, Model: req.Model}, nil
+}
+
+func (m *mockProvider) ListModels(ctx context.Context) ([]models.Model, error) {
+	if m.healthError != nil {
+		return nil, m.healthError
+	}
+	var list []models.Model
+	for _, id := range m.models {
+		list = append(list, models.Model{ID: id, Object: "model"})
+	}
+	return list, nil
+}
+
+func newTestRouter(t *testing.T, defaultBackend string) (*Router, *mockProvider, *mockProvider) {
+	t.Helper()
+
+	cloud := &mockProvider{name: "cloud"}
+	local := &mockProvider{name: "local"}
+
+	r, err := New([]*Backend{
+		{Name: "cloud", Provider: cloud, Models: []string{"gpt-4o", "gpt-4o-mini"}},
+		{Name: "local", Provider: local, Models: []string{"llama3*", "gpt-4o-local"}},
+	}, config.RoutingConfig{
+		Aliases:        map[string]string{"fast": "llama3.1:8b"},
+		DefaultBackend: defaultBackend,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	return r, cloud, local
+}
+
+func TestRouter_Route(t *testing.T) {
+	r, _, _ := newTestRouter(t, "")
+
+	tests := []struct {
+		name          string
+		model         string
+		expectBackend string
+		expectModel   string
+		wantErr       bool
+	}{
+		{name: "exact", model: "gpt-4o", expectBackend: "cloud", expectModel: "gpt-4o"},
+		{name: "glob", model: "llama3.1:70b", expectBackend: "local", expectModel: "llama3.1:70b"},
+		{name: "alias", model: "fast", expectBackend: "local", expectModel: "llama3.1:8b"},
+		{name: "unknown", model: "mistral", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, model, err := r.Route(tt.model)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Route() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrModelNotFound) {
+					t.Errorf("expected ErrModelNotFound, got %v", err)
+				}
+				return
+			}
+
+			if backend.Name != tt.expectBackend {
+				t.Errorf("expected backend %s, got %s", tt.expectBackend, backend.Name)
+			}
+
+			if model != tt.expectModel {
+				t.Errorf("expected model %s, got %s", tt.expectModel, model)
+			}
+		})
+	}
+}
+
+func TestRouter_DefaultBackend(t *testing.T) {
+	r, _, _ := newTestRouter(t, "cloud")
+
+	backend, _, err := r.Route("mistral")
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+
+	if backend.Name != "cloud" {
+		t.Errorf("expected default backend cloud, got %s", backend.Name)
+	}
+}
+
+func TestRouter_ChatCompletion(t *testing.T) {
+	r, cloud, local := newTestRouter(t, "")
+
+	req := &models.ChatCompletionRequest{
+		Model:    "fast",
+		Messages: []models.ChatMessage{{Role: "user", Content: "Hello"}},
+	}
+
+	resp, err := r.ChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	if resp.ID != "local" || local.lastModel != "llama3.1:8b" {
+		t.Errorf("expected alias to be resolved and routed to local, got %s/%s", resp.ID, local.lastModel)
+	}
+
+	if req.Model != "fast" {
+		t.Errorf("expected caller request to be left untouched, got %s", req.Model)
+	}
+
+	if cloud.lastModel != "" {
+		t.Errorf("expected cloud backend not to be called")
+	}
+
+	var streamed string
+	err = r.ChatCompletionStream(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"}, func(chunk *models.ChatCompletionChunk) error {
+		streamed = chunk.ID
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if streamed != "cloud" {
+		t.Errorf("expected stream routed to cloud, got %s", streamed)
+	}
+}
+
+func TestRouter_HealthCheck(t *testing.T) {
+	r, cloud, _ := newTestRouter(t, "")
+
+	if err := r.HealthCheck(context.Background()); err != nil {
+		t.Errorf("expected healthy, got %v", err)
+	}
+
+	cloud.healthError = context.DeadlineExceeded
+	if err := r.HealthCheck(context.Background()); err == nil {
+		t.Error("expected error when a backend is unhealthy")
+	}
+}
+
+func TestRouter_Failover(t *testing.T) {
+	primary := &mockProvider{name: "primary", chatError: &client.StatusError{StatusCode: 503}}
+	secondary := &mockProvider{name: "secondary", chatError: &client.StatusError{StatusCode: 503}}
+	local := &mockProvider{name: "local"}
+
+	r, err := New([]*Backend{
+		{Name: "primary", Provider: primary, Models: []string{"gpt-4o"}, Fallbacks: []string{"secondary"}},
+		{Name: "secondary", Provider: secondary},
+		{Name: "local", Provider: local, Models: []string{"llama3*"}},
+	}, config.RoutingConfig{
+		FallbackModels: map[string][]string{"gpt-4o": {"unknown-model", "llama3.1"}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	resp, err := r.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	if resp.ID != "local" || resp.Model != "llama3.1" {
+		t.Errorf("expected fallback model llama3.1 on local, got %s/%s", resp.ID, resp.Model)
+	}
+
+	if primary.calls != 1 || secondary.calls != 1 || secondary.lastModel != "gpt-4o" {
+		t.Errorf("expected primary then secondary to be tried with gpt-4o")
+	}
+
+	// Client errors are returned immediately without failover
+	primary.chatError = &client.StatusError{StatusCode: 400}
+	local.calls = 0
+	if _, err := r.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"}); err == nil {
+		t.Error("expected client error to be returned")
+	}
+
+	if local.calls != 0 {
+		t.Errorf("expected no failover on client errors")
+	}
+
+	// Streams fail over the same way before anything is delivered
+	primary.chatError = &client.StatusError{StatusCode: 502}
+	var streamed string
+	err = r.ChatCompletionStream(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"}, func(chunk *models.ChatCompletionChunk) error {
+		streamed = chunk.ID
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if streamed != "local" {
+		t.Errorf("expected stream to fail over to local, got %s", streamed)
+	}
+}
+
+func TestRouter_Embed(t *testing.T) {
+	primary := &mockProvider{name: "primary", chatError: &client.StatusError{StatusCode: 503}}
+	secondary := &mockProvider{name: "secondary"}
+	local := &mockProvider{name: "local"}
+
+	r, err := New([]*Backend{
+		{Name: "primary", Provider: primary, Models: []string{"text-embedding-3-small"}, Fallbacks: []string{"secondary"}},
+		{Name: "secondary", Provider: secondary},
+		{Name: "local", Provider: local, Models: []string{"nomic-embed-text"}},
+	}, config.RoutingConfig{
+		FallbackModels: map[string][]string{"text-embedding-3-small": {"nomic-embed-text"}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	req := &models.EmbeddingRequest{Model: "text-embedding-3-small", Input: []string{"hello"}}
+	resp, err := r.Embed(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if resp.Object != "secondary" || secondary.lastModel != "text-embedding-3-small" {
+		t.Errorf("expected failover to secondary with the same model, got %s", resp.Object)
+	}
+
+	// Fallback models are never used for embeddings
+	secondary.chatError = &client.StatusError{StatusCode: 503}
+	if _, err := r.Embed(context.Background(), req); err == nil {
+		t.Error("expected an error when every backend for the model fails")
+	}
+	if local.calls != 0 {
+		t.Errorf("expected no fallback to another embedding model, got %d calls", local.calls)
+	}
+
+	if _, err := r.Embed(context.Background(), &models.EmbeddingRequest{Model: "unknown"}); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("expected ErrModelNotFound, got %v", err)
+	}
+}
+
+func TestRouter_ListModels(t *testing.T) {
+	cloud := &mockProvider{name: "cloud", models: []string{"gpt-4o", "gpt-4o-mini", "llama3.1"}}
+	local := &mockProvider{name: "local", models: []string{"llama3.1", "llama3.2", "mistral"}}
+
+	r, err := New([]*Backend{
+		{Name: "cloud", Provider: cloud, Models: []string{"gpt-4o*"}},
+		{Name: "local", Provider: local, Models: []string{"llama3*"}},
+	}, config.RoutingConfig{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	list, err := r.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+
+	// Models are only listed by the backend serving them
+	expected := []string{"gpt-4o@cloud", "gpt-4o-mini@cloud", "llama3.1@local", "llama3.2@local"}
+	var got []string
+	for _, m := range list {
+		got = append(got, m.ID+"@"+m.Backend)
+	}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// One failing backend is skipped, all failing is an error
+	cloud.healthError = errors.New("down")
+	if list, err := r.ListModels(context.Background()); err != nil || len(list) != 2 {
+		t.Errorf("expected the local models only, got %v %v", list, err)
+	}
+	local.healthError = errors.New("down")
+	if _, err := r.ListModels(context.Background()); err == nil {
+		t.Error("expected an error when every backend fails")
+	}
+}
+
+func TestRouter_SkipsOpenCircuit(t *testing.T) {
+	primary := &mockProvider{name: "primary"}
+	secondary := &mockProvider{name: "secondary"}
+
+	breaker := resilience.NewCircuitBreaker(resilience.BreakerConfig{
+		FailureRatio:  0.5,
+		MinRequests:   1,
+		Window:        time.Minute,
+		ProbeInterval: time.Hour,
+	})
+	breaker.Allow()
+	breaker.Record(context.Background(), &client.StatusError{StatusCode: 503})
+
+	r, err := New([]*Backend{
+		{Name: "primary", Provider: primary, Models: []string{"gpt-4o"}, Fallbacks: []string{"secondary"}, Breaker: breaker},
+		{Name: "secondary", Provider: secondary},
+	}, config.RoutingConfig{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	resp, err := r.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	if resp.ID != "secondary" || primary.calls != 0 {
+		t.Errorf("expected open backend to be skipped, got %s after %d primary calls", resp.ID, primary.calls)
+	}
+
+	health := r.BackendHealth(context.Background())
+	if health[0].Circuit != "open" || health[0].Status != "unhealthy" {
+		t.Errorf("expected open circuit in health report, got %+v", health[0])
+	}
+
+	if health[1].Status != "healthy" || health[1].Circuit != "" {
+		t.Errorf("expected healthy backend without breaker, got %+v", health[1])
+	}
+
+	// With no fallback left the request fails fast
+	r, _ = New([]*Backend{
+		{Name: "primary", Provider: primary, Models: []string{"gpt-4o"}, Breaker: breaker},
+	}, config.RoutingConfig{})
+	if _, err := r.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"}); !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestNew_UnknownFallback(t *testing.T) {
+	_, err := New([]*Backend{{Name: "a", Provider: &mockProvider{}, Fallbacks: []string{"b"}}}, config.RoutingConfig{})
+	if err == nil {
+		t.Error("expected error for unknown fallback backend")
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	_, err := New([]*Backend{{Name: "bad", Provider: &mockProvider{}, Models: []string{"llama["}}}, config.RoutingConfig{})
+	if err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
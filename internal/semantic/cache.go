@@ -0,0 +1,131 @@
+package semantic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Cache serves cached answers to prompts that are worded differently but
+// mean the same. The final user message is embedded and compared with
+// earlier ones in the same scope: same model, persona prompt, sampling
+// parameters and preceding messages.
+type Cache struct {
+	embedder          client.Embedder
+	model             string
+	threshold         float64
+	ttl               time.Duration
+	deterministicOnly bool
+	index             *Index
+	now               func() time.Time
+}
+
+// Query is an embedded prompt, kept between Lookup and Store so a miss is
+// embedded only once
+type Query struct {
+	scope  string
+	vector []float64
+}
+
+// New creates a semantic cache that embeds prompts with embedder, normally
+// the same client chat requests go through so embeddings are routed, retried,
+// rate limited and accounted like any other call
+func New(embedder client.Embedder, cfg config.SemanticConfig) *Cache {
+	return &Cache{
+		embedder:          embedder,
+		model:             cfg.Model,
+		threshold:         cfg.Threshold,
+		ttl:               time.Duration(cfg.TTLSeconds) * time.Second,
+		deterministicOnly: cfg.DeterministicOnly,
+		index:             NewIndex(cfg.MaxEntries),
+		now:               time.Now,
+	}
+}
+
+// Cacheable reports whether req may be answered from the cache: a
+// non-streaming request that does not opt out with cache: false, ends with a
+// user message and, with deterministic_only, is sampled at temperature 0
+func (c *Cache) Cacheable(req *models.ChatCompletionRequest) bool {
+	if req.Stream != nil && *req.Stream {
+		return false
+	}
+	if req.Cache != nil && !*req.Cache {
+		return false
+	}
+	if c.deterministicOnly && (req.Temperature == nil || *req.Temperature != 0) {
+		return false
+	}
+	if len(req.Messages) == 0 {
+		return false
+	}
+	last := req.Messages[len(req.Messages)-1]
+	return last.Role == "user" && last.Content != ""
+}
+
+// Lookup embeds the final user message of req and returns the cached answer
+// to the most similar prompt in its scope, with its similarity. The returned
+// query is passed to Store after a miss.
+func (c *Cache) Lookup(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, float64, Query, error) {
+	last := req.Messages[len(req.Messages)-1]
+	resp, err := c.embedder.Embed(ctx, &models.EmbeddingRequest{Model: c.model, Input: []string{last.Content}})
+	if err != nil {
+		return nil, 0, Query{}, fmt.Errorf("failed to embed prompt: %w", err)
+	}
+	if len(resp.Data) != 1 || len(resp.Data[0].Embedding) == 0 {
+		return nil, 0, Query{}, fmt.Errorf("failed to embed prompt: expected 1 embedding, got %d", len(resp.Data))
+	}
+
+	q := Query{scope: scope(req), vector: resp.Data[0].Embedding}
+	cached, similarity, ok := c.index.Search(q.scope, q.vector, c.threshold, c.now())
+	if !ok {
+		return nil, 0, q, nil
+	}
+	return cached, similarity, q, nil
+}
+
+// Store caches resp as the answer to q for the configured TTL
+func (c *Cache) Store(q Query, resp *models.ChatCompletionResponse) {
+	if q.vector == nil {
+		return
+	}
+
+	now := c.now()
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = now.Add(c.ttl)
+	}
+	c.index.Add(q.scope, q.vector, resp, expiresAt, now)
+}
+
+// scope hashes everything about req but its final message, so only prompts
+// asked in the same setting can match
+func scope(req *models.ChatCompletionRequest) string {
+	data, _ := json.Marshal(struct {
+		Model         string               `json:"model"`
+		Context       []models.ChatMessage `json:"context"`
+		PersonaPrompt string               `json:"persona_prompt"`
+		PersonaMerge  string               `json:"persona_merge"`
+		Temperature   *float64             `json:"temperature"`
+		MaxTokens     *int                 `json:"max_tokens"`
+		Tools         []models.Tool        `json:"tools"`
+		ToolChoice    *models.ToolChoice   `json:"tool_choice"`
+	}{
+		Model:         req.Model,
+		Context:       req.Messages[:len(req.Messages)-1],
+		PersonaPrompt: req.PersonaPrompt,
+		PersonaMerge:  req.PersonaMerge,
+		Temperature:   req.Temperature,
+		MaxTokens:     req.MaxTokens,
+		Tools:         req.Tools,
+		ToolChoice:    req.ToolChoice,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,154 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// fakeEmbedder returns fixed vectors for known texts
+type fakeEmbedder struct {
+	vectors map[string][]float64
+	calls   int
+	err     error
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := &models.EmbeddingResponse{Object: "list", Model: req.Model}
+	for i, text := range req.Input {
+		resp.Data = append(resp.Data, models.Embedding{Object: "embedding", Index: i, Embedding: f.vectors[text]})
+	}
+	return resp, nil
+}
+
+func newFakeEmbedder() *fakeEmbedder {
+	return &fakeEmbedder{vectors: map[string][]float64{
+		"How do I reset my password?":  {1, 0, 0},
+		"How can I reset my password?": {0.98, 0.2, 0},
+		"What are your opening hours?": {0, 1, 0},
+	}}
+}
+
+func testSemanticConfig() config.SemanticConfig {
+	return config.SemanticConfig{
+		Model:             "nomic-embed-text",
+		Threshold:         0.95,
+		MaxEntries:        10,
+		TTLSeconds:        60,
+		DeterministicOnly: true,
+	}
+}
+
+func question(content string) *models.ChatCompletionRequest {
+	zero := 0.0
+	return &models.ChatCompletionRequest{
+		Model:         "llama3",
+		PersonaPrompt: "You are the helpdesk.",
+		Temperature:   &zero,
+		Messages:      []models.ChatMessage{{Role: "user", Content: content}},
+	}
+}
+
+func TestCache_LookupAndStore(t *testing.T) {
+	embedder := newFakeEmbedder()
+	c := New(embedder, testSemanticConfig())
+	ctx := context.Background()
+
+	resp, _, q, err := c.Lookup(ctx, question("How do I reset my password?"))
+	if err != nil || resp != nil {
+		t.Fatalf("expected a miss on an empty cache, got %v %v", resp, err)
+	}
+	c.Store(q, answer("Use the reset link."))
+
+	tests := []struct {
+		name     string
+		req      *models.ChatCompletionRequest
+		expected bool
+	}{
+		{name: "reworded", req: question("How can I reset my password?"), expected: true},
+		{name: "different question", req: question("What are your opening hours?")},
+		{name: "other model", req: func() *models.ChatCompletionRequest {
+			r := question("How can I reset my password?")
+			r.Model = "mistral"
+			return r
+		}()},
+		{name: "other persona", req: func() *models.ChatCompletionRequest {
+			r := question("How can I reset my password?")
+			r.PersonaPrompt = "You are a pirate."
+			return r
+		}()},
+		{name: "other history", req: func() *models.ChatCompletionRequest {
+			r := question("How can I reset my password?")
+			r.Messages = append([]models.ChatMessage{{Role: "user", Content: "Hi"}, {Role: "assistant", Content: "Hello"}}, r.Messages...)
+			return r
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, similarity, _, err := c.Lookup(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("Lookup failed: %v", err)
+			}
+			if (resp != nil) != tt.expected {
+				t.Fatalf("expected hit = %v, got %+v", tt.expected, resp)
+			}
+			if tt.expected && (resp.Choices[0].Message.Content != "Use the reset link." || similarity < 0.95) {
+				t.Errorf("unexpected hit %+v with similarity %v", resp, similarity)
+			}
+		})
+	}
+}
+
+func TestCache_LookupError(t *testing.T) {
+	embedder := newFakeEmbedder()
+	embedder.err = errors.New("backend down")
+	c := New(embedder, testSemanticConfig())
+
+	if _, _, _, err := c.Lookup(context.Background(), question("How do I reset my password?")); err == nil {
+		t.Error("expected the embedding error to be returned")
+	}
+
+	// A failed lookup yields an empty query, which Store ignores
+	c.Store(Query{}, answer("ignored"))
+	if c.index.Len() != 0 {
+		t.Error("expected nothing to be stored for an empty query")
+	}
+}
+
+func TestCache_Cacheable(t *testing.T) {
+	c := New(newFakeEmbedder(), testSemanticConfig())
+	yes, no, sampled := true, false, 0.7
+
+	tests := []struct {
+		name     string
+		modify   func(*models.ChatCompletionRequest)
+		expected bool
+	}{
+		{name: "question", modify: func(*models.ChatCompletionRequest) {}, expected: true},
+		{name: "stream", modify: func(r *models.ChatCompletionRequest) { r.Stream = &yes }},
+		{name: "opted out", modify: func(r *models.ChatCompletionRequest) { r.Cache = &no }},
+		{name: "sampled", modify: func(r *models.ChatCompletionRequest) { r.Temperature = &sampled }},
+		{name: "ends with assistant", modify: func(r *models.ChatCompletionRequest) {
+			r.Messages = append(r.Messages, models.ChatMessage{Role: "assistant", Content: "Sure"})
+		}},
+		{name: "no messages", modify: func(r *models.ChatCompletionRequest) { r.Messages = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := question("How do I reset my password?")
+			tt.modify(req)
+			if got := c.Cacheable(req); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
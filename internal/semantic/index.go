@@ -0,0 +1,130 @@
+package semantic
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Index is an in-process vector index of cached answers. Vectors are stored
+// normalised so cosine similarity is a dot product, and searched linearly
+// within a scope; that is fast enough for the few thousand entries a
+// bridge keeps.
+type Index struct {
+	mu      sync.Mutex
+	size    int
+	entries []entry // oldest first
+}
+
+// entry is one cached answer
+type entry struct {
+	scope     string
+	vector    []float32
+	response  models.ChatCompletionResponse
+	expiresAt time.Time // zero for no expiry
+}
+
+// NewIndex creates an empty index holding at most size entries, or any
+// number when size is 0
+func NewIndex(size int) *Index {
+	return &Index{size: size}
+}
+
+// Search returns the answer in scope whose vector is most similar to vector,
+// if its similarity is at least threshold. Expired entries are skipped.
+func (x *Index) Search(scope string, vector []float64, threshold float64, now time.Time) (*models.ChatCompletionResponse, float64, bool) {
+	query := normalize(vector)
+	if query == nil {
+		return nil, 0, false
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	best, bestScore := -1, threshold
+	for i, e := range x.entries {
+		if e.scope != scope || len(e.vector) != len(query) || expired(e, now) {
+			continue
+		}
+		if score := dot(e.vector, query); score >= bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return nil, 0, false
+	}
+
+	resp := x.entries[best].response
+	resp.Choices = append([]models.Choice(nil), resp.Choices...)
+	return &resp, bestScore, true
+}
+
+// Add stores an answer, dropping expired entries and then the oldest ones
+// beyond the size
+func (x *Index) Add(scope string, vector []float64, resp *models.ChatCompletionResponse, expiresAt time.Time, now time.Time) {
+	normalized := normalize(vector)
+	if normalized == nil {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	kept := x.entries[:0]
+	for _, e := range x.entries {
+		if !expired(e, now) {
+			kept = append(kept, e)
+		}
+	}
+	x.entries = kept
+
+	e := entry{scope: scope, vector: normalized, response: *resp, expiresAt: expiresAt}
+	e.response.Choices = append([]models.Choice(nil), resp.Choices...)
+	x.entries = append(x.entries, e)
+	if x.size > 0 && len(x.entries) > x.size {
+		x.entries = append([]entry(nil), x.entries[len(x.entries)-x.size:]...)
+	}
+}
+
+// Len returns the number of stored entries, including expired ones not yet
+// dropped
+func (x *Index) Len() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return len(x.entries)
+}
+
+// normalize returns vector scaled to unit length, or nil for a zero or empty
+// vector
+func normalize(vector []float64) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += v * v
+	}
+	if sum == 0 {
+		return nil
+	}
+
+	norm := math.Sqrt(sum)
+	out := make([]float32, len(vector))
+	for i, v := range vector {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+// dot returns the dot product of two vectors of equal length
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// expired reports whether e is past its expiry at now
+func expired(e entry, now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
@@ -0,0 +1,109 @@
+package semantic
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func answer(content string) *models.ChatCompletionResponse {
+	return &models.ChatCompletionResponse{
+		ID:      "resp-" + content,
+		Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: content}}},
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := NewIndex(0)
+	x.Add("a", []float64{1, 0, 0}, answer("x"), time.Time{}, now)
+	x.Add("a", []float64{0, 2, 0}, answer("y"), time.Time{}, now) // normalised on insert
+	x.Add("b", []float64{1, 0, 0}, answer("other scope"), time.Time{}, now)
+
+	tests := []struct {
+		name      string
+		scope     string
+		vector    []float64
+		threshold float64
+		expected  string
+	}{
+		{name: "exact", scope: "a", vector: []float64{1, 0, 0}, threshold: 0.99, expected: "x"},
+		{name: "scaled", scope: "a", vector: []float64{0, 0.5, 0}, threshold: 0.99, expected: "y"},
+		{name: "near", scope: "a", vector: []float64{1, 0.1, 0}, threshold: 0.99, expected: "x"},
+		{name: "below threshold", scope: "a", vector: []float64{1, 1, 0}, threshold: 0.9},
+		{name: "other scope", scope: "b", vector: []float64{0, 1, 0}, threshold: 0.9},
+		{name: "unknown scope", scope: "c", vector: []float64{1, 0, 0}, threshold: 0.5},
+		{name: "dimension mismatch", scope: "a", vector: []float64{1, 0}, threshold: 0.5},
+		{name: "zero vector", scope: "a", vector: []float64{0, 0, 0}, threshold: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, score, ok := x.Search(tt.scope, tt.vector, tt.threshold, now)
+			if tt.expected == "" {
+				if ok {
+					t.Errorf("expected no match, got %s (%.3f)", resp.Choices[0].Message.Content, score)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected %s, got no match", tt.expected)
+			}
+			if got := resp.Choices[0].Message.Content; got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+			if score < tt.threshold || score > 1+1e-6 {
+				t.Errorf("expected a similarity in [%v, 1], got %v", tt.threshold, score)
+			}
+		})
+	}
+}
+
+func TestIndex_Expiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := NewIndex(0)
+	x.Add("a", []float64{1, 0}, answer("x"), now.Add(time.Minute), now)
+
+	if _, _, ok := x.Search("a", []float64{1, 0}, 0.9, now.Add(59*time.Second)); !ok {
+		t.Error("expected a match before expiry")
+	}
+	if _, _, ok := x.Search("a", []float64{1, 0}, 0.9, now.Add(time.Minute)); ok {
+		t.Error("expected no match after expiry")
+	}
+
+	// Expired entries are dropped on the next insert
+	x.Add("a", []float64{0, 1}, answer("y"), time.Time{}, now.Add(time.Hour))
+	if x.Len() != 1 {
+		t.Errorf("expected the expired entry to be dropped, got %d entries", x.Len())
+	}
+}
+
+func TestIndex_Evicts(t *testing.T) {
+	now := time.Now()
+	x := NewIndex(2)
+	x.Add("a", []float64{1, 0, 0}, answer("x"), time.Time{}, now)
+	x.Add("a", []float64{0, 1, 0}, answer("y"), time.Time{}, now)
+	x.Add("a", []float64{0, 0, 1}, answer("z"), time.Time{}, now)
+
+	if x.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", x.Len())
+	}
+	if _, _, ok := x.Search("a", []float64{1, 0, 0}, 0.9, now); ok {
+		t.Error("expected the oldest entry to be evicted")
+	}
+	if _, _, ok := x.Search("a", []float64{0, 0, 1}, 0.9, now); !ok {
+		t.Error("expected the newest entry to be kept")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	v := normalize([]float64{3, 4})
+	if math.Abs(float64(v[0])-0.6) > 1e-6 || math.Abs(float64(v[1])-0.8) > 1e-6 {
+		t.Errorf("expected [0.6 0.8], got %v", v)
+	}
+	if normalize(nil) != nil || normalize([]float64{0, 0}) != nil {
+		t.Error("expected nil for empty and zero vectors")
+	}
+}
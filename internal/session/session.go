@@ -0,0 +1,234 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Session errors
+var (
+	ErrNotFound = errors.New("conversation not found")
+	ErrInvalid  = errors.New("invalid conversation")
+)
+
+// Manager keeps server-side conversations on top of a Store. Conversations
+// belong to the authenticated principal that created them; other callers
+// see them as not found.
+type Manager struct {
+	store Store
+	now   func() time.Time
+
+	mu    sync.Mutex
+	turns map[string]chan struct{} // conversation ID -> closed when its turn ends
+}
+
+// NewManager creates a manager backed by store
+func NewManager(store Store) *Manager {
+	return &Manager{
+		store: store,
+		now:   time.Now,
+		turns: make(map[string]chan struct{}),
+	}
+}
+
+// Turn is a request continuing a conversation. It holds the conversation
+// from Prepare until Record or Release, so concurrent requests on the same
+// conversation are answered one after the other, each on the full history.
+type Turn struct {
+	m        *Manager
+	id       string
+	messages []models.ChatMessage
+	release  func()
+}
+
+// Create starts a conversation owned by the caller in ctx. Its messages, if
+// any, seed the history.
+func (m *Manager) Create(ctx context.Context, c models.Conversation) (models.Conversation, error) {
+	if err := validateMessages(c.Messages); err != nil {
+		return models.Conversation{}, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return models.Conversation{}, err
+	}
+
+	now := m.now().UTC()
+	c.ID = id
+	c.Owner = owner(ctx)
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	if err := m.store.Put(c); err != nil {
+		return models.Conversation{}, err
+	}
+	return c, nil
+}
+
+// Get returns a conversation of the caller in ctx
+func (m *Manager) Get(ctx context.Context, id string) (models.Conversation, error) {
+	c, err := m.store.Get(id)
+	if err != nil {
+		return models.Conversation{}, err
+	}
+	if c.Owner != owner(ctx) {
+		return models.Conversation{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return c, nil
+}
+
+// List returns the caller's conversations without their messages, most
+// recently updated first
+func (m *Manager) List(ctx context.Context) ([]models.Conversation, error) {
+	conversations, err := m.store.List(owner(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if conversations == nil {
+		conversations = []models.Conversation{}
+	}
+	return conversations, nil
+}
+
+// Delete removes a conversation of the caller in ctx
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	if _, err := m.Get(ctx, id); err != nil {
+		return err
+	}
+	return m.store.Delete(id)
+}
+
+// Prepare expands a request continuing req.ConversationID: the stored
+// history is prepended to the request's messages and the conversation's
+// model and persona fill in empty fields. It waits for any turn in progress
+// on the conversation, and returns the new turn, which must be recorded once
+// the upstream call succeeds or released otherwise.
+func (m *Manager) Prepare(ctx context.Context, req *models.ChatCompletionRequest) (*Turn, error) {
+	release, err := m.acquire(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := m.Get(ctx, req.ConversationID)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if len(req.Messages) == 0 {
+		release()
+		return nil, fmt.Errorf("%w: messages are required", ErrInvalid)
+	}
+
+	turn := &Turn{m: m, id: c.ID, messages: append([]models.ChatMessage(nil), req.Messages...), release: release}
+	req.Messages = append(append([]models.ChatMessage(nil), c.Messages...), turn.messages...)
+	if req.Model == "" {
+		req.Model = c.Model
+	}
+	if req.PersonaID == "" && req.PersonaPrompt == "" {
+		req.PersonaID = c.PersonaID
+	}
+	return turn, nil
+}
+
+// acquire waits until no other turn is in progress on conversation id and
+// claims it. The returned function ends the turn; it may be called again.
+func (m *Manager) acquire(ctx context.Context, id string) (func(), error) {
+	for {
+		m.mu.Lock()
+		busy, ok := m.turns[id]
+		if !ok {
+			done := make(chan struct{})
+			m.turns[id] = done
+			m.mu.Unlock()
+
+			var once sync.Once
+			return func() {
+				once.Do(func() {
+					m.mu.Lock()
+					delete(m.turns, id)
+					m.mu.Unlock()
+					close(done)
+				})
+			}, nil
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-busy:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// ID returns the conversation the turn continues
+func (t *Turn) ID() string {
+	return t.id
+}
+
+// Record appends the turn and the assistant's reply to the history and
+// ends the turn
+func (t *Turn) Record(reply models.ChatMessage) error {
+	defer t.release()
+
+	messages := append(append([]models.ChatMessage(nil), t.messages...), reply)
+	return t.m.store.Append(t.id, t.m.now().UTC(), messages...)
+}
+
+// Release ends the turn without recording it. It does nothing on a nil or
+// already recorded turn.
+func (t *Turn) Release() {
+	if t != nil {
+		t.release()
+	}
+}
+
+// Close closes the underlying store
+func (m *Manager) Close() error {
+	return m.store.Close()
+}
+
+// owner returns the key of the authenticated caller in ctx, or "" when
+// authentication is disabled
+func owner(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return p.Key()
+	}
+	return ""
+}
+
+// validateMessages checks the seed messages of a new conversation
+func validateMessages(messages []models.ChatMessage) error {
+	for i, msg := range messages {
+		if err := msg.Validate(); err != nil {
+			return fmt.Errorf("%w: message %d: %v", ErrInvalid, i, err)
+		}
+	}
+	return nil
+}
+
+// newID returns a random conversation ID
+func newID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate conversation ID: %w", err)
+	}
+	return "conv-" + hex.EncodeToString(b), nil
+}
+
+// sortByUpdated orders conversations most recently updated first
+func sortByUpdated(conversations []models.Conversation) {
+	sort.Slice(conversations, func(i, j int) bool {
+		if !conversations[i].UpdatedAt.Equal(conversations[j].UpdatedAt) {
+			return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+		}
+		return conversations[i].ID < conversations[j].ID
+	})
+}
@@ -0,0 +1,197 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestManager_Conversation(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	ctx := context.Background()
+
+	c, err := m.Create(ctx, models.Conversation{
+		Model:     "llama3",
+		PersonaID: "support",
+		Messages:  []models.ChatMessage{{Role: "system", Content: "Be brief."}},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !strings.HasPrefix(c.ID, "conv-") || c.CreatedAt.IsZero() {
+		t.Errorf("expected a generated ID and creation time, got %+v", c)
+	}
+
+	req := &models.ChatCompletionRequest{
+		ConversationID: c.ID,
+		Messages:       []models.ChatMessage{{Role: "user", Content: "Hello"}},
+	}
+	turn, err := m.Prepare(ctx, req)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[1].Content != "Hello" {
+		t.Errorf("expected history to be prepended, got %+v", req.Messages)
+	}
+	if req.Model != "llama3" || req.PersonaID != "support" {
+		t.Errorf("expected conversation defaults, got model %q persona %q", req.Model, req.PersonaID)
+	}
+
+	if err := turn.Record(models.ChatMessage{Role: "assistant", Content: "Hi!"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	got, err := m.Get(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	expected := []string{"Be brief.", "Hello", "Hi!"}
+	if len(got.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %+v", len(expected), got.Messages)
+	}
+	for i, content := range expected {
+		if got.Messages[i].Content != content {
+			t.Errorf("message %d: expected %q, got %q", i, content, got.Messages[i].Content)
+		}
+	}
+
+	// An inline persona prompt is not combined with the conversation's persona
+	req = &models.ChatCompletionRequest{
+		ConversationID: c.ID,
+		PersonaPrompt:  "Be a pirate.",
+		Messages:       []models.ChatMessage{{Role: "user", Content: "Again"}},
+	}
+	turn, err = m.Prepare(ctx, req)
+	if err != nil || req.PersonaID != "" {
+		t.Errorf("expected persona_id to stay empty, got %q (%v)", req.PersonaID, err)
+	}
+	turn.Release()
+
+	req = &models.ChatCompletionRequest{ConversationID: c.ID}
+	if _, err := m.Prepare(ctx, req); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for an empty turn, got %v", err)
+	}
+
+	if err := m.Delete(ctx, c.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	req = &models.ChatCompletionRequest{ConversationID: c.ID, Messages: []models.ChatMessage{{Role: "user", Content: "Hi"}}}
+	if _, err := m.Prepare(ctx, req); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a deleted conversation, got %v", err)
+	}
+}
+
+func TestManager_Owner(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	alice := auth.NewContext(context.Background(), &auth.Principal{ID: "alice"})
+	bob := auth.NewContext(context.Background(), &auth.Principal{ID: "bob"})
+
+	c, err := m.Create(alice, models.Conversation{})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if _, err := m.Get(bob, c.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected another caller's conversation to be hidden, got %v", err)
+	}
+	token := auth.NewContext(context.Background(), &auth.Principal{ID: "alice", Method: "jwt"})
+	if _, err := m.Get(token, c.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a token with the same subject not to see it, got %v", err)
+	}
+	if err := m.Delete(bob, c.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected another caller not to delete it, got %v", err)
+	}
+	if list, _ := m.List(bob); len(list) != 0 {
+		t.Errorf("expected bob to see no conversations, got %+v", list)
+	}
+	if list, _ := m.List(alice); len(list) != 1 {
+		t.Errorf("expected alice to see her conversation, got %+v", list)
+	}
+}
+
+func TestManager_CreateInvalid(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	_, err := m.Create(context.Background(), models.Conversation{Messages: []models.ChatMessage{{Role: "user"}}})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid, got %v", err)
+	}
+}
+
+func TestManager_CreateWithToolCalls(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	seed := []models.ChatMessage{
+		{Role: "user", Content: "Weather in Paris?"},
+		{Role: "assistant", ToolCalls: []models.ToolCall{
+			{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		{Role: "tool", ToolCallID: "call_1"},
+	}
+
+	c, err := m.Create(context.Background(), models.Conversation{Messages: seed})
+	if err != nil {
+		t.Fatalf("expected a seed with tool calls to be accepted, got %v", err)
+	}
+	if len(c.Messages) != 3 || len(c.Messages[1].ToolCalls) != 1 || c.Messages[2].ToolCallID != "call_1" {
+		t.Errorf("expected the tool calls to be kept, got %+v", c.Messages)
+	}
+
+	invalid := []models.ChatMessage{{Role: "user", ToolCalls: seed[1].ToolCalls}}
+	if _, err := m.Create(context.Background(), models.Conversation{Messages: invalid}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for a user message calling tools, got %v", err)
+	}
+}
+
+func TestManager_TurnsAreSerialized(t *testing.T) {
+	m := NewManager(NewMemoryStore())
+	ctx := context.Background()
+	c, _ := m.Create(ctx, models.Conversation{})
+	user := func(content string) *models.ChatCompletionRequest {
+		return &models.ChatCompletionRequest{ConversationID: c.ID, Messages: []models.ChatMessage{{Role: "user", Content: content}}}
+	}
+
+	first, err := m.Prepare(ctx, user("one"))
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	// A concurrent turn gives up when its caller does
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Prepare(timeout, user("too late")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the waiting turn to time out, got %v", err)
+	}
+
+	// Otherwise it waits and builds on the recorded turn
+	req := user("two")
+	done := make(chan error, 1)
+	go func() {
+		second, err := m.Prepare(ctx, req)
+		if err == nil {
+			second.Release()
+		}
+		done <- err
+	}()
+	first.Record(models.ChatMessage{Role: "assistant", Content: "1"})
+	if err := <-done; err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if len(req.Messages) != 3 || req.Messages[1].Content != "1" {
+		t.Errorf("expected the second turn to see the first, got %+v", req.Messages)
+	}
+
+	// Released turns free the conversation without recording
+	third, err := m.Prepare(ctx, user("three"))
+	if err != nil {
+		t.Fatalf("Prepare after release failed: %v", err)
+	}
+	third.Release()
+	third.Release()
+	if got, _ := m.Get(ctx, c.ID); len(got.Messages) != 2 {
+		t.Errorf("expected only the recorded turn, got %+v", got.Messages)
+	}
+}
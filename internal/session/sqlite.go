@@ -0,0 +1,153 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// SQLiteStore keeps conversations in an embedded SQLite database, one row
+// per conversation with its history encoded as JSON
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens or creates the database at path
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite sessions require a path")
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sessions database: %w", err)
+	}
+	// SQLite allows a single writer; serialising access avoids SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS conversations (
+		id         TEXT PRIMARY KEY,
+		owner      TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		data       TEXT NOT NULL
+	)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create conversations table: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// List returns the conversations of owner without their messages, most
+// recently updated first
+func (s *SQLiteStore) List(owner string) ([]models.Conversation, error) {
+	rows, err := s.db.Query(`SELECT data FROM conversations WHERE owner = ? ORDER BY updated_at DESC, id`, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversations []models.Conversation
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var c models.Conversation
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, err
+		}
+		c.Messages = nil
+		conversations = append(conversations, c)
+	}
+	return conversations, rows.Err()
+}
+
+// Get returns the conversation with the given ID
+func (s *SQLiteStore) Get(id string) (models.Conversation, error) {
+	return get(s.db, id)
+}
+
+// Put creates or replaces a conversation
+func (s *SQLiteStore) Put(c models.Conversation) error {
+	return put(s.db, c)
+}
+
+// Append adds messages to the end of a conversation's history
+func (s *SQLiteStore) Append(id string, at time.Time, messages ...models.ChatMessage) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	c, err := get(tx, id)
+	if err != nil {
+		return err
+	}
+	c.Messages = append(c.Messages, messages...)
+	c.UpdatedAt = at
+	if err := put(tx, c); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete removes the conversation with the given ID
+func (s *SQLiteStore) Delete(id string) error {
+	result, err := s.db.Exec(`DELETE FROM conversations WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// get loads a single conversation
+func get(db execer, id string) (models.Conversation, error) {
+	var data string
+	err := db.QueryRow(`SELECT data FROM conversations WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Conversation{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return models.Conversation{}, err
+	}
+
+	var c models.Conversation
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		return models.Conversation{}, err
+	}
+	return c, nil
+}
+
+// put writes a conversation, replacing any existing row
+func put(db execer, c models.Conversation) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO conversations (id, owner, updated_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET owner = excluded.owner, updated_at = excluded.updated_at, data = excluded.data`,
+		c.ID, c.Owner, c.UpdatedAt.UnixNano(), string(data))
+	return err
+}
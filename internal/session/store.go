@@ -0,0 +1,130 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Storage types accepted in the sessions config
+const (
+	StorageMemory = "memory"
+	StorageSQLite = "sqlite"
+)
+
+// Store persists conversations. Implementations must be safe for concurrent
+// use and return ErrNotFound for unknown IDs.
+type Store interface {
+	List(owner string) ([]models.Conversation, error) // without messages, newest first
+	Get(id string) (models.Conversation, error)
+	Put(c models.Conversation) error // creates or replaces
+	Append(id string, at time.Time, messages ...models.ChatMessage) error
+	Delete(id string) error
+	Close() error
+}
+
+// OpenStore opens the conversation store selected by the sessions config
+func OpenStore(cfg config.SessionsConfig) (Store, error) {
+	switch cfg.Type {
+	case "", StorageMemory:
+		return NewMemoryStore(), nil
+	case StorageSQLite:
+		store, err := OpenSQLiteStore(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown sessions type %q", cfg.Type)
+	}
+}
+
+// MemoryStore keeps conversations in memory; they are lost on restart
+type MemoryStore struct {
+	mu            sync.RWMutex
+	conversations map[string]models.Conversation
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{conversations: make(map[string]models.Conversation)}
+}
+
+// List returns the conversations of owner without their messages, most
+// recently updated first
+func (m *MemoryStore) List(owner string) ([]models.Conversation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var conversations []models.Conversation
+	for _, c := range m.conversations {
+		if c.Owner == owner {
+			c.Messages = nil
+			conversations = append(conversations, c)
+		}
+	}
+	sortByUpdated(conversations)
+	return conversations, nil
+}
+
+// Get returns the conversation with the given ID
+func (m *MemoryStore) Get(id string) (models.Conversation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.conversations[id]
+	if !ok {
+		return models.Conversation{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return clone(c), nil
+}
+
+// Put creates or replaces a conversation
+func (m *MemoryStore) Put(c models.Conversation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.conversations[c.ID] = clone(c)
+	return nil
+}
+
+// Append adds messages to the end of a conversation's history
+func (m *MemoryStore) Append(id string, at time.Time, messages ...models.ChatMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.conversations[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	c.Messages = append(append([]models.ChatMessage(nil), c.Messages...), messages...)
+	c.UpdatedAt = at
+	m.conversations[id] = c
+	return nil
+}
+
+// Delete removes the conversation with the given ID
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.conversations[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	delete(m.conversations, id)
+	return nil
+}
+
+// Close is a no-op for the memory store
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// clone returns a copy of c that shares no messages with it
+func clone(c models.Conversation) models.Conversation {
+	c.Messages = append([]models.ChatMessage(nil), c.Messages...)
+	return c
+}
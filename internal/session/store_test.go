@@ -0,0 +1,121 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	if _, err := store.Get("conv-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an empty store, got %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range []models.Conversation{
+		{ID: "conv-1", Owner: "alice", Model: "llama3", CreatedAt: start, UpdatedAt: start},
+		{ID: "conv-2", Owner: "alice", CreatedAt: start, UpdatedAt: start.Add(time.Minute)},
+		{ID: "conv-3", Owner: "bob", CreatedAt: start, UpdatedAt: start},
+	} {
+		if err := store.Put(c); err != nil {
+			t.Fatalf("Put %d failed: %v", i, err)
+		}
+	}
+
+	turn := []models.ChatMessage{{Role: "user", Content: "Hello"}, {Role: "assistant", Content: "Hi"}}
+	if err := store.Append("conv-1", start.Add(time.Hour), turn...); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := store.Append("conv-1", start.Add(2*time.Hour), models.ChatMessage{Role: "user", Content: "Bye"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := store.Append("missing", start); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound appending to a missing conversation, got %v", err)
+	}
+
+	c, err := store.Get("conv-1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(c.Messages) != 3 || c.Messages[2].Content != "Bye" || c.Model != "llama3" {
+		t.Errorf("unexpected conversation %+v", c)
+	}
+	if !c.UpdatedAt.Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("expected update time to be bumped, got %v", c.UpdatedAt)
+	}
+
+	list, err := store.List("alice")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "conv-1" || list[1].ID != "conv-2" {
+		t.Errorf("expected alice's conversations newest first, got %+v", list)
+	}
+	if list[0].Messages != nil {
+		t.Errorf("expected lists to omit messages, got %+v", list[0].Messages)
+	}
+
+	if err := store.Delete("conv-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := store.Delete("conv-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestSQLiteStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore failed: %v", err)
+	}
+	testStore(t, store)
+	store.Close()
+
+	// Conversations survive a restart
+	store, err = OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+	if _, err := store.Get("conv-2"); err != nil {
+		t.Errorf("expected conv-2 after reopening, got %v", err)
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.SessionsConfig
+		wantErr bool
+	}{
+		{name: "default", cfg: config.SessionsConfig{}},
+		{name: "memory", cfg: config.SessionsConfig{Type: "memory"}},
+		{name: "sqlite", cfg: config.SessionsConfig{Type: "sqlite", Path: filepath.Join(t.TempDir(), "sessions.db")}},
+		{name: "sqlite without path", cfg: config.SessionsConfig{Type: "sqlite"}, wantErr: true},
+		{name: "unknown", cfg: config.SessionsConfig{Type: "redis"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := OpenStore(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if store != nil {
+				store.Close()
+			}
+		})
+	}
+}
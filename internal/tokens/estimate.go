@@ -0,0 +1,54 @@
+// Package tokens estimates token counts where a backend does not report them
+package tokens
+
+import (
+	"math"
+	"unicode/utf8"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// MessageOverhead approximates the tokens a chat format adds around every
+// message for its role and separators
+const MessageOverhead = 4
+
+// Estimator approximates token counts from text length. It does not know
+// the backend's tokenizer, so limits should leave some headroom.
+type Estimator struct {
+	CharsPerToken float64
+}
+
+// Text returns the estimated tokens in s
+func (e Estimator) Text(s string) int {
+	if s == "" {
+		return 0
+	}
+	return int(math.Ceil(float64(utf8.RuneCountInString(s)) / e.CharsPerToken))
+}
+
+// Message returns the estimated tokens of a single chat message, including
+// the tool calls it makes
+func (e Estimator) Message(m models.ChatMessage) int {
+	tokens := MessageOverhead + e.Text(m.Content)
+	for _, call := range m.ToolCalls {
+		tokens += e.Text(call.ID) + e.Text(call.Function.Name) + e.Text(call.Function.Arguments)
+	}
+	return tokens
+}
+
+// Request returns the estimated prompt tokens of a request, including the
+// persona prompt that will be merged into it and the tool definitions
+func (e Estimator) Request(req *models.ChatCompletionRequest) int {
+	tokens := 0
+	if req.PersonaPrompt != "" {
+		tokens += MessageOverhead + e.Text(req.PersonaPrompt)
+	}
+	for _, m := range req.Messages {
+		tokens += e.Message(m)
+	}
+	for _, tool := range req.Tools {
+		f := tool.Function
+		tokens += e.Text(f.Name) + e.Text(f.Description) + e.Text(string(f.Parameters))
+	}
+	return tokens
+}
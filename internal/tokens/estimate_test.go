@@ -0,0 +1,49 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestEstimator(t *testing.T) {
+	e := Estimator{CharsPerToken: 4}
+
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{text: "", expected: 0},
+		{text: "abc", expected: 1},
+		{text: "abcd", expected: 1},
+		{text: "abcde", expected: 2},
+		{text: "héllo wörld", expected: 3}, // counted in runes, not bytes
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := e.Text(tt.text); got != tt.expected {
+				t.Errorf("expected %d tokens, got %d", tt.expected, got)
+			}
+		})
+	}
+
+	req := &models.ChatCompletionRequest{
+		PersonaPrompt: "abcdefgh",
+		Messages:      []models.ChatMessage{{Role: "user", Content: "abcd"}},
+	}
+	if got, expected := e.Request(req), (MessageOverhead+2)+(MessageOverhead+1); got != expected {
+		t.Errorf("expected %d request tokens, got %d", expected, got)
+	}
+
+	// Tool calls and tool definitions take up room in the prompt too
+	req = &models.ChatCompletionRequest{
+		Messages: []models.ChatMessage{{Role: "assistant", ToolCalls: []models.ToolCall{
+			{ID: "call", Function: models.FunctionCall{Name: "abcd", Arguments: `{"a":1}`}},
+		}}},
+		Tools: []models.Tool{{Type: "function", Function: models.ToolFunction{Name: "abcd", Parameters: []byte(`{}`)}}},
+	}
+	if got, expected := e.Request(req), (MessageOverhead+1+1+2)+(1+1); got != expected {
+		t.Errorf("expected %d request tokens with tools, got %d", expected, got)
+	}
+}
@@ -0,0 +1,61 @@
+package tokens
+
+import (
+	"math"
+	"unicode/utf8"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// streamCharsPerToken is the ratio StreamUsage estimates with
+const streamCharsPerToken = 4
+
+// StreamUsage tallies the token usage of a streamed completion. Usage the
+// backend reports is used as is; without it the prompt and the streamed
+// deltas are estimated, so streams are never accounted as free.
+type StreamUsage struct {
+	estimator Estimator
+	prompt    int
+	chars     int
+	chunks    int
+	reported  *models.Usage
+}
+
+// NewStreamUsage starts tallying the stream answering req
+func NewStreamUsage(req *models.ChatCompletionRequest) *StreamUsage {
+	e := Estimator{CharsPerToken: streamCharsPerToken}
+	return &StreamUsage{estimator: e, prompt: e.Request(req)}
+}
+
+// Add folds a chunk into the tally
+func (u *StreamUsage) Add(chunk *models.ChatCompletionChunk) {
+	u.chunks++
+	if chunk.Usage != nil {
+		reported := *chunk.Usage
+		u.reported = &reported
+	}
+	for _, choice := range chunk.Choices {
+		u.chars += utf8.RuneCountInString(choice.Delta.Content)
+		for _, call := range choice.Delta.ToolCalls {
+			u.chars += utf8.RuneCountInString(call.Function.Name) + utf8.RuneCountInString(call.Function.Arguments)
+		}
+	}
+}
+
+// Usage returns the reported or estimated usage. It reports false when no
+// chunk arrived, as the backend then most likely did no work.
+func (u *StreamUsage) Usage() (models.Usage, bool) {
+	if u.reported != nil {
+		return *u.reported, true
+	}
+	if u.chunks == 0 {
+		return models.Usage{}, false
+	}
+
+	completion := int(math.Ceil(float64(u.chars) / u.estimator.CharsPerToken))
+	return models.Usage{
+		PromptTokens:     u.prompt,
+		CompletionTokens: completion,
+		TotalTokens:      u.prompt + completion,
+	}, true
+}
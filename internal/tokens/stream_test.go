@@ -0,0 +1,31 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+func TestStreamUsage(t *testing.T) {
+	req := &models.ChatCompletionRequest{Messages: []models.ChatMessage{{Role: "user", Content: "Hi"}}}
+	delta := func(content string) *models.ChatCompletionChunk {
+		return &models.ChatCompletionChunk{Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Content: content}}}}
+	}
+
+	u := NewStreamUsage(req)
+	if _, ok := u.Usage(); ok {
+		t.Error("expected no usage before any chunk")
+	}
+
+	u.Add(delta("Hello, "))
+	u.Add(delta("world!"))
+	usage, ok := u.Usage()
+	if !ok || usage.PromptTokens != 5 || usage.CompletionTokens != 4 || usage.TotalTokens != 9 {
+		t.Errorf("expected an estimate of 5+4 tokens, got %+v", usage)
+	}
+
+	u.Add(&models.ChatCompletionChunk{Usage: &models.Usage{PromptTokens: 8, CompletionTokens: 3, TotalTokens: 11}})
+	if usage, _ := u.Usage(); usage.TotalTokens != 11 {
+		t.Errorf("expected the reported usage to win, got %+v", usage)
+	}
+}
@@ -0,0 +1,119 @@
+package usage
+
+import (
+	"context"
+	"log"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/tokens"
+)
+
+// Client wraps a provider, checks the caller's quota before forwarding and
+// records the usage of every completion and embedding in the ledger
+type Client struct {
+	client.Provider
+	ledger *Ledger
+	quotas *Quotas
+}
+
+// NewClient wraps provider so usage is recorded in ledger. quotas may be nil
+// to record usage without enforcing quotas.
+func NewClient(provider client.Provider, ledger *Ledger, quotas *Quotas) *Client {
+	return &Client{
+		Provider: provider,
+		ledger:   ledger,
+		quotas:   quotas,
+	}
+}
+
+// ChatCompletion checks the quota, forwards the request and records the
+// reported usage
+func (c *Client) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	if err := c.checkQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Provider.ChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.record(ctx, req.Model, resp.Model, resp.Usage)
+	return resp, nil
+}
+
+// ChatCompletionStream checks the quota, forwards the request and records
+// the usage reported on the final chunk, or an estimate when the backend
+// sends none
+func (c *Client) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	if err := c.checkQuota(ctx); err != nil {
+		return err
+	}
+
+	tally := tokens.NewStreamUsage(req)
+	model := ""
+	err := c.Provider.ChatCompletionStream(ctx, req, func(chunk *models.ChatCompletionChunk) error {
+		tally.Add(chunk)
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		return onChunk(chunk)
+	})
+
+	if usage, ok := tally.Usage(); ok {
+		c.record(ctx, req.Model, model, usage)
+	}
+	return err
+}
+
+// Embed checks the quota, forwards the request and records the reported
+// usage
+func (c *Client) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	if err := c.checkQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Provider.Embed(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.record(ctx, req.Model, resp.Model, resp.Usage)
+	return resp, nil
+}
+
+// checkQuota rejects the request when the caller in ctx is over quota
+func (c *Client) checkQuota(ctx context.Context) error {
+	if c.quotas == nil {
+		return nil
+	}
+	principal, _ := auth.FromContext(ctx)
+	return c.quotas.Check(principal)
+}
+
+// record adds a request to the ledger. The model the backend reports is
+// preferred over the requested one, which may be an alias or have failed
+// over. Ledger write failures are logged rather than failing a request that
+// has already been served.
+func (c *Client) record(ctx context.Context, requested, model string, u models.Usage) {
+	if model == "" {
+		model = requested
+	}
+
+	rec := Record{
+		Model:            model,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		rec.Key = principal.Key()
+		rec.Tenant = principal.Tenant
+	}
+
+	if err := c.ledger.Record(rec); err != nil {
+		log.Printf("Failed to record usage: %v", err)
+	}
+}
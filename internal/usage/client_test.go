@@ -0,0 +1,157 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// mockProvider returns fixed usage for every completion
+type mockProvider struct {
+	calls         int
+	noStreamUsage bool // stream without a usage chunk, like backends that ignore include_usage
+}
+
+func (m *mockProvider) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	m.calls++
+	return &models.ChatCompletionResponse{
+		ID:    "test-id",
+		Model: "gpt-4o-2024-08-06",
+		Usage: models.Usage{PromptTokens: 30, CompletionTokens: 20, TotalTokens: 50},
+	}, nil
+}
+
+func (m *mockProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	m.calls++
+	if err := onChunk(&models.ChatCompletionChunk{ID: "test-id", Choices: []models.ChunkChoice{{Delta: models.ChatDelta{Content: "Hello, world!"}}}}); err != nil {
+		return err
+	}
+	if m.noStreamUsage {
+		return nil
+	}
+	return onChunk(&models.ChatCompletionChunk{
+		ID:    "test-id",
+		Usage: &models.Usage{PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
+	})
+}
+
+func (m *mockProvider) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	m.calls++
+	return &models.EmbeddingResponse{
+		Model: "text-embedding-3-small",
+		Usage: models.Usage{PromptTokens: 8, TotalTokens: 8},
+	}, nil
+}
+
+func (m *mockProvider) ListModels(ctx context.Context) ([]models.Model, error) {
+	return nil, nil
+}
+
+func TestClient_RecordsUsage(t *testing.T) {
+	ledger, _ := Open("")
+	provider := &mockProvider{}
+	c := NewClient(provider, ledger, nil)
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "a", Tenant: "acme"})
+	req := &models.ChatCompletionRequest{Model: "gpt-4o"}
+
+	if _, err := c.ChatCompletion(ctx, req); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if err := c.ChatCompletionStream(ctx, req, func(*models.ChatCompletionChunk) error { return nil }); err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	now := time.Now()
+	rows, total, _ := ledger.Report(now, now, Filter{}, []string{GroupKey, GroupTenant, GroupModel})
+	if total.Requests != 2 || total.TotalTokens != 70 {
+		t.Errorf("expected 2 requests and 70 tokens, got %+v", total)
+	}
+	if len(rows) != 2 || rows[0].Model != "gpt-4o" || rows[1].Model != "gpt-4o-2024-08-06" {
+		t.Errorf("expected the reported model with the requested one as fallback, got %+v", rows)
+	}
+	if rows[0].Key != "a" || rows[0].Tenant != "acme" {
+		t.Errorf("expected usage accounted to the caller, got %+v", rows[0])
+	}
+}
+
+func TestClient_EstimatesStreamUsage(t *testing.T) {
+	ledger, _ := Open("")
+	c := NewClient(&mockProvider{noStreamUsage: true}, ledger, nil)
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "a", Tenant: "acme"})
+	req := &models.ChatCompletionRequest{Model: "gpt-4o", Messages: []models.ChatMessage{{Role: "user", Content: "Hi"}}}
+
+	if err := c.ChatCompletionStream(ctx, req, func(*models.ChatCompletionChunk) error { return nil }); err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	now := time.Now()
+	rows, total, _ := ledger.Report(now, now, Filter{}, []string{GroupKey})
+	if total.Requests != 1 || total.PromptTokens != 5 || total.CompletionTokens != 4 {
+		t.Errorf("expected an estimate of 5 prompt and 4 completion tokens, got %+v", total)
+	}
+	if len(rows) != 1 || rows[0].Key != "a" {
+		t.Errorf("expected usage accounted to the caller, got %+v", rows)
+	}
+}
+
+func TestClient_RecordsEmbeddingUsage(t *testing.T) {
+	ledger, _ := Open("")
+	c := NewClient(&mockProvider{}, ledger, NewQuotas(ledger, "key", Quota{DailyTokens: 8}, nil))
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "a", Tenant: "acme"})
+	req := &models.EmbeddingRequest{Model: "embed", Input: []string{"hello"}}
+
+	if _, err := c.Embed(ctx, req); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	now := time.Now()
+	rows, total, _ := ledger.Report(now, now, Filter{}, []string{GroupModel})
+	if total.Requests != 1 || total.TotalTokens != 8 {
+		t.Errorf("expected 1 request and 8 tokens, got %+v", total)
+	}
+	if len(rows) != 1 || rows[0].Model != "text-embedding-3-small" {
+		t.Errorf("expected the reported model, got %+v", rows)
+	}
+
+	if _, err := c.Embed(ctx, req); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+}
+
+func TestClient_EnforcesQuota(t *testing.T) {
+	ledger, _ := Open("")
+	provider := &mockProvider{}
+	c := NewClient(provider, ledger, NewQuotas(ledger, "key", Quota{DailyTokens: 50}, nil))
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "a"})
+	req := &models.ChatCompletionRequest{Model: "gpt-4o"}
+
+	if _, err := c.ChatCompletion(ctx, req); err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+
+	_, err := c.ChatCompletion(ctx, req)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	err = c.ChatCompletionStream(ctx, req, func(*models.ChatCompletionChunk) error { return nil })
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded for stream, got %v", err)
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("expected over-quota requests not to be forwarded, got %d calls", provider.calls)
+	}
+}
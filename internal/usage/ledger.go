@@ -0,0 +1,326 @@
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// dayFormat and monthFormat are the layouts of ledger periods, always in UTC
+const (
+	dayFormat   = "2006-01-02"
+	monthFormat = "2006-01"
+)
+
+// Record is one completed request in the ledger
+type Record struct {
+	Time             time.Time `json:"time"`
+	Key              string    `json:"key,omitempty"` // principal ID, empty for anonymous callers
+	Tenant           string    `json:"tenant,omitempty"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+}
+
+// bucketKey identifies one aggregate in the ledger
+type bucketKey struct {
+	Day    string
+	Key    string
+	Tenant string
+	Model  string
+}
+
+// usedKey identifies a running total of one key or one tenant over a day
+// or a month
+type usedKey struct {
+	Period string
+	Key    string
+	Tenant string
+}
+
+// Totals are summed token counts
+type Totals struct {
+	Requests         int `json:"requests"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (t *Totals) add(other Totals) {
+	t.Requests += other.Requests
+	t.PromptTokens += other.PromptTokens
+	t.CompletionTokens += other.CompletionTokens
+	t.TotalTokens += other.TotalTokens
+}
+
+// Ledger accumulates token usage per day, key, tenant and model. Records are
+// appended to a JSON lines file, which is replayed on startup; an empty path
+// keeps the ledger in memory only. Daily and monthly token totals per key
+// and per tenant are kept alongside so quota checks don't scan the buckets.
+type Ledger struct {
+	mu      sync.Mutex
+	file    *os.File
+	buckets map[bucketKey]*Totals
+	used    map[usedKey]int
+	now     func() time.Time
+}
+
+// Open opens or creates the ledger file at path and loads its totals
+func Open(path string) (*Ledger, error) {
+	l := &Ledger{
+		buckets: make(map[bucketKey]*Totals),
+		used:    make(map[usedKey]int),
+		now:     time.Now,
+	}
+	if path == "" {
+		return l, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+
+	end, err := l.replay(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to load usage ledger %s: %w", path, err)
+	}
+
+	// Drop a partial record left by a crash mid-write so new records start
+	// on a line of their own
+	if err := file.Truncate(end); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to repair usage ledger: %w", err)
+	}
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to repair usage ledger: %w", err)
+	}
+
+	l.file = file
+	return l, nil
+}
+
+// replay adds every complete record in r to the totals and returns the
+// offset just past the last complete line
+func (l *Ledger) replay(r io.Reader) (int64, error) {
+	reader := bufio.NewReader(r)
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		offset += int64(len(data))
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		l.add(rec)
+	}
+}
+
+// Record appends rec to the ledger. A zero rec.Time is set to now.
+func (l *Ledger) Record(rec Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rec.Time.IsZero() {
+		rec.Time = l.now()
+	}
+	rec.Time = rec.Time.UTC()
+
+	if l.file != nil {
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		if _, err := l.file.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write usage record: %w", err)
+		}
+	}
+
+	l.add(rec)
+	return nil
+}
+
+// add folds rec into the totals. Callers must hold l.mu or own l exclusively.
+func (l *Ledger) add(rec Record) {
+	key := bucketKey{
+		Day:    rec.Time.UTC().Format(dayFormat),
+		Key:    rec.Key,
+		Tenant: rec.Tenant,
+		Model:  rec.Model,
+	}
+
+	totals, ok := l.buckets[key]
+	if !ok {
+		totals = &Totals{}
+		l.buckets[key] = totals
+	}
+	totals.add(Totals{
+		Requests:         1,
+		PromptTokens:     rec.PromptTokens,
+		CompletionTokens: rec.CompletionTokens,
+		TotalTokens:      rec.TotalTokens,
+	})
+
+	for _, period := range []string{key.Day, rec.Time.UTC().Format(monthFormat)} {
+		if rec.Key != "" {
+			l.used[usedKey{Period: period, Key: rec.Key}] += rec.TotalTokens
+		}
+		if rec.Tenant != "" {
+			l.used[usedKey{Period: period, Tenant: rec.Tenant}] += rec.TotalTokens
+		}
+	}
+}
+
+// DailyUsed returns the tokens recorded on the UTC day of t for the key or
+// the tenant filter selects
+func (l *Ledger) DailyUsed(filter Filter, t time.Time) int {
+	return l.usedIn(filter, t.UTC().Format(dayFormat))
+}
+
+// MonthlyUsed returns the tokens recorded in the UTC month of t for the key
+// or the tenant filter selects
+func (l *Ledger) MonthlyUsed(filter Filter, t time.Time) int {
+	return l.usedIn(filter, t.UTC().Format(monthFormat))
+}
+
+// usedIn returns the running total of filter over period
+func (l *Ledger) usedIn(filter Filter, period string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.used[usedKey{Period: period, Key: filter.Key, Tenant: filter.Tenant}]
+}
+
+// Close closes the ledger file
+func (l *Ledger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Filter selects ledger entries; empty fields match everything
+type Filter struct {
+	Key    string
+	Tenant string
+	Model  string
+}
+
+func (f Filter) matches(key bucketKey) bool {
+	return (f.Key == "" || f.Key == key.Key) &&
+		(f.Tenant == "" || f.Tenant == key.Tenant) &&
+		(f.Model == "" || f.Model == key.Model)
+}
+
+// Group dimensions accepted by Report
+const (
+	GroupDay    = "day"
+	GroupKey    = "key"
+	GroupTenant = "tenant"
+	GroupModel  = "model"
+)
+
+// ReportRow is one group of a usage report. Dimensions not grouped by are
+// left empty.
+type ReportRow struct {
+	Day    string `json:"day,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+	Model  string `json:"model,omitempty"`
+	Totals
+}
+
+// Report sums the usage between the days from and to, inclusive, matching
+// filter and grouped by the given dimensions. Rows are sorted by their
+// dimensions.
+func (l *Ledger) Report(from, to time.Time, filter Filter, groupBy []string) ([]ReportRow, Totals, error) {
+	group := make(map[string]bool, len(groupBy))
+	for _, g := range groupBy {
+		switch g {
+		case GroupDay, GroupKey, GroupTenant, GroupModel:
+			group[g] = true
+		default:
+			return nil, Totals{}, fmt.Errorf("unknown group %q", g)
+		}
+	}
+
+	first := from.UTC().Format(dayFormat)
+	last := to.UTC().Format(dayFormat)
+
+	l.mu.Lock()
+	rows := make(map[bucketKey]*ReportRow)
+	var total Totals
+	for key, totals := range l.buckets {
+		if key.Day < first || key.Day > last || !filter.matches(key) {
+			continue
+		}
+
+		var rowKey bucketKey
+		if group[GroupDay] {
+			rowKey.Day = key.Day
+		}
+		if group[GroupKey] {
+			rowKey.Key = key.Key
+		}
+		if group[GroupTenant] {
+			rowKey.Tenant = key.Tenant
+		}
+		if group[GroupModel] {
+			rowKey.Model = key.Model
+		}
+
+		row, ok := rows[rowKey]
+		if !ok {
+			row = &ReportRow{Day: rowKey.Day, Key: rowKey.Key, Tenant: rowKey.Tenant, Model: rowKey.Model}
+			rows[rowKey] = row
+		}
+		row.add(*totals)
+		total.add(*totals)
+	}
+	l.mu.Unlock()
+
+	report := make([]ReportRow, 0, len(rows))
+	for _, row := range rows {
+		report = append(report, *row)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Model < b.Model
+	})
+
+	return report, total, nil
+}
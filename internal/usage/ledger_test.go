@@ -0,0 +1,141 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestLedger_ReplaysFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+
+	ledger, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	records := []Record{
+		{Time: day("2026-10-01"), Key: "a", Tenant: "acme", Model: "gpt-4o", PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		{Time: day("2026-10-02"), Key: "a", Tenant: "acme", Model: "llama3", TotalTokens: 20},
+	}
+	for _, rec := range records {
+		if err := ledger.Record(rec); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	ledger.Close()
+
+	// Simulate a crash in the middle of a write
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"time":"2026-10-03T00:00:00Z","key":"a"`)
+	f.Close()
+
+	ledger, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer ledger.Close()
+
+	if used := ledger.MonthlyUsed(Filter{Key: "a"}, day("2026-10-01")); used != 35 {
+		t.Errorf("expected 35 tokens after replay, got %d", used)
+	}
+
+	if err := ledger.Record(Record{Time: day("2026-10-03"), Key: "a", Model: "gpt-4o", TotalTokens: 5}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	ledger.Close()
+
+	ledger, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen after repair failed: %v", err)
+	}
+	defer ledger.Close()
+
+	if used := ledger.MonthlyUsed(Filter{Key: "a"}, day("2026-10-01")); used != 40 {
+		t.Errorf("expected 40 tokens after repair, got %d", used)
+	}
+}
+
+func TestLedger_CorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	os.WriteFile(path, []byte("not json\n"), 0600)
+
+	if _, err := Open(path); err == nil {
+		t.Error("expected error for corrupt ledger")
+	}
+}
+
+func TestLedger_Report(t *testing.T) {
+	ledger, _ := Open("")
+	for _, rec := range []Record{
+		{Time: day("2026-09-30"), Key: "a", Tenant: "acme", Model: "gpt-4o", TotalTokens: 1000},
+		{Time: day("2026-10-01"), Key: "a", Tenant: "acme", Model: "gpt-4o", PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		{Time: day("2026-10-01"), Key: "b", Tenant: "acme", Model: "gpt-4o", PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30},
+		{Time: day("2026-10-02"), Key: "c", Tenant: "globex", Model: "llama3", PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2},
+	} {
+		ledger.Record(rec)
+	}
+
+	rows, total, err := ledger.Report(day("2026-10-01"), day("2026-10-31"), Filter{}, []string{GroupTenant, GroupModel})
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d: %+v", len(rows), rows)
+	}
+	if rows[0].Tenant != "acme" || rows[0].Model != "gpt-4o" || rows[0].Key != "" {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+	if rows[0].Requests != 2 || rows[0].PromptTokens != 30 || rows[0].CompletionTokens != 15 || rows[0].TotalTokens != 45 {
+		t.Errorf("unexpected first row totals %+v", rows[0].Totals)
+	}
+	if total.Requests != 3 || total.TotalTokens != 47 {
+		t.Errorf("expected 3 requests and 47 tokens in total, got %+v", total)
+	}
+
+	rows, _, _ = ledger.Report(day("2026-09-01"), day("2026-10-31"), Filter{Tenant: "acme"}, []string{GroupDay})
+	if len(rows) != 2 || rows[0].Day != "2026-09-30" || rows[1].TotalTokens != 45 {
+		t.Errorf("unexpected daily rows %+v", rows)
+	}
+
+	if _, _, err := ledger.Report(day("2026-10-01"), day("2026-10-31"), Filter{}, []string{"project"}); err == nil {
+		t.Error("expected error for unknown group")
+	}
+}
+
+func TestLedger_RunningTotals(t *testing.T) {
+	ledger, _ := Open("")
+	ledger.Record(Record{Time: day("2026-09-30"), Key: "a", Tenant: "acme", TotalTokens: 1000})
+	ledger.Record(Record{Time: day("2026-10-01"), Key: "a", Tenant: "acme", TotalTokens: 10})
+	ledger.Record(Record{Time: day("2026-10-16").Add(23 * time.Hour), Key: "a", Tenant: "acme", TotalTokens: 20})
+	ledger.Record(Record{Time: day("2026-10-16"), Key: "b", Tenant: "acme", TotalTokens: 40})
+	ledger.Record(Record{Time: day("2026-10-16"), TotalTokens: 80})
+
+	now := day("2026-10-16").Add(12 * time.Hour)
+	tests := []struct {
+		name    string
+		used    func(Filter, time.Time) int
+		filter  Filter
+		expects int
+	}{
+		{"key daily", ledger.DailyUsed, Filter{Key: "a"}, 20},
+		{"key monthly", ledger.MonthlyUsed, Filter{Key: "a"}, 30},
+		{"tenant daily", ledger.DailyUsed, Filter{Tenant: "acme"}, 60},
+		{"tenant monthly", ledger.MonthlyUsed, Filter{Tenant: "acme"}, 70},
+		{"unknown key", ledger.MonthlyUsed, Filter{Key: "c"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if used := tt.used(tt.filter, now); used != tt.expects {
+				t.Errorf("expected %d tokens, got %d", tt.expects, used)
+			}
+		})
+	}
+}
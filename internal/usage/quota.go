@@ -0,0 +1,88 @@
+package usage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+// ErrQuotaExceeded is returned when a caller has used up its token quota
+var ErrQuotaExceeded = errors.New("token quota exceeded")
+
+// Quota is a caller's token allowance. Zero means unlimited.
+type Quota struct {
+	DailyTokens   int
+	MonthlyTokens int
+}
+
+// Quotas enforces daily and monthly token quotas against a ledger. Days and
+// months are calendar periods in UTC.
+type Quotas struct {
+	ledger    *Ledger
+	by        string
+	defaults  Quota
+	overrides map[string]Quota
+	now       func() time.Time
+}
+
+// NewQuotas creates quotas counted per "key" or per "tenant" according to by.
+// Overrides are keyed by "key:<id>" or "tenant:<name>".
+func NewQuotas(ledger *Ledger, by string, defaults Quota, overrides map[string]Quota) *Quotas {
+	return &Quotas{
+		ledger:    ledger,
+		by:        by,
+		defaults:  defaults,
+		overrides: overrides,
+		now:       time.Now,
+	}
+}
+
+// NewQuotasFromConfig creates quotas from the usage config
+func NewQuotasFromConfig(ledger *Ledger, cfg config.UsageConfig) *Quotas {
+	overrides := make(map[string]Quota, len(cfg.Overrides))
+	for key, o := range cfg.Overrides {
+		overrides[key] = Quota{DailyTokens: o.DailyTokens, MonthlyTokens: o.MonthlyTokens}
+	}
+
+	return NewQuotas(ledger, cfg.QuotaBy, Quota{
+		DailyTokens:   cfg.DailyTokens,
+		MonthlyTokens: cfg.MonthlyTokens,
+	}, overrides)
+}
+
+// Check returns an error wrapping ErrQuotaExceeded when the principal has
+// used up its daily or monthly quota. Requests are checked before they are
+// forwarded, so the request that crosses the quota is still served.
+// Anonymous callers are not subject to quotas.
+func (q *Quotas) Check(p *auth.Principal) error {
+	if p == nil {
+		return nil
+	}
+
+	scope, filter := "key:"+p.Key(), Filter{Key: p.Key()}
+	if q.by == "tenant" && p.Tenant != "" {
+		scope, filter = "tenant:"+p.Tenant, Filter{Tenant: p.Tenant}
+	}
+
+	quota, ok := q.overrides[scope]
+	if !ok {
+		quota = q.defaults
+	}
+
+	now := q.now()
+	if quota.DailyTokens > 0 {
+		if used := q.ledger.DailyUsed(filter, now); used >= quota.DailyTokens {
+			return fmt.Errorf("%w: %s used %d of %d daily tokens", ErrQuotaExceeded, scope, used, quota.DailyTokens)
+		}
+	}
+	if quota.MonthlyTokens > 0 {
+		if used := q.ledger.MonthlyUsed(filter, now); used >= quota.MonthlyTokens {
+			return fmt.Errorf("%w: %s used %d of %d monthly tokens", ErrQuotaExceeded, scope, used, quota.MonthlyTokens)
+		}
+	}
+
+	return nil
+}
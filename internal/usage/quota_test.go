@@ -0,0 +1,67 @@
+package usage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/auth"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+)
+
+func TestQuotas_Check(t *testing.T) {
+	ledger, _ := Open("")
+	ledger.Record(Record{Time: day("2026-10-01"), Key: "a", Tenant: "acme", TotalTokens: 500})
+	ledger.Record(Record{Time: day("2026-10-16"), Key: "a", Tenant: "acme", TotalTokens: 100})
+	ledger.Record(Record{Time: day("2026-10-16"), Key: "b", Tenant: "acme", TotalTokens: 100})
+
+	tests := []struct {
+		name      string
+		by        string
+		defaults  Quota
+		overrides map[string]Quota
+		principal *auth.Principal
+		exceeded  bool
+	}{
+		{name: "anonymous", defaults: Quota{DailyTokens: 1}, principal: nil},
+		{name: "unlimited", principal: &auth.Principal{ID: "a"}},
+		{name: "under daily", by: "key", defaults: Quota{DailyTokens: 150}, principal: &auth.Principal{ID: "a", Tenant: "acme"}},
+		{name: "tenant over daily", by: "tenant", defaults: Quota{DailyTokens: 150}, principal: &auth.Principal{ID: "a", Tenant: "acme"}, exceeded: true},
+		{name: "over monthly", by: "key", defaults: Quota{MonthlyTokens: 600}, principal: &auth.Principal{ID: "a"}, exceeded: true},
+		{name: "token subject", by: "key", defaults: Quota{MonthlyTokens: 600}, principal: &auth.Principal{ID: "a", Method: "jwt"}},
+		{name: "override", by: "key", defaults: Quota{MonthlyTokens: 600}, overrides: map[string]Quota{"key:a": {MonthlyTokens: 1000}}, principal: &auth.Principal{ID: "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQuotas(ledger, tt.by, tt.defaults, tt.overrides)
+			q.now = func() time.Time { return day("2026-10-16").Add(12 * time.Hour) }
+
+			err := q.Check(tt.principal)
+			if tt.exceeded && !errors.Is(err, ErrQuotaExceeded) {
+				t.Errorf("expected ErrQuotaExceeded, got %v", err)
+			}
+			if !tt.exceeded && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewQuotasFromConfig(t *testing.T) {
+	ledger, _ := Open("")
+	q := NewQuotasFromConfig(ledger, config.UsageConfig{
+		QuotaBy:     "tenant",
+		DailyTokens: 100,
+		Overrides: map[string]config.QuotaConfig{
+			"tenant:acme": {DailyTokens: 1000, MonthlyTokens: 5000},
+		},
+	})
+
+	if q.by != "tenant" || q.defaults.DailyTokens != 100 {
+		t.Errorf("unexpected quotas %+v", q)
+	}
+	if o := q.overrides["tenant:acme"]; o.DailyTokens != 1000 || o.MonthlyTokens != 5000 {
+		t.Errorf("unexpected override %+v", o)
+	}
+}
@@ -0,0 +1,61 @@
+package window
+
+import (
+	"context"
+	"log"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// Client wraps a provider and fits every request into its model's context
+// window before forwarding it. With the summarize strategy it also writes
+// the summaries through the same provider.
+type Client struct {
+	client.Provider
+	window    *Window
+	summaries *summaryCache
+}
+
+// NewClient wraps provider so requests are fitted to w
+func NewClient(provider client.Provider, w *Window) *Client {
+	return &Client{
+		Provider:  provider,
+		window:    w,
+		summaries: newSummaryCache(w.summaryCacheSize),
+	}
+}
+
+// ChatCompletion fits the request and forwards it
+func (c *Client) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	fitted, err := c.fit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.Provider.ChatCompletion(ctx, fitted)
+}
+
+// ChatCompletionStream fits the request and forwards it
+func (c *Client) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	fitted, err := c.fit(ctx, req)
+	if err != nil {
+		return err
+	}
+	return c.Provider.ChatCompletionStream(ctx, fitted, onChunk)
+}
+
+// fit applies the window and logs dropped history
+func (c *Client) fit(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionRequest, error) {
+	if c.window.strategy == StrategySummarize {
+		return c.summarize(ctx, req)
+	}
+
+	fitted, dropped, err := c.window.Fit(req)
+	if err != nil {
+		return nil, err
+	}
+	if dropped > 0 {
+		log.Printf("Context window: dropped %d of %d messages for model %s", dropped, len(req.Messages), req.Model)
+	}
+	return fitted, nil
+}
@@ -0,0 +1,73 @@
+package window
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// mockProvider records the requests it receives
+type mockProvider struct {
+	last *models.ChatCompletionRequest
+}
+
+func (m *mockProvider) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	m.last = req
+	return &models.ChatCompletionResponse{ID: "test-id"}, nil
+}
+
+func (m *mockProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, onChunk func(*models.ChatCompletionChunk) error) error {
+	m.last = req
+	return onChunk(&models.ChatCompletionChunk{ID: "test-id"})
+}
+
+func (m *mockProvider) Embed(ctx context.Context, req *models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	return &models.EmbeddingResponse{Model: req.Model}, nil
+}
+
+func (m *mockProvider) ListModels(ctx context.Context) ([]models.Model, error) {
+	return nil, nil
+}
+
+func TestClient(t *testing.T) {
+	provider := &mockProvider{}
+	c := NewClient(provider, NewFromConfig(testConfig(StrategyDropOldest)))
+	ctx := context.Background()
+
+	req := &models.ChatCompletionRequest{
+		Model: "other",
+		Messages: []models.ChatMessage{
+			msg("user", 40, "u1"), msg("assistant", 16, "a1"), msg("user", 16, "u2"),
+		},
+	}
+
+	if _, err := c.ChatCompletion(ctx, req); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if contents(provider.last.Messages) != "u2" {
+		t.Errorf("expected old turns to be dropped, got %s", contents(provider.last.Messages))
+	}
+
+	provider.last = nil
+	if err := c.ChatCompletionStream(ctx, req, func(*models.ChatCompletionChunk) error { return nil }); err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+	if contents(provider.last.Messages) != "u2" {
+		t.Errorf("expected old turns to be dropped, got %s", contents(provider.last.Messages))
+	}
+
+	failing := NewClient(provider, NewFromConfig(testConfig(StrategyFail)))
+	provider.last = nil
+	if _, err := failing.ChatCompletion(ctx, req); !errors.Is(err, ErrContextOverflow) {
+		t.Errorf("expected ErrContextOverflow, got %v", err)
+	}
+	if provider.last != nil {
+		t.Error("expected an overflowing request not to be forwarded")
+	}
+}
@@ -0,0 +1,191 @@
+package window
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/tokens"
+)
+
+// summaryInstructions is the system prompt of summarization requests
+const summaryInstructions = "You condense chat transcripts. Summarize the conversation below so it can " +
+	"stand in for the original messages: keep names, facts, figures, decisions, commitments and open " +
+	"questions, and drop pleasantries. Write in the third person and reply with the summary only."
+
+// summaryPrefix starts the system message that replaces summarized turns
+const summaryPrefix = "Summary of the conversation so far:\n"
+
+// summary is a cached summary of the first covered droppable messages of a
+// conversation. digest identifies those messages, so an edited history is
+// never answered with a stale summary.
+type summary struct {
+	covered int
+	digest  string
+	text    string
+}
+
+// summarize fits req like drop-oldest, leaving room for a summary, and
+// replaces the dropped messages with a summary of them. Summaries are cached
+// per conversation and extended as more turns fall out of the window. If the
+// summary cannot be written the dropped messages are left out instead.
+func (c *Client) summarize(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionRequest, error) {
+	w := c.window
+	room := w.summaryTokens + tokens.MessageOverhead + w.estimator.Text(summaryPrefix)
+	keep, err := w.plan(req, room)
+	if err != nil || keep == nil {
+		return req, err
+	}
+
+	var dropped []models.ChatMessage
+	for i, m := range req.Messages {
+		if !keep[i] {
+			dropped = append(dropped, m)
+		}
+	}
+
+	key := req.ConversationID
+	if key == "" {
+		// Stateless callers resend their history; its opening message
+		// identifies the conversation well enough given the digest check
+		key = "digest:" + digest(dropped[:1])
+	}
+
+	previous, ok := c.summaries.get(key)
+	if !ok || previous.covered > len(dropped) || previous.digest != digest(dropped[:previous.covered]) {
+		previous = summary{}
+	}
+
+	text := previous.text
+	if previous.covered < len(dropped) {
+		text, err = c.writeSummary(ctx, req.Model, previous.text, dropped[previous.covered:])
+		if err != nil {
+			log.Printf("Context window: summary failed for model %s, dropping %d messages: %v", req.Model, len(dropped), err)
+			fitted, _, _ := w.apply(req, keep, nil)
+			return fitted, nil
+		}
+		c.summaries.put(key, summary{covered: len(dropped), digest: digest(dropped), text: text})
+	}
+
+	message := models.ChatMessage{Role: "system", Content: summaryPrefix + text, Summary: true}
+	fitted, _, _ := w.apply(req, keep, &message)
+	log.Printf("Context window: summarized %d of %d messages for model %s", len(dropped), len(req.Messages), req.Model)
+	return fitted, nil
+}
+
+// writeSummary asks the summary model, through the wrapped provider, to fold
+// messages into the previous summary
+func (c *Client) writeSummary(ctx context.Context, model, previous string, messages []models.ChatMessage) (string, error) {
+	if c.window.summaryModel != "" {
+		model = c.window.summaryModel
+	}
+
+	var transcript strings.Builder
+	if previous != "" {
+		fmt.Fprintf(&transcript, "Summary of earlier messages:\n%s\n\nLater messages:\n", previous)
+	}
+	for _, m := range messages {
+		if m.Content != "" || len(m.ToolCalls) == 0 {
+			fmt.Fprintf(&transcript, "%s: %s\n", m.Role, m.Content)
+		}
+		for _, call := range m.ToolCalls {
+			fmt.Fprintf(&transcript, "%s called %s(%s)\n", m.Role, call.Function.Name, call.Function.Arguments)
+		}
+	}
+
+	maxTokens := c.window.summaryTokens
+	temperature := 0.0
+	resp, err := c.Provider.ChatCompletion(ctx, &models.ChatCompletionRequest{
+		Model: model,
+		Messages: []models.ChatMessage{
+			{Role: "system", Content: summaryInstructions},
+			{Role: "user", Content: transcript.String()},
+		},
+		MaxTokens:   &maxTokens,
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("summary model returned no choices")
+	}
+	text := strings.TrimSpace(resp.Choices[0].Message.Content)
+	if text == "" {
+		return "", errors.New("summary model returned an empty summary")
+	}
+	return text, nil
+}
+
+// digest identifies a run of messages
+func digest(messages []models.ChatMessage) string {
+	h := sha256.New()
+	for _, m := range messages {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(m.Role), m.Role, len(m.Content), m.Content)
+		for _, call := range m.ToolCalls {
+			fmt.Fprintf(h, "%d:%s%d:%s", len(call.Function.Name), call.Function.Name, len(call.Function.Arguments), call.Function.Arguments)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// summaryCache keeps the latest summary of the most recently used
+// conversations
+type summaryCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	summary summary
+}
+
+func newSummaryCache(size int) *summaryCache {
+	return &summaryCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *summaryCache) get(key string) (summary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return summary{}, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).summary, true
+}
+
+func (c *summaryCache) put(key string, s summary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*cacheEntry).summary = s
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, summary: s})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,240 @@
+package window
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/client"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// summaryProvider answers summarization requests with numbered summaries and
+// records everything else
+type summaryProvider struct {
+	mockProvider
+	summaries []*models.ChatCompletionRequest
+	err       error
+}
+
+func (p *summaryProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	if len(req.Messages) == 0 || req.Messages[0].Content != summaryInstructions {
+		return p.mockProvider.ChatCompletion(ctx, req)
+	}
+	p.summaries = append(p.summaries, req)
+	if p.err != nil {
+		return nil, p.err
+	}
+	text := fmt.Sprintf("S%d", len(p.summaries))
+	return &models.ChatCompletionResponse{Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: text}}}}, nil
+}
+
+func summarizeClient(provider *summaryProvider) *Client {
+	cfg := testConfig(StrategySummarize)
+	cfg.SummaryModel = "cheap"
+	cfg.SummaryTokens = 4
+	cfg.SummaryCacheSize = 10
+	return NewClient(provider, NewFromConfig(cfg))
+}
+
+// summarizeRequest builds a request whose first two messages fall out of the
+// window with a 46 token budget (100 - 10 reserved - 44 for the summary)
+func summarizeRequest(id string, extra ...models.ChatMessage) *models.ChatCompletionRequest {
+	maxTokens := 10
+	messages := []models.ChatMessage{
+		msg("user", 30, "u1"), msg("assistant", 30, "a1"),
+		msg("user", 10, "u2"), msg("assistant", 10, "a2"), msg("user", 10, "u3"),
+	}
+	return &models.ChatCompletionRequest{
+		Model:          "other",
+		Messages:       append(messages, extra...),
+		MaxTokens:      &maxTokens,
+		ConversationID: id,
+	}
+}
+
+func TestClient_Summarize(t *testing.T) {
+	provider := &summaryProvider{}
+	c := summarizeClient(provider)
+	ctx := context.Background()
+
+	if _, err := c.ChatCompletion(ctx, summarizeRequest("conv-1")); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if len(provider.summaries) != 1 {
+		t.Fatalf("expected 1 summary request, got %d", len(provider.summaries))
+	}
+	first := provider.summaries[0]
+	if first.Model != "cheap" {
+		t.Errorf("expected the summary model to be used, got %q", first.Model)
+	}
+	if transcript := first.Messages[1].Content; !strings.Contains(transcript, "user: u1") || !strings.Contains(transcript, "assistant: a1") || strings.Contains(transcript, "u2") {
+		t.Errorf("expected only the dropped turns to be summarized, got %q", transcript)
+	}
+
+	forwarded := provider.last.Messages
+	if forwarded[0].Role != "system" || forwarded[0].Content != summaryPrefix+"S1" {
+		t.Errorf("expected the summary in place of the dropped turns, got %+v", forwarded[0])
+	}
+	if got := contents(forwarded[1:]); got != "u2,a2,u3" {
+		t.Errorf("expected u2,a2,u3 after the summary, got %s", got)
+	}
+
+	// The next turn pushes two more messages out; the cached summary is
+	// extended with just those
+	next := summarizeRequest("conv-1", msg("assistant", 10, "a3"), msg("user", 10, "u4"))
+	if _, err := c.ChatCompletion(ctx, next); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if len(provider.summaries) != 2 {
+		t.Fatalf("expected 2 summary requests, got %d", len(provider.summaries))
+	}
+	if transcript := provider.summaries[1].Messages[1].Content; !strings.Contains(transcript, "S1") || strings.Contains(transcript, "u1") || !strings.Contains(transcript, "u2") {
+		t.Errorf("expected the previous summary to be extended, got %q", transcript)
+	}
+	if got := provider.last.Messages[0].Content; got != summaryPrefix+"S2" {
+		t.Errorf("expected the extended summary, got %q", got)
+	}
+
+	// Repeating the request reuses the cached summary
+	if err := c.ChatCompletionStream(ctx, next, func(*models.ChatCompletionChunk) error { return nil }); err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+	if len(provider.summaries) != 2 {
+		t.Errorf("expected the cached summary to be reused, got %d summary requests", len(provider.summaries))
+	}
+	if got := provider.last.Messages[0].Content; got != summaryPrefix+"S2" {
+		t.Errorf("expected the cached summary, got %q", got)
+	}
+}
+
+func TestClient_SummarizeStateless(t *testing.T) {
+	provider := &summaryProvider{}
+	c := summarizeClient(provider)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.ChatCompletion(ctx, summarizeRequest("")); err != nil {
+			t.Fatalf("ChatCompletion failed: %v", err)
+		}
+	}
+	if len(provider.summaries) != 1 {
+		t.Errorf("expected a resent history to hit the cache, got %d summary requests", len(provider.summaries))
+	}
+
+	// An edited history is summarized afresh
+	edited := summarizeRequest("")
+	edited.Messages[1] = msg("assistant", 30, "edited")
+	if _, err := c.ChatCompletion(ctx, edited); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if len(provider.summaries) != 2 {
+		t.Errorf("expected an edited history to be summarized again, got %d summary requests", len(provider.summaries))
+	}
+}
+
+func TestClient_SummarizeFailure(t *testing.T) {
+	provider := &summaryProvider{err: errors.New("upstream down")}
+	c := summarizeClient(provider)
+
+	if _, err := c.ChatCompletion(context.Background(), summarizeRequest("conv-1")); err != nil {
+		t.Fatalf("expected the request to succeed without a summary, got %v", err)
+	}
+	if got := contents(provider.last.Messages); got != "u2,a2,u3" {
+		t.Errorf("expected the dropped turns to be left out, got %s", got)
+	}
+
+	// Nothing is cached for a failed summary
+	provider.err = nil
+	if _, err := c.ChatCompletion(context.Background(), summarizeRequest("conv-1")); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if got := provider.last.Messages[0].Content; got != summaryPrefix+"S2" {
+		t.Errorf("expected a fresh summary, got %q", got)
+	}
+}
+
+func TestClient_SummarizeFits(t *testing.T) {
+	provider := &summaryProvider{}
+	c := summarizeClient(provider)
+
+	req := &models.ChatCompletionRequest{Model: "other", Messages: []models.ChatMessage{msg("user", 10, "u1")}}
+	if _, err := c.ChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if len(provider.summaries) != 0 || provider.last != req {
+		t.Error("expected a fitting request to be forwarded as it is")
+	}
+}
+
+func TestSummaryCache(t *testing.T) {
+	cache := newSummaryCache(2)
+	cache.put("a", summary{text: "A"})
+	cache.put("b", summary{text: "B"})
+	cache.get("a")
+	cache.put("c", summary{text: "C"})
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.get(key); !ok {
+			t.Errorf("expected %q to be cached", key)
+		}
+	}
+
+	disabled := newSummaryCache(0)
+	disabled.put("a", summary{text: "A"})
+	if _, ok := disabled.get("a"); ok {
+		t.Error("expected a zero-size cache to keep nothing")
+	}
+}
+
+// upstreamSummaryProvider answers summarization requests itself and sends
+// everything else to a real upstream client, so persona merging applies
+type upstreamSummaryProvider struct {
+	*client.OpenWebUIClient
+}
+
+func (p *upstreamSummaryProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	if len(req.Messages) > 0 && req.Messages[0].Content == summaryInstructions {
+		return &models.ChatCompletionResponse{Choices: []models.Choice{{Message: models.ChatMessage{Role: "assistant", Content: "S1"}}}}, nil
+	}
+	return p.OpenWebUIClient.ChatCompletion(ctx, req)
+}
+
+func TestClient_SummarizeWithReplacingPersona(t *testing.T) {
+	var upstream models.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&upstream)
+		json.NewEncoder(w).Encode(models.ChatCompletionResponse{ID: "test-id"})
+	}))
+	defer server.Close()
+
+	provider := &upstreamSummaryProvider{client.NewOpenWebUIClient(server.URL, "", time.Second)}
+	cfg := testConfig(StrategySummarize)
+	cfg.SummaryTokens = 4
+	cfg.SummaryCacheSize = 10
+	c := NewClient(provider, NewFromConfig(cfg))
+
+	req := summarizeRequest("conv-1")
+	req.PersonaPrompt = "P"
+	req.PersonaMerge = models.MergeReplace
+	if _, err := c.ChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+
+	forwarded := upstream.Messages
+	if len(forwarded) < 2 || forwarded[0].Content != "P" || forwarded[1].Content != summaryPrefix+"S1" {
+		t.Fatalf("expected the persona followed by the summary, got %+v", forwarded)
+	}
+	if got := contents(forwarded[2:]); got != "u3" {
+		t.Errorf("expected u3 after the summary, got %s", got)
+	}
+}
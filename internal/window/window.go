@@ -0,0 +1,194 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/tokens"
+)
+
+// ErrContextOverflow is returned when a request cannot be made to fit the
+// model's context window
+var ErrContextOverflow = errors.New("context window exceeded")
+
+// Strategies for requests that exceed the context window
+const (
+	StrategyDropOldest    = "drop-oldest"
+	StrategySlidingWindow = "sliding-window"
+	StrategySummarize     = "summarize"
+	StrategyFail          = "fail"
+)
+
+// Window fits requests into per-model context limits before they are
+// forwarded. System messages and the persona prompt are always kept, as is
+// the newest message.
+type Window struct {
+	strategy       string
+	defaultTokens  int
+	reserveTokens  int
+	windowMessages int
+	models         map[string]int
+	patterns       []string // glob patterns from models, most specific first
+	estimator      tokens.Estimator
+
+	summaryModel     string
+	summaryTokens    int
+	summaryCacheSize int
+}
+
+// NewFromConfig creates a window from the context window config
+func NewFromConfig(cfg config.ContextConfig) *Window {
+	w := &Window{
+		strategy:       cfg.Strategy,
+		defaultTokens:  cfg.DefaultTokens,
+		reserveTokens:  cfg.ReserveTokens,
+		windowMessages: cfg.WindowMessages,
+		models:         cfg.Models,
+		estimator:      tokens.Estimator{CharsPerToken: cfg.CharsPerToken},
+
+		summaryModel:     cfg.SummaryModel,
+		summaryTokens:    cfg.SummaryTokens,
+		summaryCacheSize: cfg.SummaryCacheSize,
+	}
+
+	for pattern := range cfg.Models {
+		if strings.ContainsAny(pattern, "*?[") {
+			w.patterns = append(w.patterns, pattern)
+		}
+	}
+	sort.Slice(w.patterns, func(i, j int) bool {
+		if len(w.patterns[i]) != len(w.patterns[j]) {
+			return len(w.patterns[i]) > len(w.patterns[j])
+		}
+		return w.patterns[i] < w.patterns[j]
+	})
+	return w
+}
+
+// Limit returns the context window of model in tokens, or 0 when it is
+// unlimited. Exact names win over patterns, and longer patterns over
+// shorter ones.
+func (w *Window) Limit(model string) int {
+	if tokens, ok := w.models[model]; ok {
+		return tokens
+	}
+	for _, pattern := range w.patterns {
+		if ok, _ := path.Match(pattern, model); ok {
+			return w.models[pattern]
+		}
+	}
+	return w.defaultTokens
+}
+
+// Fit returns req, or a copy of it with older messages removed, so that its
+// prompt and the room reserved for the reply fit the model's context
+// window. It also returns how many messages were dropped. The summarize
+// strategy drops like drop-oldest here; Client replaces the dropped
+// messages with a summary.
+func (w *Window) Fit(req *models.ChatCompletionRequest) (*models.ChatCompletionRequest, int, error) {
+	keep, err := w.plan(req, 0)
+	if err != nil || keep == nil {
+		return req, 0, err
+	}
+	return w.apply(req, keep, nil)
+}
+
+// plan decides which messages of req to keep, leaving extra tokens free in
+// addition to the reply reserve. It returns nil when the request fits as it
+// is.
+func (w *Window) plan(req *models.ChatCompletionRequest, extra int) ([]bool, error) {
+	limit := w.Limit(req.Model)
+	if limit <= 0 || len(req.Messages) == 0 {
+		return nil, nil
+	}
+
+	reserve := w.reserveTokens
+	if req.MaxTokens != nil {
+		reserve = *req.MaxTokens
+	}
+	budget := limit - reserve
+
+	needed := w.estimator.Request(req)
+	conversation := 0
+	for _, m := range req.Messages {
+		if m.Role != "system" {
+			conversation++
+		}
+	}
+
+	windowed := w.strategy == StrategySlidingWindow && conversation > w.windowMessages
+	if needed <= budget && !windowed {
+		return nil, nil
+	}
+	if w.strategy == StrategyFail {
+		return nil, w.overflow(req.Model, needed, limit, reserve)
+	}
+
+	keep := make([]bool, len(req.Messages))
+	for i := range keep {
+		keep[i] = true
+	}
+
+	// Indexes of the messages that may be dropped, oldest first. The newest
+	// message is the turn being answered and always stays.
+	var droppable []int
+	for i, m := range req.Messages[:len(req.Messages)-1] {
+		if m.Role != "system" {
+			droppable = append(droppable, i)
+		}
+	}
+
+	remaining := needed
+	drop := func() {
+		i := droppable[0]
+		droppable = droppable[1:]
+		keep[i] = false
+		remaining -= w.estimator.Message(req.Messages[i])
+	}
+
+	if windowed {
+		for conversation > w.windowMessages && len(droppable) > 0 {
+			drop()
+			conversation--
+		}
+	}
+	for remaining > budget-extra && len(droppable) > 0 {
+		drop()
+	}
+	// Do not start the remaining history halfway through a turn
+	for len(droppable) > 0 && req.Messages[droppable[0]].Role != "user" {
+		drop()
+	}
+
+	if remaining > budget-extra {
+		return nil, w.overflow(req.Model, remaining+extra, limit, reserve)
+	}
+	return keep, nil
+}
+
+// apply returns a copy of req holding only the kept messages. A non-nil
+// summary takes the place of the first dropped message.
+func (w *Window) apply(req *models.ChatCompletionRequest, keep []bool, summary *models.ChatMessage) (*models.ChatCompletionRequest, int, error) {
+	fitted := *req
+	fitted.Messages = nil
+	for i, m := range req.Messages {
+		if keep[i] {
+			fitted.Messages = append(fitted.Messages, m)
+		} else if summary != nil {
+			fitted.Messages = append(fitted.Messages, *summary)
+			summary = nil
+		}
+	}
+	return &fitted, len(req.Messages) - len(fitted.Messages), nil
+}
+
+// overflow describes by how much a request exceeds the context window
+func (w *Window) overflow(model string, needed, limit, reserve int) error {
+	return fmt.Errorf("%w: prompt needs about %d tokens, model %s allows %d with %d reserved for the reply (%d over)",
+		ErrContextOverflow, needed, model, limit, reserve, needed-(limit-reserve))
+}
@@ -0,0 +1,156 @@
+package window
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/config"
+	"github.com/fr0g-vibe/fr0g-ai-bridge/internal/models"
+)
+
+// msg builds a message whose content is estimated at tokens tokens, so each
+// message costs that plus the per-message overhead with one char per token
+func msg(role string, tokens int, tag string) models.ChatMessage {
+	return models.ChatMessage{Role: role, Content: tag + strings.Repeat(".", tokens-len(tag))}
+}
+
+func testConfig(strategy string) config.ContextConfig {
+	return config.ContextConfig{
+		Strategy:       strategy,
+		DefaultTokens:  100,
+		ReserveTokens:  20,
+		WindowMessages: 2,
+		CharsPerToken:  1,
+		Models:         map[string]int{"big": 1000, "llama*": 50, "llama3*": 60, "free": 0},
+	}
+}
+
+func TestWindow_Limit(t *testing.T) {
+	w := NewFromConfig(testConfig(StrategyDropOldest))
+
+	tests := []struct {
+		model    string
+		expected int
+	}{
+		{model: "big", expected: 1000},
+		{model: "llama3.1", expected: 60}, // longest matching pattern wins
+		{model: "llama2", expected: 50},
+		{model: "free", expected: 0},
+		{model: "other", expected: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := w.Limit(tt.model); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func contents(messages []models.ChatMessage) string {
+	var tags []string
+	for _, m := range messages {
+		tags = append(tags, strings.TrimRight(m.Content, "."))
+	}
+	return strings.Join(tags, ",")
+}
+
+func TestWindow_Fit(t *testing.T) {
+	// Each message below costs 20 tokens; the budget is 100-20 = 80
+	history := []models.ChatMessage{
+		msg("system", 16, "s"),
+		msg("user", 16, "u1"),
+		msg("assistant", 16, "a1"),
+		msg("user", 16, "u2"),
+		msg("assistant", 16, "a2"),
+		msg("user", 16, "u3"),
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		model    string
+		messages []models.ChatMessage
+		expected string
+		dropped  int
+		err      error
+	}{
+		{name: "fits", strategy: StrategyDropOldest, messages: history[:4], expected: "s,u1,a1,u2"},
+		{name: "unlimited model", strategy: StrategyFail, model: "free", messages: history, expected: "s,u1,a1,u2,a2,u3"},
+		{name: "drop oldest keeps system and whole turns", strategy: StrategyDropOldest, messages: history, expected: "s,u2,a2,u3", dropped: 2},
+		{name: "sliding window", strategy: StrategySlidingWindow, model: "big", messages: history, expected: "s,u3", dropped: 4},
+		{name: "fail", strategy: StrategyFail, messages: history, err: ErrContextOverflow},
+		{name: "newest message too big", strategy: StrategyDropOldest, messages: []models.ChatMessage{msg("user", 90, "u")}, err: ErrContextOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewFromConfig(testConfig(tt.strategy))
+			req := &models.ChatCompletionRequest{Model: tt.model, Messages: tt.messages}
+			if req.Model == "" {
+				req.Model = "other"
+			}
+
+			fitted, dropped, err := w.Fit(req)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fit failed: %v", err)
+			}
+			if got := contents(fitted.Messages); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+			if dropped != tt.dropped {
+				t.Errorf("expected %d dropped, got %d", tt.dropped, dropped)
+			}
+		})
+	}
+
+	if len(history) != 6 || !strings.HasPrefix(history[1].Content, "u1") {
+		t.Errorf("expected the caller's messages to be left untouched")
+	}
+}
+
+func TestWindow_FitReserve(t *testing.T) {
+	w := NewFromConfig(testConfig(StrategyFail))
+	req := &models.ChatCompletionRequest{Model: "other", Messages: []models.ChatMessage{msg("user", 46, "u")}}
+
+	// 50 tokens fit the default 80 token budget
+	if _, _, err := w.Fit(req); err != nil {
+		t.Fatalf("expected request to fit, got %v", err)
+	}
+
+	// Asking for a 60 token reply leaves only 40
+	maxTokens := 60
+	req.MaxTokens = &maxTokens
+	_, _, err := w.Fit(req)
+	if !errors.Is(err, ErrContextOverflow) {
+		t.Fatalf("expected ErrContextOverflow, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "(10 over)") {
+		t.Errorf("expected the overflow to be named, got %v", err)
+	}
+}
+
+func TestWindow_FitCountsPersonaPrompt(t *testing.T) {
+	w := NewFromConfig(testConfig(StrategyDropOldest))
+	req := &models.ChatCompletionRequest{
+		Model:         "other",
+		PersonaPrompt: strings.Repeat(".", 36),
+		Messages:      []models.ChatMessage{msg("user", 16, "u1"), msg("assistant", 16, "a1"), msg("user", 16, "u2")},
+	}
+
+	fitted, dropped, err := w.Fit(req)
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if dropped != 2 || contents(fitted.Messages) != "u2" {
+		t.Errorf("expected the persona prompt to take room, got %s (%d dropped)", contents(fitted.Messages), dropped)
+	}
+}